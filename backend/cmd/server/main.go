@@ -1,10 +1,20 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"go-lv-vue-admin/internal/core"
 	"go-lv-vue-admin/internal/global"
+	"go-lv-vue-admin/internal/logsink"
 	"go-lv-vue-admin/internal/router"
+	"go-lv-vue-admin/internal/service"
+	"go-lv-vue-admin/internal/storage"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
@@ -25,6 +35,8 @@ func main() {
 		core.RegisterTables()
 		// Initialize Casbin
 		global.LV_ENFORCER = core.InitCasbin()
+		// Sync user-role groupings
+		core.InitUserRoles()
 		db, _ := global.LV_DB.DB()
 		defer db.Close()
 	} else {
@@ -32,15 +44,73 @@ func main() {
 		// For now, don't exit to allow running without DB
 	}
 
+	// Initialize storage driver from storage.driver. Before log archiving this was never called and
+	// uploads always went to local storage; a driver that is not fully configured still falls back to local.
+	if err := storage.InitStorage(); err != nil {
+		global.LV_LOG.Error("init storage failed, falling back to local storage", zap.Error(err))
+	}
+
+	// Initialize operation log sink (batched async writer)
+	logsink.InitSink()
+
+	// Archive expired operation logs and evaluate security alert rules in the background
+	backgroundCtx, stopBackground := context.WithCancel(context.Background())
+	defer stopBackground()
+	alertDone := make(chan struct{})
+	if global.LV_DB != nil {
+		go service.RunLogArchiveScheduler(backgroundCtx)
+		go func() {
+			defer close(alertDone)
+			service.RunAlertEngine(backgroundCtx)
+		}()
+	} else {
+		close(alertDone)
+	}
+
 	// 4. Initialize Router
 	gin.SetMode(global.LV_CONFIG.Server.Mode)
 	r := gin.Default()
 	router.InitRouter(r)
 
+	// 5. Initialize API resources from registered routes
+	if global.LV_DB != nil {
+		core.InitApis(r.Routes())
+	}
+
 	addr := fmt.Sprintf(":%d", global.LV_CONFIG.Server.Port)
 	global.LV_LOG.Info("Server exiting", zap.String("addr", addr))
 
-	if err := r.Run(addr); err != nil {
-		global.LV_LOG.Error(err.Error())
+	srv := &http.Server{Addr: addr, Handler: r}
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- srv.ListenAndServe()
+	}()
+
+	// 收到退出信号后优雅退出：先停止接收请求，再写完队列中的操作日志
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+	select {
+	case err := <-errCh:
+		if !errors.Is(err, http.ErrServerClosed) {
+			global.LV_LOG.Error(err.Error())
+		}
+	case <-quit:
+	}
+
+	stopBackground()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := srv.Shutdown(ctx); err != nil {
+		global.LV_LOG.Error("server shutdown failed", zap.Error(err))
+	}
+	if err := logsink.Close(ctx); err != nil {
+		global.LV_LOG.Error("flush operation logs failed", zap.Error(err))
+	}
+	// 等待告警引擎发送完队列中的通知
+	select {
+	case <-alertDone:
+	case <-ctx.Done():
+		global.LV_LOG.Error("send pending alert notifications timed out")
 	}
+	global.LV_LOG.Info("Server exited")
 }
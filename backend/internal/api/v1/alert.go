@@ -0,0 +1,147 @@
+package v1
+
+import (
+	"go-lv-vue-admin/internal/global"
+	"go-lv-vue-admin/internal/model"
+	"go-lv-vue-admin/internal/service"
+	"go-lv-vue-admin/pkg/utils"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+type AlertApi struct{}
+
+var alertService = service.AlertService{}
+
+// GetAlertList
+// @Summary 获取安全告警列表（handled=0 未处理，1 已处理）
+// @Router /platform/alert/list [get]
+func (a *AlertApi) GetAlertList(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("pageSize", "10"))
+
+	alerts, total, err := alertService.GetAlertList(c.Request.Context(), page, pageSize, c.Query("level"), c.Query("handled"), c.Query("keyword"))
+	if err != nil {
+		global.LV_LOG.Error("获取告警列表失败", zap.Error(err))
+		c.JSON(500, gin.H{"code": 7, "msg": "获取告警列表失败"})
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"code": 0,
+		"data": gin.H{
+			"list":     alerts,
+			"total":    total,
+			"page":     page,
+			"pageSize": pageSize,
+		},
+		"msg": "success",
+	})
+}
+
+// GetUnhandledCount
+// @Summary 获取未处理的告警数
+// @Router /platform/alert/unhandled [get]
+func (a *AlertApi) GetUnhandledCount(c *gin.Context) {
+	count, err := alertService.UnhandledCount(c.Request.Context())
+	if err != nil {
+		global.LV_LOG.Error("获取未处理告警数失败", zap.Error(err))
+		c.JSON(500, gin.H{"code": 7, "msg": "获取未处理告警数失败"})
+		return
+	}
+
+	c.JSON(200, gin.H{"code": 0, "data": gin.H{"count": count}, "msg": "success"})
+}
+
+// HandleAlerts
+// @Summary 将告警标记为已处理
+// @Router /platform/alert/handle [put]
+func (a *AlertApi) HandleAlerts(c *gin.Context) {
+	var req struct {
+		Ids    []uint `json:"ids"`
+		Remark string `json:"remark"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, gin.H{"code": 7, "msg": err.Error()})
+		return
+	}
+
+	if err := alertService.HandleAlerts(c.Request.Context(), req.Ids, utils.GetClaims(c).Username, req.Remark); err != nil {
+		global.LV_LOG.Error("处理告警失败", zap.Error(err))
+		c.JSON(500, gin.H{"code": 7, "msg": err.Error()})
+		return
+	}
+
+	c.JSON(200, gin.H{"code": 0, "msg": "处理成功"})
+}
+
+// GetRuleList
+// @Summary 获取告警规则和可选的通知渠道
+// @Router /platform/alert/rules [get]
+func (a *AlertApi) GetRuleList(c *gin.Context) {
+	rules, err := alertService.GetRuleList(c.Request.Context())
+	if err != nil {
+		global.LV_LOG.Error("获取告警规则失败", zap.Error(err))
+		c.JSON(500, gin.H{"code": 7, "msg": "获取告警规则失败"})
+		return
+	}
+
+	c.JSON(200, gin.H{"code": 0, "data": gin.H{"list": rules, "channels": service.AlertChannels()}, "msg": "success"})
+}
+
+// CreateRule
+// @Summary 创建告警规则
+// @Router /platform/alert/rules [post]
+func (a *AlertApi) CreateRule(c *gin.Context) {
+	var rule model.LvAlertRule
+	if err := c.ShouldBindJSON(&rule); err != nil {
+		c.JSON(400, gin.H{"code": 7, "msg": err.Error()})
+		return
+	}
+
+	if err := alertService.CreateRule(c.Request.Context(), &rule); err != nil {
+		global.LV_LOG.Error("创建告警规则失败", zap.Error(err))
+		c.JSON(500, gin.H{"code": 7, "msg": err.Error()})
+		return
+	}
+
+	c.JSON(200, gin.H{"code": 0, "msg": "创建成功"})
+}
+
+// UpdateRule
+// @Summary 更新告警规则
+// @Router /platform/alert/rules/:id [put]
+func (a *AlertApi) UpdateRule(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+	var rule model.LvAlertRule
+	if err := c.ShouldBindJSON(&rule); err != nil {
+		c.JSON(400, gin.H{"code": 7, "msg": err.Error()})
+		return
+	}
+	rule.ID = uint(id)
+
+	if err := alertService.UpdateRule(c.Request.Context(), &rule); err != nil {
+		global.LV_LOG.Error("更新告警规则失败", zap.Error(err))
+		c.JSON(500, gin.H{"code": 7, "msg": err.Error()})
+		return
+	}
+
+	c.JSON(200, gin.H{"code": 0, "msg": "更新成功"})
+}
+
+// DeleteRule
+// @Summary 删除告警规则
+// @Router /platform/alert/rules/:id [delete]
+func (a *AlertApi) DeleteRule(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+
+	if err := alertService.DeleteRule(c.Request.Context(), uint(id)); err != nil {
+		global.LV_LOG.Error("删除告警规则失败", zap.Error(err))
+		c.JSON(500, gin.H{"code": 7, "msg": "删除告警规则失败"})
+		return
+	}
+
+	c.JSON(200, gin.H{"code": 0, "msg": "删除成功"})
+}
@@ -0,0 +1,46 @@
+package v1
+
+import (
+	"go-lv-vue-admin/internal/global"
+	"go-lv-vue-admin/internal/service"
+	"go-lv-vue-admin/pkg/utils"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+type AuditApi struct{}
+
+var auditService = service.AuditService{}
+
+// GetAuditList
+// @Summary 获取审计日志列表（entity、id 查询某条数据的变更历史）
+// @Router /system/audit [get]
+func (a *AuditApi) GetAuditList(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("pageSize", "10"))
+	entity := c.Query("entity")
+	entityId := c.Query("id")
+	username := c.Query("username")
+	action := c.Query("action")
+
+	claims := utils.GetClaims(c)
+	logs, total, err := auditService.GetAuditList(c.Request.Context(), claims.UserId, claims.ActiveRoleId, page, pageSize, entity, entityId, username, action)
+	if err != nil {
+		global.LV_LOG.Error("获取审计日志列表失败", zap.Error(err))
+		c.JSON(500, gin.H{"code": 7, "msg": "获取审计日志列表失败"})
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"code": 0,
+		"data": gin.H{
+			"list":     logs,
+			"total":    total,
+			"page":     page,
+			"pageSize": pageSize,
+		},
+		"msg": "success",
+	})
+}
@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 )
 
 type DashboardApi struct{}
@@ -49,19 +50,20 @@ type LogItem struct {
 // @Router /dashboard/stats [get]
 func (d *DashboardApi) GetStats(c *gin.Context) {
 	var stats DashboardStats
+	db := global.LV_DB.WithContext(c.Request.Context())
 
 	// Count users
-	global.LV_DB.Model(&model.LvUser{}).Count(&stats.UserCount)
+	db.Model(&model.LvUser{}).Count(&stats.UserCount)
 
 	// Count roles
-	global.LV_DB.Model(&model.LvRole{}).Count(&stats.RoleCount)
+	db.Model(&model.LvRole{}).Count(&stats.RoleCount)
 
 	// Count menus
-	global.LV_DB.Model(&model.LvMenu{}).Count(&stats.MenuCount)
+	db.Model(&model.LvMenu{}).Count(&stats.MenuCount)
 
 	// Today visit from operation logs
 	today := time.Now().Format("2006-01-02")
-	global.LV_DB.Model(&model.LvOperationLog{}).
+	db.Model(&model.LvOperationLog{}).
 		Where("DATE(created_at) = ?", today).
 		Count(&stats.TodayVisit)
 
@@ -80,18 +82,19 @@ func (d *DashboardApi) GetStats(c *gin.Context) {
 // @Router /dashboard/charts [get]
 func (d *DashboardApi) GetCharts(c *gin.Context) {
 	var charts DashboardCharts
+	db := global.LV_DB.WithContext(c.Request.Context())
 
 	// 1. 最近7天访问趋势
-	charts.VisitTrend = getVisitTrend()
+	charts.VisitTrend = getVisitTrend(db)
 
 	// 2. 用户增长趋势（最近7天）
-	charts.UserGrowth = getUserGrowth()
+	charts.UserGrowth = getUserGrowth(db)
 
 	// 3. 模块访问占比
-	charts.ModuleStats = getModuleStats()
+	charts.ModuleStats = getModuleStats(db)
 
 	// 4. 最近操作日志
-	charts.LatestLogs = getLatestLogs()
+	charts.LatestLogs = getLatestLogs(db)
 
 	c.JSON(200, gin.H{
 		"code": 0,
@@ -101,7 +104,7 @@ func (d *DashboardApi) GetCharts(c *gin.Context) {
 }
 
 // 获取最近7天访问趋势
-func getVisitTrend() ChartData {
+func getVisitTrend(db *gorm.DB) ChartData {
 	var result ChartData
 	now := time.Now()
 
@@ -111,7 +114,7 @@ func getVisitTrend() ChartData {
 		result.Categories = append(result.Categories, date.Format("01/02"))
 
 		var count int64
-		global.LV_DB.Model(&model.LvOperationLog{}).
+		db.Model(&model.LvOperationLog{}).
 			Where("DATE(created_at) = ?", dateStr).
 			Count(&count)
 		result.Series = append(result.Series, count)
@@ -121,7 +124,7 @@ func getVisitTrend() ChartData {
 }
 
 // 获取用户增长趋势
-func getUserGrowth() ChartData {
+func getUserGrowth(db *gorm.DB) ChartData {
 	var result ChartData
 	now := time.Now()
 
@@ -131,7 +134,7 @@ func getUserGrowth() ChartData {
 		result.Categories = append(result.Categories, date.Format("01/02"))
 
 		var count int64
-		global.LV_DB.Model(&model.LvUser{}).
+		db.Model(&model.LvUser{}).
 			Where("DATE(created_at) <= ?", dateStr).
 			Count(&count)
 		result.Series = append(result.Series, count)
@@ -141,13 +144,13 @@ func getUserGrowth() ChartData {
 }
 
 // 获取模块访问统计
-func getModuleStats() []PieItem {
+func getModuleStats(db *gorm.DB) []PieItem {
 	var results []struct {
 		Module string
 		Count  int64
 	}
 
-	global.LV_DB.Model(&model.LvOperationLog{}).
+	db.Model(&model.LvOperationLog{}).
 		Select("module, COUNT(*) as count").
 		Group("module").
 		Order("count DESC").
@@ -175,9 +178,9 @@ func getModuleStats() []PieItem {
 }
 
 // 获取最近操作日志
-func getLatestLogs() []LogItem {
+func getLatestLogs(db *gorm.DB) []LogItem {
 	var logs []model.LvOperationLog
-	global.LV_DB.Order("created_at DESC").Limit(10).Find(&logs)
+	db.Order("created_at DESC").Limit(10).Find(&logs)
 
 	var items []LogItem
 	for _, log := range logs {
@@ -58,7 +58,7 @@ func (a *DemoApi) CreateDemo(c *gin.Context) {
 		return
 	}
 
-	if err := demoService.CreateDemo(&demo); err != nil {
+	if err := demoService.CreateDemo(c.Request.Context(), &demo); err != nil {
 		global.LV_LOG.Error("创建失败", zap.Error(err))
 		c.JSON(500, gin.H{"code": 7, "msg": "创建失败"})
 		return
@@ -77,7 +77,7 @@ func (a *DemoApi) UpdateDemo(c *gin.Context) {
 	}
 	demo.ID = uint(id)
 
-	if err := demoService.UpdateDemo(&demo); err != nil {
+	if err := demoService.UpdateDemo(c.Request.Context(), &demo); err != nil {
 		global.LV_LOG.Error("更新失败", zap.Error(err))
 		c.JSON(500, gin.H{"code": 7, "msg": "更新失败"})
 		return
@@ -89,7 +89,7 @@ func (a *DemoApi) UpdateDemo(c *gin.Context) {
 // DeleteDemo 删除
 func (a *DemoApi) DeleteDemo(c *gin.Context) {
 	id, _ := strconv.Atoi(c.Param("id"))
-	if err := demoService.DeleteDemo(uint(id)); err != nil {
+	if err := demoService.DeleteDemo(c.Request.Context(), uint(id)); err != nil {
 		global.LV_LOG.Error("删除失败", zap.Error(err))
 		c.JSON(500, gin.H{"code": 7, "msg": "删除失败"})
 		return
@@ -0,0 +1,109 @@
+package v1
+
+import (
+	"fmt"
+	"go-lv-vue-admin/internal/global"
+	"go-lv-vue-admin/internal/service"
+	"go-lv-vue-admin/pkg/utils"
+	"io"
+	"net/url"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+type ExportApi struct{}
+
+var exportService = service.ExportService{}
+
+// GetJobList
+// @Summary 获取当前用户的后台导出任务
+// @Router /export/jobs [get]
+func (e *ExportApi) GetJobList(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("pageSize", "10"))
+
+	jobs, total, err := exportService.GetJobList(c.Request.Context(), utils.GetClaims(c).UserId, page, pageSize)
+	if err != nil {
+		global.LV_LOG.Error("获取导出任务失败", zap.Error(err))
+		c.JSON(500, gin.H{"code": 7, "msg": "获取导出任务失败"})
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"code": 0,
+		"data": gin.H{
+			"list":     jobs,
+			"total":    total,
+			"page":     page,
+			"pageSize": pageSize,
+		},
+		"msg": "success",
+	})
+}
+
+// DownloadJob
+// @Summary 下载后台导出任务生成的文件
+// @Router /export/jobs/:id/download [get]
+func (e *ExportApi) DownloadJob(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+
+	job, reader, err := exportService.OpenJobFile(c.Request.Context(), utils.GetClaims(c).UserId, uint(id))
+	if err != nil {
+		c.JSON(400, gin.H{"code": 7, "msg": err.Error()})
+		return
+	}
+	defer reader.Close()
+
+	setAttachmentHeaders(c, job.Filename, job.Format)
+	if _, err := io.Copy(c.Writer, reader); err != nil {
+		global.LV_LOG.Error("下载导出文件失败", zap.Uint("jobId", job.ID), zap.Error(err))
+	}
+}
+
+// writeExport 导出接口的公共处理：format 为 csv（默认）或 xlsx
+// 条数不超过阈值时直接以附件流式返回，否则创建后台导出任务，返回任务信息
+func writeExport(c *gin.Context, export service.Export) {
+	format := c.DefaultQuery("format", utils.SheetCSV)
+	if !utils.IsSheetFormat(format) {
+		c.JSON(400, gin.H{"code": 7, "msg": "不支持的文件格式"})
+		return
+	}
+	ctx := c.Request.Context()
+
+	total, err := export.Count(ctx)
+	if err != nil {
+		global.LV_LOG.Error("统计导出数据失败", zap.Error(err))
+		c.JSON(500, gin.H{"code": 7, "msg": "导出失败"})
+		return
+	}
+	if total > exportService.AsyncThreshold() {
+		job, err := exportService.StartJob(ctx, utils.GetClaims(c).UserId, export, format)
+		if err != nil {
+			global.LV_LOG.Error("创建导出任务失败", zap.Error(err))
+			c.JSON(500, gin.H{"code": 7, "msg": "导出失败"})
+			return
+		}
+		c.JSON(200, gin.H{"code": 0, "data": gin.H{"async": true, "job": job}, "msg": fmt.Sprintf("共 %d 条数据，已转为后台导出", total)})
+		return
+	}
+	writeSheet(c, export, format)
+}
+
+// writeSheet 以附件形式流式返回表格
+func writeSheet(c *gin.Context, export service.Export, format string) {
+	setAttachmentHeaders(c, export.Filename(format), format)
+	// 响应头已发出，出错时只能记录日志
+	if _, err := exportService.Write(c.Request.Context(), export, format, c.Writer); err != nil {
+		global.LV_LOG.Error("导出失败", zap.String("type", export.Type), zap.Error(err))
+	}
+}
+
+// setAttachmentHeaders 设置文件下载的响应头，文件名按 RFC 5987 编码以支持中文
+func setAttachmentHeaders(c *gin.Context, filename, format string) {
+	c.Header("Content-Type", utils.SheetContentType(format))
+	c.Header("Content-Disposition", "attachment; filename*=UTF-8''"+url.PathEscape(filename))
+	c.Header("Access-Control-Expose-Headers", "Content-Disposition")
+	c.Status(200)
+}
@@ -49,14 +49,15 @@ func (a *GeneratorApi) GenerateCode(c *gin.Context) {
 		return
 	}
 
-	// 自动检测表是否有 deleted_at 字段
+	// 自动检测表是否有 deleted_at 字段，开启多租户模式时模型按租户隔离
 	req.HasDeletedAt = generatorService.HasDeletedAtColumn(req.TableName)
+	req.HasTenantId = global.LV_CONFIG.Tenant.Enable || generatorService.HasTenantIdColumn(req.TableName)
 
 	// 获取项目根路径（假设在 backend 目录运行）
 	backendPath, _ := filepath.Abs(".")
 	frontendPath, _ := filepath.Abs("../frontend")
 
-	result, err := generatorService.WriteGeneratedFiles(req, backendPath, frontendPath)
+	result, err := generatorService.WriteGeneratedFiles(c.Request.Context(), req, backendPath, frontendPath)
 	if err != nil {
 		global.LV_LOG.Error("生成代码失败", zap.Error(err))
 		c.JSON(500, gin.H{"code": 7, "msg": "生成失败: " + err.Error()})
@@ -74,8 +75,9 @@ func (a *GeneratorApi) PreviewCode(c *gin.Context) {
 		return
 	}
 
-	// 自动检测表是否有 deleted_at 字段
+	// 自动检测表是否有 deleted_at 字段，开启多租户模式时模型按租户隔离
 	config.HasDeletedAt = generatorService.HasDeletedAtColumn(config.TableName)
+	config.HasTenantId = global.LV_CONFIG.Tenant.Enable || generatorService.HasTenantIdColumn(config.TableName)
 
 	codes, err := generatorService.GenerateCode(config)
 	if err != nil {
@@ -1,9 +1,14 @@
 package v1
 
 import (
+	"errors"
 	"go-lv-vue-admin/internal/global"
+	"go-lv-vue-admin/internal/logsink"
 	"go-lv-vue-admin/internal/service"
+	"go-lv-vue-admin/pkg/utils"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
@@ -11,7 +16,10 @@ import (
 
 type OperationLogApi struct{}
 
-var operationLogService = service.OperationLogService{}
+var (
+	operationLogService = service.OperationLogService{}
+	logArchiveService   = service.LogArchiveService{}
+)
 
 // GetOperationLogList
 // @Summary 获取操作日志列表
@@ -19,11 +27,14 @@ var operationLogService = service.OperationLogService{}
 func (o *OperationLogApi) GetOperationLogList(c *gin.Context) {
 	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
 	pageSize, _ := strconv.Atoi(c.DefaultQuery("pageSize", "10"))
-	username := c.Query("username")
-	module := c.Query("module")
-	action := c.Query("action")
+	filter, err := operationLogFilter(c)
+	if err != nil {
+		c.JSON(400, gin.H{"code": 7, "msg": err.Error()})
+		return
+	}
 
-	logs, total, err := operationLogService.GetOperationLogList(page, pageSize, username, module, action)
+	claims := utils.GetClaims(c)
+	logs, total, err := operationLogService.GetOperationLogList(c.Request.Context(), claims.UserId, claims.ActiveRoleId, page, pageSize, filter)
 	if err != nil {
 		global.LV_LOG.Error("获取操作日志列表失败", zap.Error(err))
 		c.JSON(500, gin.H{"code": 7, "msg": "获取操作日志列表失败"})
@@ -42,6 +53,79 @@ func (o *OperationLogApi) GetOperationLogList(c *gin.Context) {
 	})
 }
 
+// ExportOperationLogs
+// @Summary 按列表的筛选条件导出操作日志（format=csv/xlsx）
+// @Router /system/log/export [get]
+func (o *OperationLogApi) ExportOperationLogs(c *gin.Context) {
+	filter, err := operationLogFilter(c)
+	if err != nil {
+		c.JSON(400, gin.H{"code": 7, "msg": err.Error()})
+		return
+	}
+
+	claims := utils.GetClaims(c)
+	export := operationLogService.ExportOperationLogs(claims.UserId, claims.ActiveRoleId, filter)
+	writeExport(c, export)
+}
+
+// operationLogFilter 解析列表和导出共用的筛选条件
+// status 可以是状态码（404）或状态类别（4xx）；startTime / endTime 为 "2006-01-02 15:04:05" 格式或毫秒时间戳
+func operationLogFilter(c *gin.Context) (service.OperationLogFilter, error) {
+	filter := service.OperationLogFilter{
+		Username:  c.Query("username"),
+		Module:    c.Query("module"),
+		Action:    c.Query("action"),
+		Ip:        strings.TrimSpace(c.Query("ip")),
+		Path:      strings.TrimSpace(c.Query("path")),
+		Keyword:   c.Query("keyword"),
+		SortBy:    c.Query("sortBy"),
+		SortOrder: c.Query("sortOrder"),
+	}
+
+	if status := strings.ToLower(strings.TrimSpace(c.Query("status"))); status != "" {
+		if len(status) == 3 && strings.HasSuffix(status, "xx") && status[0] >= '1' && status[0] <= '5' {
+			filter.StatusFrom = int(status[0]-'0') * 100
+			filter.StatusTo = filter.StatusFrom + 99
+		} else if code, err := strconv.Atoi(status); err == nil && code >= 100 && code <= 599 {
+			filter.StatusFrom, filter.StatusTo = code, code
+		} else {
+			return filter, errors.New("状态码格式不正确")
+		}
+	}
+	if minLatency := c.Query("minLatency"); minLatency != "" {
+		value, err := strconv.ParseInt(minLatency, 10, 64)
+		if err != nil || value < 0 {
+			return filter, errors.New("耗时格式不正确")
+		}
+		filter.MinLatency = value
+	}
+
+	var err error
+	if filter.StartTime, err = parseQueryTime(c.Query("startTime")); err != nil {
+		return filter, errors.New("开始时间格式不正确")
+	}
+	if filter.EndTime, err = parseQueryTime(c.Query("endTime")); err != nil {
+		return filter, errors.New("结束时间格式不正确")
+	}
+	return filter, nil
+}
+
+// parseQueryTime 解析查询参数中的时间，为空时返回 nil
+func parseQueryTime(value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	if ms, err := strconv.ParseInt(value, 10, 64); err == nil {
+		t := time.UnixMilli(ms)
+		return &t, nil
+	}
+	t, err := time.ParseInLocation(time.DateTime, value, time.Local)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
 // DeleteOperationLogs
 // @Summary 批量删除操作日志
 // @Router /system/log [delete]
@@ -54,7 +138,8 @@ func (o *OperationLogApi) DeleteOperationLogs(c *gin.Context) {
 		return
 	}
 
-	if err := operationLogService.DeleteOperationLogs(req.Ids); err != nil {
+	claims := utils.GetClaims(c)
+	if err := operationLogService.DeleteOperationLogs(c.Request.Context(), claims.UserId, claims.ActiveRoleId, req.Ids); err != nil {
 		global.LV_LOG.Error("删除操作日志失败", zap.Error(err))
 		c.JSON(500, gin.H{"code": 7, "msg": "删除失败"})
 		return
@@ -67,7 +152,8 @@ func (o *OperationLogApi) DeleteOperationLogs(c *gin.Context) {
 // @Summary 清空操作日志
 // @Router /system/log/clear [delete]
 func (o *OperationLogApi) ClearOperationLogs(c *gin.Context) {
-	if err := operationLogService.ClearOperationLogs(); err != nil {
+	claims := utils.GetClaims(c)
+	if err := operationLogService.ClearOperationLogs(c.Request.Context(), claims.UserId, claims.ActiveRoleId); err != nil {
 		global.LV_LOG.Error("清空操作日志失败", zap.Error(err))
 		c.JSON(500, gin.H{"code": 7, "msg": "清空失败"})
 		return
@@ -75,3 +161,115 @@ func (o *OperationLogApi) ClearOperationLogs(c *gin.Context) {
 
 	c.JSON(200, gin.H{"code": 0, "msg": "清空成功"})
 }
+
+// ScrubOperationLogs
+// @Summary 按当前脱敏规则清洗历史操作日志
+// @Router /system/log/scrub [post]
+func (o *OperationLogApi) ScrubOperationLogs(c *gin.Context) {
+	count, err := operationLogService.ScrubOperationLogs(c.Request.Context())
+	if err != nil {
+		global.LV_LOG.Error("清洗操作日志失败", zap.Error(err))
+		c.JSON(500, gin.H{"code": 7, "msg": "清洗失败"})
+		return
+	}
+
+	c.JSON(200, gin.H{"code": 0, "data": gin.H{"count": count}, "msg": "清洗完成"})
+}
+
+// GetSinkStats
+// @Summary 获取操作日志写入队列统计（写入、丢弃、失败条数）
+// @Router /system/log/sink [get]
+func (o *OperationLogApi) GetSinkStats(c *gin.Context) {
+	c.JSON(200, gin.H{"code": 0, "data": logsink.GetSink().Stats(), "msg": "success"})
+}
+
+// GetArchiveList
+// @Summary 获取操作日志归档列表
+// @Router /system/log/archive/list [get]
+func (o *OperationLogApi) GetArchiveList(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("pageSize", "10"))
+
+	archives, total, err := logArchiveService.GetArchiveList(c.Request.Context(), page, pageSize)
+	if err != nil {
+		global.LV_LOG.Error("获取归档列表失败", zap.Error(err))
+		c.JSON(500, gin.H{"code": 7, "msg": "获取归档列表失败"})
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"code": 0,
+		"data": gin.H{
+			"list":     archives,
+			"total":    total,
+			"page":     page,
+			"pageSize": pageSize,
+		},
+		"msg": "success",
+	})
+}
+
+// ArchiveOperationLogs
+// @Summary 立即归档过期操作日志（days 为空时使用配置的保留天数）
+// @Router /system/log/archive [post]
+func (o *OperationLogApi) ArchiveOperationLogs(c *gin.Context) {
+	var req struct {
+		Days int `json:"days"`
+	}
+	_ = c.ShouldBindJSON(&req)
+	if req.Days <= 0 {
+		req.Days = global.LV_CONFIG.OperationLog.RetentionDays
+	}
+	if req.Days <= 0 {
+		c.JSON(400, gin.H{"code": 7, "msg": "未配置日志保留天数"})
+		return
+	}
+
+	archives, err := logArchiveService.ArchiveLogs(c.Request.Context(), req.Days)
+	if errors.Is(err, service.ErrLogArchiveRunning) {
+		c.JSON(400, gin.H{"code": 7, "msg": err.Error()})
+		return
+	}
+	if err != nil {
+		global.LV_LOG.Error("归档操作日志失败", zap.Error(err))
+		c.JSON(500, gin.H{"code": 7, "msg": "归档失败"})
+		return
+	}
+
+	var count int64
+	for _, archive := range archives {
+		count += archive.Count
+	}
+	c.JSON(200, gin.H{"code": 0, "data": gin.H{"archives": len(archives), "count": count}, "msg": "归档完成"})
+}
+
+// RestoreArchive
+// @Summary 将归档的操作日志恢复到日志表用于排查
+// @Router /system/log/archive/:id/restore [post]
+func (o *OperationLogApi) RestoreArchive(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+
+	count, err := logArchiveService.RestoreArchive(c.Request.Context(), uint(id))
+	if err != nil {
+		global.LV_LOG.Error("恢复归档失败", zap.Error(err))
+		c.JSON(500, gin.H{"code": 7, "msg": "恢复失败"})
+		return
+	}
+
+	c.JSON(200, gin.H{"code": 0, "data": gin.H{"count": count}, "msg": "恢复成功"})
+}
+
+// ClearRestoredArchive
+// @Summary 删除从归档恢复的操作日志
+// @Router /system/log/archive/:id/restore [delete]
+func (o *OperationLogApi) ClearRestoredArchive(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+
+	if err := logArchiveService.ClearRestoredArchive(c.Request.Context(), uint(id)); err != nil {
+		global.LV_LOG.Error("清除恢复的日志失败", zap.Error(err))
+		c.JSON(500, gin.H{"code": 7, "msg": "清除失败"})
+		return
+	}
+
+	c.JSON(200, gin.H{"code": 0, "msg": "清除成功"})
+}
@@ -4,6 +4,7 @@ import (
 	"go-lv-vue-admin/internal/global"
 	"go-lv-vue-admin/internal/model"
 	"go-lv-vue-admin/internal/service"
+	"go-lv-vue-admin/pkg/utils"
 	"strconv"
 
 	"github.com/gin-gonic/gin"
@@ -24,7 +25,7 @@ func (p *PermissionApi) GetRoleMenus(c *gin.Context) {
 		return
 	}
 
-	menuIds, err := permissionService.GetRoleMenus(uint(roleId))
+	menuIds, err := permissionService.GetRoleMenus(c.Request.Context(), uint(roleId))
 	if err != nil {
 		global.LV_LOG.Error("获取角色菜单失败", zap.Error(err))
 		c.JSON(500, gin.H{"code": 7, "msg": "获取失败"})
@@ -52,7 +53,7 @@ func (p *PermissionApi) SetRoleMenus(c *gin.Context) {
 		return
 	}
 
-	if err := permissionService.SetRoleMenus(uint(roleId), req.MenuIds); err != nil {
+	if err := permissionService.SetRoleMenus(c.Request.Context(), uint(roleId), req.MenuIds); err != nil {
 		global.LV_LOG.Error("设置角色菜单失败", zap.Error(err))
 		c.JSON(500, gin.H{"code": 7, "msg": "设置失败"})
 		return
@@ -61,17 +62,59 @@ func (p *PermissionApi) SetRoleMenus(c *gin.Context) {
 	c.JSON(200, gin.H{"code": 0, "msg": "设置成功"})
 }
 
+// GetRoleApis
+// @Summary 获取角色的接口权限
+// @Router /system/role/:id/apis [get]
+func (p *PermissionApi) GetRoleApis(c *gin.Context) {
+	roleId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(400, gin.H{"code": 7, "msg": "参数错误"})
+		return
+	}
+
+	apiIds, err := permissionService.GetRoleApis(c.Request.Context(), uint(roleId))
+	if err != nil {
+		global.LV_LOG.Error("获取角色接口失败", zap.Error(err))
+		c.JSON(500, gin.H{"code": 7, "msg": "获取失败"})
+		return
+	}
+
+	c.JSON(200, gin.H{"code": 0, "data": apiIds, "msg": "success"})
+}
+
+// SetRoleApis
+// @Summary 设置角色的接口权限
+// @Router /system/role/:id/apis [put]
+func (p *PermissionApi) SetRoleApis(c *gin.Context) {
+	roleId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(400, gin.H{"code": 7, "msg": "参数错误"})
+		return
+	}
+
+	var req struct {
+		ApiIds []uint `json:"apiIds"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, gin.H{"code": 7, "msg": err.Error()})
+		return
+	}
+
+	if err := permissionService.SetRoleApis(c.Request.Context(), uint(roleId), req.ApiIds); err != nil {
+		global.LV_LOG.Error("设置角色接口失败", zap.Error(err))
+		c.JSON(500, gin.H{"code": 7, "msg": "设置失败"})
+		return
+	}
+
+	c.JSON(200, gin.H{"code": 0, "msg": "设置成功"})
+}
+
 // GetUserPermissions
 // @Summary 获取当前用户的按钮权限
 // @Router /user/permissions [get]
 func (p *PermissionApi) GetUserPermissions(c *gin.Context) {
-	roleId, exists := c.Get("roleId")
-	if !exists {
-		c.JSON(401, gin.H{"code": 401, "msg": "未登录"})
-		return
-	}
-
-	permissions, err := permissionService.GetUserPermissions(roleId.(uint))
+	claims := utils.GetClaims(c)
+	permissions, err := permissionService.GetUserPermissions(claims.UserId, claims.ActiveRoleId)
 	if err != nil {
 		global.LV_LOG.Error("获取用户权限失败", zap.Error(err))
 		c.JSON(500, gin.H{"code": 7, "msg": "获取失败"})
@@ -85,13 +128,8 @@ func (p *PermissionApi) GetUserPermissions(c *gin.Context) {
 // @Summary 获取当前用户可访问的菜单
 // @Router /user/menus [get]
 func (p *PermissionApi) GetUserMenus(c *gin.Context) {
-	roleId, exists := c.Get("roleId")
-	if !exists {
-		c.JSON(401, gin.H{"code": 401, "msg": "未登录"})
-		return
-	}
-
-	menus, err := permissionService.GetUserMenus(roleId.(uint))
+	claims := utils.GetClaims(c)
+	menus, err := permissionService.GetUserMenus(claims.UserId, claims.ActiveRoleId)
 	if err != nil {
 		global.LV_LOG.Error("获取用户菜单失败", zap.Error(err))
 		c.JSON(500, gin.H{"code": 7, "msg": "获取失败"})
@@ -103,6 +141,66 @@ func (p *PermissionApi) GetUserMenus(c *gin.Context) {
 	c.JSON(200, gin.H{"code": 0, "data": menuTree, "msg": "success"})
 }
 
+// GetUserRoles
+// @Summary 获取当前用户拥有的角色及当前生效角色
+// @Router /user/roles [get]
+func (p *PermissionApi) GetUserRoles(c *gin.Context) {
+	claims := utils.GetClaims(c)
+	roles, err := service.EffectiveRoles(claims.UserId, 0)
+	if err != nil {
+		global.LV_LOG.Error("获取用户角色失败", zap.Error(err))
+		c.JSON(500, gin.H{"code": 7, "msg": "获取失败"})
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"code": 0,
+		"data": gin.H{
+			"roles":        roles,
+			"activeRoleId": claims.ActiveRoleId,
+		},
+		"msg": "success",
+	})
+}
+
+// SwitchActiveRole
+// @Summary 切换当前会话的生效角色（0 为使用全部角色），并重新签发 Token
+// @Router /user/active-role [put]
+func (p *PermissionApi) SwitchActiveRole(c *gin.Context) {
+	claims := utils.GetClaims(c)
+	var req struct {
+		RoleId uint `json:"roleId"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, gin.H{"code": 7, "msg": err.Error()})
+		return
+	}
+
+	if err := userSessionService.SetActiveRole(claims.ID, claims.UserId, req.RoleId); err != nil {
+		c.JSON(400, gin.H{"code": 7, "msg": err.Error()})
+		return
+	}
+
+	user, err := profileService.GetProfile(claims.UserId)
+	if err != nil {
+		c.JSON(500, gin.H{"code": 7, "msg": "获取用户信息失败"})
+		return
+	}
+	userService := service.UserService{}
+	token, expiresAt, err := userService.CreateToken(*user, claims.ID)
+	if err != nil {
+		global.LV_LOG.Error("get token failed", zap.Error(err))
+		c.JSON(500, gin.H{"code": 7, "msg": "获取Token失败"})
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"code": 0,
+		"data": gin.H{"token": token, "expiresAt": expiresAt, "activeRoleId": req.RoleId},
+		"msg":  "切换成功",
+	})
+}
+
 // buildMenuTree 递归构建菜单树
 func buildMenuTree(menus []model.LvMenu, parentId uint) []model.LvMenu {
 	var tree []model.LvMenu
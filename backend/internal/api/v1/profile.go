@@ -2,7 +2,9 @@ package v1
 
 import (
 	"go-lv-vue-admin/internal/global"
-	"go-lv-vue-admin/internal/model"
+	"go-lv-vue-admin/internal/model/response"
+	"go-lv-vue-admin/internal/service"
+	"go-lv-vue-admin/pkg/utils"
 	"strconv"
 
 	"github.com/gin-gonic/gin"
@@ -11,37 +13,37 @@ import (
 
 type ProfileApi struct{}
 
+var (
+	profileService     = service.ProfileService{}
+	accessTokenService = service.AccessTokenService{}
+)
+
 // GetProfile 获取当前用户信息
 // @Router /profile [get]
 func (p *ProfileApi) GetProfile(c *gin.Context) {
-	// 从 context 获取用户 ID（实际应从 JWT 中解析）
-	userIdStr := c.GetHeader("X-User-Id")
-	if userIdStr == "" {
-		userIdStr = "1" // 临时默认值，后续通过 JWT 中间件获取
-	}
-	userId, _ := strconv.Atoi(userIdStr)
+	claims := utils.GetClaims(c)
 
-	var user model.LvUser
-	if err := global.LV_DB.Preload("Role").First(&user, userId).Error; err != nil {
+	user, err := profileService.GetProfile(claims.UserId)
+	if err != nil {
 		c.JSON(500, gin.H{"code": 7, "msg": "获取用户信息失败"})
 		return
 	}
 
 	c.JSON(200, gin.H{
 		"code": 0,
-		"data": user,
-		"msg":  "success",
+		"data": response.ProfileResponse{
+			LvUser:           *user,
+			Impersonated:     claims.ImpersonatorId != 0,
+			ImpersonatorName: claims.ImpersonatorName,
+		},
+		"msg": "success",
 	})
 }
 
 // UpdateProfile 更新个人资料
 // @Router /profile [put]
 func (p *ProfileApi) UpdateProfile(c *gin.Context) {
-	userIdStr := c.GetHeader("X-User-Id")
-	if userIdStr == "" {
-		userIdStr = "1"
-	}
-	userId, _ := strconv.Atoi(userIdStr)
+	claims := utils.GetClaims(c)
 
 	var req struct {
 		Nickname string `json:"nickname"`
@@ -54,12 +56,7 @@ func (p *ProfileApi) UpdateProfile(c *gin.Context) {
 		return
 	}
 
-	if err := global.LV_DB.Model(&model.LvUser{}).Where("id = ?", userId).Updates(map[string]interface{}{
-		"nickname": req.Nickname,
-		"email":    req.Email,
-		"phone":    req.Phone,
-		"avatar":   req.Avatar,
-	}).Error; err != nil {
+	if err := profileService.UpdateProfile(c.Request.Context(), claims.UserId, req.Nickname, req.Email, req.Phone, req.Avatar); err != nil {
 		global.LV_LOG.Error("更新个人资料失败", zap.Error(err))
 		c.JSON(500, gin.H{"code": 7, "msg": "更新失败"})
 		return
@@ -71,40 +68,275 @@ func (p *ProfileApi) UpdateProfile(c *gin.Context) {
 // ChangePassword 修改密码
 // @Router /profile/password [put]
 func (p *ProfileApi) ChangePassword(c *gin.Context) {
-	userIdStr := c.GetHeader("X-User-Id")
-	if userIdStr == "" {
-		userIdStr = "1"
-	}
-	userId, _ := strconv.Atoi(userIdStr)
+	claims := utils.GetClaims(c)
 
 	var req struct {
 		OldPassword string `json:"oldPassword" binding:"required"`
-		NewPassword string `json:"newPassword" binding:"required,min=6"`
+		NewPassword string `json:"newPassword" binding:"required"`
 	}
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(400, gin.H{"code": 7, "msg": "请填写完整的密码信息"})
 		return
 	}
 
-	// 验证旧密码
-	var user model.LvUser
-	if err := global.LV_DB.First(&user, userId).Error; err != nil {
-		c.JSON(500, gin.H{"code": 7, "msg": "用户不存在"})
+	if err := profileService.ChangePassword(c.Request.Context(), claims.UserId, req.OldPassword, req.NewPassword, claims.ID); err != nil {
+		global.LV_LOG.Error("修改密码失败", zap.Error(err))
+		c.JSON(400, gin.H{"code": 7, "msg": err.Error()})
 		return
 	}
 
-	// TODO: 使用 bcrypt 比较密码
-	if user.Password != req.OldPassword {
-		c.JSON(400, gin.H{"code": 7, "msg": "原密码错误"})
+	// 为当前会话重新签发 Token（清除密码过期标记）
+	user, err := profileService.GetProfile(claims.UserId)
+	if err != nil {
+		c.JSON(500, gin.H{"code": 7, "msg": "获取用户信息失败"})
+		return
+	}
+	userService := service.UserService{}
+	token, expiresAt, err := userService.CreateToken(*user, claims.ID)
+	if err != nil {
+		global.LV_LOG.Error("get token failed", zap.Error(err))
+		c.JSON(500, gin.H{"code": 7, "msg": "获取Token失败"})
 		return
 	}
 
-	// 更新密码
-	if err := global.LV_DB.Model(&model.LvUser{}).Where("id = ?", userId).Update("password", req.NewPassword).Error; err != nil {
-		global.LV_LOG.Error("修改密码失败", zap.Error(err))
-		c.JSON(500, gin.H{"code": 7, "msg": "修改密码失败"})
+	c.JSON(200, gin.H{
+		"code": 0,
+		"data": gin.H{"token": token, "expiresAt": expiresAt},
+		"msg":  "密码修改成功，其他设备已下线",
+	})
+}
+
+// GetSessions 获取我的在线设备
+// @Router /profile/sessions [get]
+func (p *ProfileApi) GetSessions(c *gin.Context) {
+	claims := utils.GetClaims(c)
+
+	sessions, err := userSessionService.GetUserSessions(claims.UserId, claims.ID)
+	if err != nil {
+		global.LV_LOG.Error("获取会话列表失败", zap.Error(err))
+		c.JSON(500, gin.H{"code": 7, "msg": "获取失败"})
+		return
+	}
+
+	c.JSON(200, gin.H{"code": 0, "data": sessions, "msg": "success"})
+}
+
+// RevokeSession 下线我的某个设备
+// @Router /profile/sessions/:sessionId [delete]
+func (p *ProfileApi) RevokeSession(c *gin.Context) {
+	claims := utils.GetClaims(c)
+
+	session, err := userSessionService.GetSession(c.Param("sessionId"))
+	if err != nil || session.UserId != claims.UserId {
+		c.JSON(404, gin.H{"code": 7, "msg": "会话不存在"})
+		return
+	}
+
+	if err := userSessionService.RevokeSession(session.SessionId); err != nil {
+		global.LV_LOG.Error("下线设备失败", zap.Error(err))
+		c.JSON(500, gin.H{"code": 7, "msg": "下线失败"})
+		return
+	}
+
+	c.JSON(200, gin.H{"code": 0, "msg": "已下线"})
+}
+
+// GetTwoFactor 获取两步验证状态
+// @Router /profile/2fa [get]
+func (p *ProfileApi) GetTwoFactor(c *gin.Context) {
+	claims := utils.GetClaims(c)
+
+	status, err := twoFactorService.GetStatus(claims.UserId)
+	if err != nil {
+		global.LV_LOG.Error("获取两步验证状态失败", zap.Error(err))
+		c.JSON(500, gin.H{"code": 7, "msg": "获取失败"})
+		return
+	}
+
+	c.JSON(200, gin.H{"code": 0, "data": status, "msg": "success"})
+}
+
+// SetupTwoFactor 生成两步验证密钥
+// @Router /profile/2fa/setup [post]
+func (p *ProfileApi) SetupTwoFactor(c *gin.Context) {
+	claims := utils.GetClaims(c)
+
+	secret, uri, err := twoFactorService.Setup(claims.UserId)
+	if err != nil {
+		global.LV_LOG.Error("生成两步验证密钥失败", zap.Error(err))
+		c.JSON(400, gin.H{"code": 7, "msg": err.Error()})
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"code": 0,
+		"data": gin.H{"secret": secret, "otpauthUri": uri},
+		"msg":  "success",
+	})
+}
+
+// EnableTwoFactor 确认绑定两步验证
+// @Router /profile/2fa/enable [post]
+func (p *ProfileApi) EnableTwoFactor(c *gin.Context) {
+	claims := utils.GetClaims(c)
+
+	var req struct {
+		Code string `json:"code" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, gin.H{"code": 7, "msg": "请输入验证码"})
+		return
+	}
+
+	codes, err := twoFactorService.Enable(claims.UserId, req.Code)
+	if err != nil {
+		global.LV_LOG.Error("启用两步验证失败", zap.Error(err))
+		c.JSON(400, gin.H{"code": 7, "msg": err.Error()})
+		return
+	}
+
+	// 为当前会话重新签发 Token（清除待绑定标记）
+	user, err := profileService.GetProfile(claims.UserId)
+	if err != nil {
+		c.JSON(500, gin.H{"code": 7, "msg": "获取用户信息失败"})
+		return
+	}
+	userService := service.UserService{}
+	token, expiresAt, err := userService.CreateToken(*user, claims.ID)
+	if err != nil {
+		global.LV_LOG.Error("get token failed", zap.Error(err))
+		c.JSON(500, gin.H{"code": 7, "msg": "获取Token失败"})
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"code": 0,
+		"data": gin.H{"recoveryCodes": codes, "token": token, "expiresAt": expiresAt},
+		"msg":  "两步验证已启用，请妥善保存恢复码",
+	})
+}
+
+// DisableTwoFactor 关闭两步验证
+// @Router /profile/2fa/disable [post]
+func (p *ProfileApi) DisableTwoFactor(c *gin.Context) {
+	claims := utils.GetClaims(c)
+
+	var req struct {
+		Code string `json:"code" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, gin.H{"code": 7, "msg": "请输入验证码"})
+		return
+	}
+
+	if err := twoFactorService.Disable(claims.UserId, req.Code); err != nil {
+		global.LV_LOG.Error("关闭两步验证失败", zap.Error(err))
+		c.JSON(400, gin.H{"code": 7, "msg": err.Error()})
+		return
+	}
+
+	c.JSON(200, gin.H{"code": 0, "msg": "两步验证已关闭"})
+}
+
+// RegenerateRecoveryCodes 重新生成恢复码
+// @Router /profile/2fa/recovery-codes [post]
+func (p *ProfileApi) RegenerateRecoveryCodes(c *gin.Context) {
+	claims := utils.GetClaims(c)
+
+	var req struct {
+		Code string `json:"code" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, gin.H{"code": 7, "msg": "请输入验证码"})
+		return
+	}
+
+	codes, err := twoFactorService.RegenerateRecoveryCodes(claims.UserId, req.Code)
+	if err != nil {
+		global.LV_LOG.Error("生成恢复码失败", zap.Error(err))
+		c.JSON(400, gin.H{"code": 7, "msg": err.Error()})
+		return
+	}
+
+	c.JSON(200, gin.H{"code": 0, "data": gin.H{"recoveryCodes": codes}, "msg": "恢复码已重新生成"})
+}
+
+// GetAccessTokens 获取我的访问令牌
+// @Router /profile/tokens [get]
+func (p *ProfileApi) GetAccessTokens(c *gin.Context) {
+	claims := utils.GetClaims(c)
+
+	tokens, err := accessTokenService.GetUserTokens(claims.UserId)
+	if err != nil {
+		global.LV_LOG.Error("获取访问令牌失败", zap.Error(err))
+		c.JSON(500, gin.H{"code": 7, "msg": "获取失败"})
+		return
+	}
+
+	c.JSON(200, gin.H{"code": 0, "data": tokens, "msg": "success"})
+}
+
+// GetAccessTokenApis 获取可分配给访问令牌的接口（当前用户有权访问的接口）
+// @Router /profile/tokens/apis [get]
+func (p *ProfileApi) GetAccessTokenApis(c *gin.Context) {
+	claims := utils.GetClaims(c)
+
+	apis, err := accessTokenService.GetAvailableApis(claims.UserId)
+	if err != nil {
+		global.LV_LOG.Error("获取可用接口失败", zap.Error(err))
+		c.JSON(500, gin.H{"code": 7, "msg": "获取失败"})
+		return
+	}
+
+	c.JSON(200, gin.H{"code": 0, "data": apis, "msg": "success"})
+}
+
+// CreateAccessToken 创建访问令牌
+// @Router /profile/tokens [post]
+func (p *ProfileApi) CreateAccessToken(c *gin.Context) {
+	claims := utils.GetClaims(c)
+
+	var req struct {
+		Name          string `json:"name" binding:"required"`
+		ExpiresInDays int    `json:"expiresInDays" binding:"required"`
+		ApiIds        []uint `json:"apiIds"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, gin.H{"code": 7, "msg": err.Error()})
+		return
+	}
+
+	user, err := profileService.GetProfile(claims.UserId)
+	if err != nil {
+		c.JSON(500, gin.H{"code": 7, "msg": "获取用户信息失败"})
+		return
+	}
+
+	token, raw, err := accessTokenService.CreateToken(user, req.Name, req.ExpiresInDays, req.ApiIds)
+	if err != nil {
+		global.LV_LOG.Error("创建访问令牌失败", zap.Error(err))
+		c.JSON(400, gin.H{"code": 7, "msg": err.Error()})
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"code": 0,
+		"data": gin.H{"token": raw, "accessToken": token},
+		"msg":  "创建成功，令牌只显示一次，请妥善保存",
+	})
+}
+
+// DeleteAccessToken 吊销访问令牌
+// @Router /profile/tokens/:id [delete]
+func (p *ProfileApi) DeleteAccessToken(c *gin.Context) {
+	claims := utils.GetClaims(c)
+	id, _ := strconv.Atoi(c.Param("id"))
+
+	if err := accessTokenService.DeleteToken(claims.UserId, uint(id)); err != nil {
+		global.LV_LOG.Error("吊销访问令牌失败", zap.Error(err))
+		c.JSON(400, gin.H{"code": 7, "msg": err.Error()})
 		return
 	}
 
-	c.JSON(200, gin.H{"code": 0, "msg": "密码修改成功"})
+	c.JSON(200, gin.H{"code": 0, "msg": "已吊销"})
 }
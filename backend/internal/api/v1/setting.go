@@ -3,6 +3,7 @@ package v1
 import (
 	"go-lv-vue-admin/internal/global"
 	"go-lv-vue-admin/internal/service"
+	"go-lv-vue-admin/pkg/utils"
 
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
@@ -14,7 +15,7 @@ var settingService = service.SettingService{}
 
 // GetSettings 获取所有设置
 func (s *SettingApi) GetSettings(c *gin.Context) {
-	settings, err := settingService.GetAllSettings()
+	settings, err := settingService.GetAllSettings(utils.GetClaims(c).TenantId)
 	if err != nil {
 		global.LV_LOG.Error("获取设置失败", zap.Error(err))
 		c.JSON(500, gin.H{"code": 7, "msg": "获取设置失败"})
@@ -24,13 +25,16 @@ func (s *SettingApi) GetSettings(c *gin.Context) {
 	c.JSON(200, gin.H{"code": 0, "data": settings, "msg": "success"})
 }
 
-// GetPublicSettings 获取公开设置（无需登录）
+// GetPublicSettings 获取公开设置（无需登录），多租户模式下可通过 tenantCode 获取租户的设置
 func (s *SettingApi) GetPublicSettings(c *gin.Context) {
-	settings, err := settingService.GetPublicSettings()
+	tenantId, _ := tenantService.ResolveTenant(c.Query("tenantCode"))
+	settings, err := settingService.GetPublicSettings(tenantId)
 	if err != nil {
 		c.JSON(500, gin.H{"code": 7, "msg": "获取设置失败"})
 		return
 	}
+	// 登录页据此决定是否显示租户编码输入框
+	settings["tenant_enable"] = global.LV_CONFIG.Tenant.Enable
 
 	c.JSON(200, gin.H{"code": 0, "data": settings, "msg": "success"})
 }
@@ -43,9 +47,9 @@ func (s *SettingApi) UpdateSettings(c *gin.Context) {
 		return
 	}
 
-	if err := settingService.BatchUpdateSettings(req); err != nil {
+	if err := settingService.BatchUpdateSettings(c.Request.Context(), utils.GetClaims(c).TenantId, req); err != nil {
 		global.LV_LOG.Error("更新设置失败", zap.Error(err))
-		c.JSON(500, gin.H{"code": 7, "msg": "更新设置失败"})
+		c.JSON(500, gin.H{"code": 7, "msg": err.Error()})
 		return
 	}
 
@@ -0,0 +1,113 @@
+package v1
+
+import (
+	"go-lv-vue-admin/internal/global"
+	"go-lv-vue-admin/internal/model"
+	"go-lv-vue-admin/internal/service"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+type SystemApiApi struct{}
+
+var systemApiService = service.SystemApiService{}
+
+// GetApiList
+// @Summary 获取接口列表
+// @Router /system/api/list [get]
+func (s *SystemApiApi) GetApiList(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("pageSize", "10"))
+	path := c.Query("path")
+	method := c.Query("method")
+	apiGroup := c.Query("apiGroup")
+
+	apis, total, err := systemApiService.GetApiList(page, pageSize, path, method, apiGroup)
+	if err != nil {
+		global.LV_LOG.Error("获取接口列表失败", zap.Error(err))
+		c.JSON(500, gin.H{"code": 7, "msg": "获取接口列表失败"})
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"code": 0,
+		"data": gin.H{
+			"list":     apis,
+			"total":    total,
+			"page":     page,
+			"pageSize": pageSize,
+		},
+		"msg": "success",
+	})
+}
+
+// GetAllApis
+// @Summary 获取全部接口（角色授权使用）
+// @Router /system/api/all [get]
+func (s *SystemApiApi) GetAllApis(c *gin.Context) {
+	apis, err := systemApiService.GetAllApis()
+	if err != nil {
+		global.LV_LOG.Error("获取接口列表失败", zap.Error(err))
+		c.JSON(500, gin.H{"code": 7, "msg": "获取接口列表失败"})
+		return
+	}
+
+	c.JSON(200, gin.H{"code": 0, "data": apis, "msg": "success"})
+}
+
+// CreateApi
+// @Summary 创建接口
+// @Router /system/api [post]
+func (s *SystemApiApi) CreateApi(c *gin.Context) {
+	var api model.LvApi
+	if err := c.ShouldBindJSON(&api); err != nil {
+		c.JSON(400, gin.H{"code": 7, "msg": err.Error()})
+		return
+	}
+
+	if err := systemApiService.CreateApi(&api); err != nil {
+		global.LV_LOG.Error("创建接口失败", zap.Error(err))
+		c.JSON(500, gin.H{"code": 7, "msg": err.Error()})
+		return
+	}
+
+	c.JSON(200, gin.H{"code": 0, "msg": "创建成功"})
+}
+
+// UpdateApi
+// @Summary 更新接口
+// @Router /system/api/:id [put]
+func (s *SystemApiApi) UpdateApi(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+	var api model.LvApi
+	if err := c.ShouldBindJSON(&api); err != nil {
+		c.JSON(400, gin.H{"code": 7, "msg": err.Error()})
+		return
+	}
+	api.ID = uint(id)
+
+	if err := systemApiService.UpdateApi(&api); err != nil {
+		global.LV_LOG.Error("更新接口失败", zap.Error(err))
+		c.JSON(500, gin.H{"code": 7, "msg": err.Error()})
+		return
+	}
+
+	c.JSON(200, gin.H{"code": 0, "msg": "更新成功"})
+}
+
+// DeleteApi
+// @Summary 删除接口
+// @Router /system/api/:id [delete]
+func (s *SystemApiApi) DeleteApi(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+
+	if err := systemApiService.DeleteApi(uint(id)); err != nil {
+		global.LV_LOG.Error("删除接口失败", zap.Error(err))
+		c.JSON(500, gin.H{"code": 7, "msg": "删除失败"})
+		return
+	}
+
+	c.JSON(200, gin.H{"code": 0, "msg": "删除成功"})
+}
@@ -0,0 +1,111 @@
+package v1
+
+import (
+	"go-lv-vue-admin/internal/global"
+	"go-lv-vue-admin/internal/model"
+	"go-lv-vue-admin/internal/service"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+type SystemDeptApi struct{}
+
+var systemDeptService = service.SystemDeptService{}
+
+// GetDeptTree
+// @Summary 获取部门树
+// @Router /system/dept/tree [get]
+func (s *SystemDeptApi) GetDeptTree(c *gin.Context) {
+	depts, err := systemDeptService.GetDeptTree(c.Request.Context())
+	if err != nil {
+		global.LV_LOG.Error("获取部门树失败", zap.Error(err))
+		c.JSON(500, gin.H{"code": 7, "msg": "获取部门树失败"})
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"code": 0,
+		"data": depts,
+		"msg":  "success",
+	})
+}
+
+// CreateDept
+// @Summary 创建部门
+// @Router /system/dept [post]
+func (s *SystemDeptApi) CreateDept(c *gin.Context) {
+	var dept model.LvDept
+	if err := c.ShouldBindJSON(&dept); err != nil {
+		c.JSON(400, gin.H{"code": 7, "msg": err.Error()})
+		return
+	}
+
+	if err := systemDeptService.CreateDept(c.Request.Context(), &dept); err != nil {
+		global.LV_LOG.Error("创建部门失败", zap.Error(err))
+		c.JSON(500, gin.H{"code": 7, "msg": err.Error()})
+		return
+	}
+
+	c.JSON(200, gin.H{"code": 0, "msg": "创建成功"})
+}
+
+// UpdateDept
+// @Summary 更新部门
+// @Router /system/dept/:id [put]
+func (s *SystemDeptApi) UpdateDept(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+	var dept model.LvDept
+	if err := c.ShouldBindJSON(&dept); err != nil {
+		c.JSON(400, gin.H{"code": 7, "msg": err.Error()})
+		return
+	}
+	dept.ID = uint(id)
+
+	if err := systemDeptService.UpdateDept(c.Request.Context(), &dept); err != nil {
+		global.LV_LOG.Error("更新部门失败", zap.Error(err))
+		c.JSON(500, gin.H{"code": 7, "msg": "更新部门失败"})
+		return
+	}
+
+	c.JSON(200, gin.H{"code": 0, "msg": "更新成功"})
+}
+
+// MoveDept
+// @Summary 移动部门
+// @Router /system/dept/:id/move [put]
+func (s *SystemDeptApi) MoveDept(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+	var req struct {
+		ParentId uint `json:"parentId"`
+		Sort     int  `json:"sort"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, gin.H{"code": 7, "msg": err.Error()})
+		return
+	}
+
+	if err := systemDeptService.MoveDept(c.Request.Context(), uint(id), req.ParentId, req.Sort); err != nil {
+		global.LV_LOG.Error("移动部门失败", zap.Error(err))
+		c.JSON(400, gin.H{"code": 7, "msg": err.Error()})
+		return
+	}
+
+	c.JSON(200, gin.H{"code": 0, "msg": "移动成功"})
+}
+
+// DeleteDept
+// @Summary 删除部门
+// @Router /system/dept/:id [delete]
+func (s *SystemDeptApi) DeleteDept(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+
+	if err := systemDeptService.DeleteDept(c.Request.Context(), uint(id)); err != nil {
+		global.LV_LOG.Error("删除部门失败", zap.Error(err))
+		c.JSON(400, gin.H{"code": 7, "msg": err.Error()})
+		return
+	}
+
+	c.JSON(200, gin.H{"code": 0, "msg": "删除成功"})
+}
@@ -42,7 +42,7 @@ func (s *SystemMenuApi) CreateMenu(c *gin.Context) {
 		return
 	}
 
-	if err := systemMenuService.CreateMenu(&menu); err != nil {
+	if err := systemMenuService.CreateMenu(c.Request.Context(), &menu); err != nil {
 		global.LV_LOG.Error("创建菜单失败", zap.Error(err))
 		c.JSON(500, gin.H{"code": 7, "msg": err.Error()})
 		return
@@ -63,7 +63,7 @@ func (s *SystemMenuApi) UpdateMenu(c *gin.Context) {
 	}
 	menu.ID = uint(id)
 
-	if err := systemMenuService.UpdateMenu(&menu); err != nil {
+	if err := systemMenuService.UpdateMenu(c.Request.Context(), &menu); err != nil {
 		global.LV_LOG.Error("更新菜单失败", zap.Error(err))
 		c.JSON(500, gin.H{"code": 7, "msg": "更新菜单失败"})
 		return
@@ -78,7 +78,7 @@ func (s *SystemMenuApi) UpdateMenu(c *gin.Context) {
 func (s *SystemMenuApi) DeleteMenu(c *gin.Context) {
 	id, _ := strconv.Atoi(c.Param("id"))
 
-	if err := systemMenuService.DeleteMenu(uint(id)); err != nil {
+	if err := systemMenuService.DeleteMenu(c.Request.Context(), uint(id)); err != nil {
 		global.LV_LOG.Error("删除菜单失败", zap.Error(err))
 		c.JSON(500, gin.H{"code": 7, "msg": err.Error()})
 		return
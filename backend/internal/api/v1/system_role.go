@@ -18,7 +18,7 @@ var systemRoleService = service.SystemRoleService{}
 // @Summary 获取角色列表
 // @Router /system/role/list [get]
 func (s *SystemRoleApi) GetRoleList(c *gin.Context) {
-	roles, err := systemRoleService.GetRoleList()
+	roles, err := systemRoleService.GetRoleList(c.Request.Context())
 	if err != nil {
 		global.LV_LOG.Error("获取角色列表失败", zap.Error(err))
 		c.JSON(500, gin.H{"code": 7, "msg": "获取角色列表失败"})
@@ -42,7 +42,7 @@ func (s *SystemRoleApi) CreateRole(c *gin.Context) {
 		return
 	}
 
-	if err := systemRoleService.CreateRole(&role); err != nil {
+	if err := systemRoleService.CreateRole(c.Request.Context(), &role); err != nil {
 		global.LV_LOG.Error("创建角色失败", zap.Error(err))
 		c.JSON(500, gin.H{"code": 7, "msg": err.Error()})
 		return
@@ -63,7 +63,7 @@ func (s *SystemRoleApi) UpdateRole(c *gin.Context) {
 	}
 	role.ID = uint(id)
 
-	if err := systemRoleService.UpdateRole(&role); err != nil {
+	if err := systemRoleService.UpdateRole(c.Request.Context(), &role); err != nil {
 		global.LV_LOG.Error("更新角色失败", zap.Error(err))
 		c.JSON(500, gin.H{"code": 7, "msg": "更新角色失败"})
 		return
@@ -78,7 +78,7 @@ func (s *SystemRoleApi) UpdateRole(c *gin.Context) {
 func (s *SystemRoleApi) DeleteRole(c *gin.Context) {
 	id, _ := strconv.Atoi(c.Param("id"))
 
-	if err := systemRoleService.DeleteRole(uint(id)); err != nil {
+	if err := systemRoleService.DeleteRole(c.Request.Context(), uint(id)); err != nil {
 		global.LV_LOG.Error("删除角色失败", zap.Error(err))
 		c.JSON(500, gin.H{"code": 7, "msg": err.Error()})
 		return
@@ -86,3 +86,47 @@ func (s *SystemRoleApi) DeleteRole(c *gin.Context) {
 
 	c.JSON(200, gin.H{"code": 0, "msg": "删除成功"})
 }
+
+// GetRoleDataScope
+// @Summary 获取角色数据范围
+// @Router /system/role/:id/data-scope [get]
+func (s *SystemRoleApi) GetRoleDataScope(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+
+	dataScope, deptIds, err := systemRoleService.GetRoleDataScope(c.Request.Context(), uint(id))
+	if err != nil {
+		global.LV_LOG.Error("获取角色数据范围失败", zap.Error(err))
+		c.JSON(500, gin.H{"code": 7, "msg": "获取失败"})
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"code": 0,
+		"data": gin.H{"dataScope": dataScope, "deptIds": deptIds},
+		"msg":  "success",
+	})
+}
+
+// SetRoleDataScope
+// @Summary 设置角色数据范围
+// @Router /system/role/:id/data-scope [put]
+func (s *SystemRoleApi) SetRoleDataScope(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+
+	var req struct {
+		DataScope int    `json:"dataScope" binding:"required"`
+		DeptIds   []uint `json:"deptIds"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, gin.H{"code": 7, "msg": err.Error()})
+		return
+	}
+
+	if err := systemRoleService.SetRoleDataScope(c.Request.Context(), uint(id), req.DataScope, req.DeptIds); err != nil {
+		global.LV_LOG.Error("设置角色数据范围失败", zap.Error(err))
+		c.JSON(400, gin.H{"code": 7, "msg": err.Error()})
+		return
+	}
+
+	c.JSON(200, gin.H{"code": 0, "msg": "设置成功"})
+}
@@ -0,0 +1,87 @@
+package v1
+
+import (
+	"go-lv-vue-admin/internal/global"
+	"go-lv-vue-admin/pkg/utils"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+type SystemSessionApi struct{}
+
+// GetSessionList
+// @Summary 获取在线会话列表
+// @Router /system/session/list [get]
+func (s *SystemSessionApi) GetSessionList(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("pageSize", "10"))
+	username := c.Query("username")
+	claims := utils.GetClaims(c)
+
+	sessions, total, err := userSessionService.GetOnlineSessions(claims.TenantId, page, pageSize, username, claims.ID)
+	if err != nil {
+		global.LV_LOG.Error("获取在线会话失败", zap.Error(err))
+		c.JSON(500, gin.H{"code": 7, "msg": "获取在线会话失败"})
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"code": 0,
+		"data": gin.H{
+			"list":     sessions,
+			"total":    total,
+			"page":     page,
+			"pageSize": pageSize,
+		},
+		"msg": "success",
+	})
+}
+
+// ForceLogout
+// @Summary 强制下线指定会话
+// @Router /system/session/:sessionId [delete]
+func (s *SystemSessionApi) ForceLogout(c *gin.Context) {
+	sessionId := c.Param("sessionId")
+	if !userSessionService.SessionInTenant(sessionId, utils.GetClaims(c).TenantId) {
+		c.JSON(404, gin.H{"code": 7, "msg": "会话不存在"})
+		return
+	}
+
+	if err := userSessionService.RevokeSession(sessionId); err != nil {
+		global.LV_LOG.Error("强制下线失败", zap.Error(err))
+		c.JSON(500, gin.H{"code": 7, "msg": "强制下线失败"})
+		return
+	}
+
+	c.JSON(200, gin.H{"code": 0, "msg": "已强制下线"})
+}
+
+// ForceLogoutUser
+// @Summary 强制下线用户的全部会话
+// @Router /system/session/user/:userId [delete]
+func (s *SystemSessionApi) ForceLogoutUser(c *gin.Context) {
+	userId, err := strconv.Atoi(c.Param("userId"))
+	if err != nil {
+		c.JSON(400, gin.H{"code": 7, "msg": "参数错误"})
+		return
+	}
+	claims := utils.GetClaims(c)
+	if userId == 1 && claims.UserId != 1 {
+		c.JSON(403, gin.H{"code": 7, "msg": "不能强制下线超级管理员"})
+		return
+	}
+	if !userSessionService.UserInScope(c.Request.Context(), claims.UserId, claims.ActiveRoleId, uint(userId)) {
+		c.JSON(404, gin.H{"code": 7, "msg": "用户不存在或不在数据范围内"})
+		return
+	}
+
+	if err := userSessionService.RevokeUserSessions(uint(userId), ""); err != nil {
+		global.LV_LOG.Error("强制下线失败", zap.Error(err))
+		c.JSON(500, gin.H{"code": 7, "msg": "强制下线失败"})
+		return
+	}
+
+	c.JSON(200, gin.H{"code": 0, "msg": "已强制下线该用户的全部会话"})
+}
@@ -1,9 +1,12 @@
 package v1
 
 import (
+	"fmt"
 	"go-lv-vue-admin/internal/global"
 	"go-lv-vue-admin/internal/model"
+	"go-lv-vue-admin/internal/model/request"
 	"go-lv-vue-admin/internal/service"
+	"go-lv-vue-admin/pkg/utils"
 	"strconv"
 
 	"github.com/gin-gonic/gin"
@@ -28,8 +31,10 @@ func (s *SystemUserApi) GetUserList(c *gin.Context) {
 		statusVal, _ := strconv.Atoi(statusStr)
 		status = &statusVal
 	}
+	deptId, _ := strconv.Atoi(c.Query("deptId"))
 
-	users, total, err := systemUserService.GetUserList(page, pageSize, username, phone, status)
+	claims := utils.GetClaims(c)
+	users, total, err := systemUserService.GetUserList(c.Request.Context(), claims.UserId, claims.ActiveRoleId, page, pageSize, username, phone, status, uint(deptId))
 	if err != nil {
 		global.LV_LOG.Error("获取用户列表失败", zap.Error(err))
 		c.JSON(500, gin.H{"code": 7, "msg": "获取用户列表失败"})
@@ -48,17 +53,93 @@ func (s *SystemUserApi) GetUserList(c *gin.Context) {
 	})
 }
 
+// ExportUsers
+// @Summary 按列表的筛选条件导出用户（format=csv/xlsx）
+// @Router /system/user/export [get]
+func (s *SystemUserApi) ExportUsers(c *gin.Context) {
+	var status *int
+	if statusStr := c.Query("status"); statusStr != "" {
+		statusVal, _ := strconv.Atoi(statusStr)
+		status = &statusVal
+	}
+	deptId, _ := strconv.Atoi(c.Query("deptId"))
+
+	claims := utils.GetClaims(c)
+	export := systemUserService.ExportUsers(claims.UserId, claims.ActiveRoleId, c.Query("username"), c.Query("phone"), status, uint(deptId))
+	writeExport(c, export)
+}
+
+// GetImportTemplate
+// @Summary 下载用户导入模板（format=csv/xlsx）
+// @Router /system/user/import/template [get]
+func (s *SystemUserApi) GetImportTemplate(c *gin.Context) {
+	format := c.DefaultQuery("format", utils.SheetXLSX)
+	if !utils.IsSheetFormat(format) {
+		c.JSON(400, gin.H{"code": 7, "msg": "不支持的文件格式"})
+		return
+	}
+	writeSheet(c, systemUserService.UserImportTemplate(), format)
+}
+
+// ImportUsers
+// @Summary 从 CSV / XLSX 批量导入用户（dryRun=true 时只校验），存在错误时不导入并返回校验报告
+// @Router /system/user/import [post]
+func (s *SystemUserApi) ImportUsers(c *gin.Context) {
+	file, header, err := c.Request.FormFile("file")
+	if err != nil {
+		c.JSON(400, gin.H{"code": 7, "msg": "请选择要导入的文件"})
+		return
+	}
+	defer file.Close()
+
+	format, ok := utils.SheetFormat(header.Filename)
+	if !ok {
+		c.JSON(400, gin.H{"code": 7, "msg": "仅支持 csv、xlsx 文件"})
+		return
+	}
+	dryRun, _ := strconv.ParseBool(c.PostForm("dryRun"))
+
+	claims := utils.GetClaims(c)
+	result, err := systemUserService.ImportUsers(c.Request.Context(), claims.UserId, claims.ActiveRoleId, format, file, dryRun)
+	if err != nil {
+		global.LV_LOG.Error("导入用户失败", zap.Error(err))
+		c.JSON(500, gin.H{"code": 7, "msg": err.Error()})
+		return
+	}
+
+	msg := fmt.Sprintf("导入成功，共 %d 条", result.Created)
+	switch {
+	case len(result.Errors) > 0:
+		msg = fmt.Sprintf("%d 条数据校验失败，未导入任何数据", len(result.Errors))
+	case result.DryRun:
+		msg = fmt.Sprintf("校验通过，共 %d 条", result.Total)
+	}
+	c.JSON(200, gin.H{"code": 0, "data": result, "msg": msg})
+}
+
 // CreateUser
 // @Summary 创建用户
 // @Router /system/user [post]
 func (s *SystemUserApi) CreateUser(c *gin.Context) {
-	var user model.LvUser
-	if err := c.ShouldBindJSON(&user); err != nil {
+	var req request.CreateUser
+	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(400, gin.H{"code": 7, "msg": err.Error()})
 		return
 	}
+	user := model.LvUser{
+		Username: req.Username,
+		Password: req.Password,
+		Nickname: req.Nickname,
+		Email:    req.Email,
+		Phone:    req.Phone,
+		RoleId:   req.RoleId,
+		RoleIds:  req.RoleIds,
+		DeptId:   req.DeptId,
+		Status:   req.Status,
+	}
 
-	if err := systemUserService.CreateUser(&user); err != nil {
+	claims := utils.GetClaims(c)
+	if err := systemUserService.CreateUser(c.Request.Context(), claims.UserId, claims.ActiveRoleId, &user); err != nil {
 		global.LV_LOG.Error("创建用户失败", zap.Error(err))
 		c.JSON(500, gin.H{"code": 7, "msg": err.Error()})
 		return
@@ -79,9 +160,10 @@ func (s *SystemUserApi) UpdateUser(c *gin.Context) {
 	}
 	user.ID = uint(id)
 
-	if err := systemUserService.UpdateUser(&user); err != nil {
+	claims := utils.GetClaims(c)
+	if err := systemUserService.UpdateUser(c.Request.Context(), claims.UserId, claims.ActiveRoleId, &user); err != nil {
 		global.LV_LOG.Error("更新用户失败", zap.Error(err))
-		c.JSON(500, gin.H{"code": 7, "msg": "更新用户失败"})
+		c.JSON(500, gin.H{"code": 7, "msg": err.Error()})
 		return
 	}
 
@@ -94,7 +176,8 @@ func (s *SystemUserApi) UpdateUser(c *gin.Context) {
 func (s *SystemUserApi) DeleteUser(c *gin.Context) {
 	id, _ := strconv.Atoi(c.Param("id"))
 
-	if err := systemUserService.DeleteUser(uint(id)); err != nil {
+	claims := utils.GetClaims(c)
+	if err := systemUserService.DeleteUser(c.Request.Context(), claims.UserId, claims.ActiveRoleId, uint(id)); err != nil {
 		global.LV_LOG.Error("删除用户失败", zap.Error(err))
 		c.JSON(500, gin.H{"code": 7, "msg": err.Error()})
 		return
@@ -117,25 +200,102 @@ func (s *SystemUserApi) ResetPassword(c *gin.Context) {
 		return
 	}
 
-	newPassword := req.Password
-	if newPassword == "" {
-		newPassword = "123456" // 默认密码
-	}
-
-	if err := systemUserService.ResetPassword(uint(id), newPassword); err != nil {
+	// 未指定密码时生成随机密码
+	claims := utils.GetClaims(c)
+	newPassword, err := systemUserService.ResetPassword(c.Request.Context(), claims.UserId, claims.ActiveRoleId, uint(id), req.Password)
+	if err != nil {
 		global.LV_LOG.Error("重置密码失败", zap.Error(err))
 		c.JSON(500, gin.H{"code": 7, "msg": err.Error()})
 		return
 	}
 
-	c.JSON(200, gin.H{"code": 0, "msg": "密码已重置为: " + newPassword})
+	c.JSON(200, gin.H{"code": 0, "data": gin.H{"password": newPassword}, "msg": "密码已重置，用户下次登录时需修改密码"})
+}
+
+// UnlockUser
+// @Summary 解锁因登录失败过多被锁定的用户
+// @Router /system/user/:id/unlock [put]
+func (s *SystemUserApi) UnlockUser(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+	claims := utils.GetClaims(c)
+	if !userSessionService.UserInScope(c.Request.Context(), claims.UserId, claims.ActiveRoleId, uint(id)) {
+		c.JSON(404, gin.H{"code": 7, "msg": "用户不存在或不在数据范围内"})
+		return
+	}
+
+	if err := loginGuardService.Unlock(c.Request.Context(), uint(id)); err != nil {
+		global.LV_LOG.Error("解锁用户失败", zap.Error(err))
+		c.JSON(500, gin.H{"code": 7, "msg": "解锁失败"})
+		return
+	}
+
+	c.JSON(200, gin.H{"code": 0, "msg": "解锁成功"})
+}
+
+// ResetTwoFactor
+// @Summary 重置用户的两步验证（用于丢失认证设备）
+// @Router /system/user/:id/reset-2fa [put]
+func (s *SystemUserApi) ResetTwoFactor(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+	claims := utils.GetClaims(c)
+	if !userSessionService.UserInScope(c.Request.Context(), claims.UserId, claims.ActiveRoleId, uint(id)) {
+		c.JSON(404, gin.H{"code": 7, "msg": "用户不存在或不在数据范围内"})
+		return
+	}
+
+	if err := twoFactorService.Reset(c.Request.Context(), uint(id)); err != nil {
+		global.LV_LOG.Error("重置两步验证失败", zap.Error(err))
+		c.JSON(500, gin.H{"code": 7, "msg": "重置失败"})
+		return
+	}
+
+	c.JSON(200, gin.H{"code": 0, "msg": "两步验证已重置"})
+}
+
+// Impersonate
+// @Summary 模拟登录指定用户（用于排查用户看到的菜单和权限）
+// @Router /system/user/:id/impersonate [post]
+func (s *SystemUserApi) Impersonate(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+	claims := utils.GetClaims(c)
+
+	// 访问令牌和模拟会话都不能再发起模拟
+	if claims.ID == "" {
+		c.JSON(403, gin.H{"code": 7, "msg": "访问令牌不能用于模拟登录"})
+		return
+	}
+	if claims.ImpersonatorId != 0 {
+		c.JSON(403, gin.H{"code": 7, "msg": "模拟登录中不能再次模拟"})
+		return
+	}
+
+	user, session, err := systemUserService.Impersonate(c.Request.Context(), claims.UserId, claims.ActiveRoleId, uint(id), c.ClientIP(), c.Request.UserAgent())
+	if err != nil {
+		global.LV_LOG.Error("模拟登录失败", zap.Error(err))
+		c.JSON(400, gin.H{"code": 7, "msg": err.Error()})
+		return
+	}
+
+	userService := service.UserService{}
+	token, expiresAt, err := userService.CreateToken(*user, session.SessionId)
+	if err != nil {
+		global.LV_LOG.Error("get token failed", zap.Error(err))
+		c.JSON(500, gin.H{"code": 7, "msg": "获取Token失败"})
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"code": 0,
+		"data": gin.H{"user": user, "token": token, "expiresAt": expiresAt, "impersonatorName": claims.Username},
+		"msg":  "success",
+	})
 }
 
 // GetRoleOptions
 // @Summary 获取角色选项
 // @Router /system/user/role-options [get]
 func (s *SystemUserApi) GetRoleOptions(c *gin.Context) {
-	roles, err := systemUserService.GetRoleList()
+	roles, err := systemUserService.GetRoleList(c.Request.Context())
 	if err != nil {
 		c.JSON(500, gin.H{"code": 7, "msg": "获取角色列表失败"})
 		return
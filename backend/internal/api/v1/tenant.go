@@ -0,0 +1,98 @@
+package v1
+
+import (
+	"go-lv-vue-admin/internal/global"
+	"go-lv-vue-admin/internal/model"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+type TenantApi struct{}
+
+// GetTenantList
+// @Summary 获取租户列表
+// @Router /platform/tenant/list [get]
+func (t *TenantApi) GetTenantList(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("pageSize", "10"))
+	name := c.Query("name")
+
+	tenants, total, err := tenantService.GetTenantList(page, pageSize, name)
+	if err != nil {
+		global.LV_LOG.Error("获取租户列表失败", zap.Error(err))
+		c.JSON(500, gin.H{"code": 7, "msg": "获取租户列表失败"})
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"code": 0,
+		"data": gin.H{
+			"list":     tenants,
+			"total":    total,
+			"page":     page,
+			"pageSize": pageSize,
+		},
+		"msg": "success",
+	})
+}
+
+// CreateTenant
+// @Summary 创建租户（同时创建租户管理员账号）
+// @Router /platform/tenant [post]
+func (t *TenantApi) CreateTenant(c *gin.Context) {
+	var req struct {
+		model.LvTenant
+		AdminUsername string `json:"adminUsername"`
+		AdminPassword string `json:"adminPassword"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, gin.H{"code": 7, "msg": err.Error()})
+		return
+	}
+
+	if err := tenantService.CreateTenant(&req.LvTenant, req.AdminUsername, req.AdminPassword); err != nil {
+		global.LV_LOG.Error("创建租户失败", zap.Error(err))
+		c.JSON(500, gin.H{"code": 7, "msg": err.Error()})
+		return
+	}
+
+	c.JSON(200, gin.H{"code": 0, "msg": "创建成功"})
+}
+
+// UpdateTenant
+// @Summary 更新租户
+// @Router /platform/tenant/:id [put]
+func (t *TenantApi) UpdateTenant(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+	var tenant model.LvTenant
+	if err := c.ShouldBindJSON(&tenant); err != nil {
+		c.JSON(400, gin.H{"code": 7, "msg": err.Error()})
+		return
+	}
+	tenant.ID = uint(id)
+
+	if err := tenantService.UpdateTenant(&tenant); err != nil {
+		global.LV_LOG.Error("更新租户失败", zap.Error(err))
+		c.JSON(500, gin.H{"code": 7, "msg": "更新租户失败"})
+		return
+	}
+
+	c.JSON(200, gin.H{"code": 0, "msg": "更新成功"})
+}
+
+// DeleteTenant
+// @Summary 删除租户
+// @Router /platform/tenant/:id [delete]
+func (t *TenantApi) DeleteTenant(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+
+	if err := tenantService.DeleteTenant(uint(id)); err != nil {
+		global.LV_LOG.Error("删除租户失败", zap.Error(err))
+		c.JSON(500, gin.H{"code": 7, "msg": "删除租户失败"})
+		return
+	}
+
+	c.JSON(200, gin.H{"code": 0, "msg": "删除成功"})
+}
@@ -1,11 +1,17 @@
 package v1
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"go-lv-vue-admin/internal/global"
+	"go-lv-vue-admin/internal/logsink"
 	"go-lv-vue-admin/internal/model"
 	"go-lv-vue-admin/internal/model/request"
 	"go-lv-vue-admin/internal/model/response"
 	"go-lv-vue-admin/internal/service"
+	"go-lv-vue-admin/pkg/utils"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
@@ -13,6 +19,15 @@ import (
 
 type UserApi struct{}
 
+var (
+	userSessionService    = service.UserSessionService{}
+	loginGuardService     = service.LoginGuardService{}
+	passwordPolicyService = service.PasswordPolicyService{}
+	twoFactorService      = service.TwoFactorService{}
+	oidcAuthProvider      = service.OidcAuthProvider{}
+	tenantService         = service.TenantService{}
+)
+
 // Login
 // @Tags Base
 // @Summary Login
@@ -28,25 +43,310 @@ func (b *UserApi) Login(c *gin.Context) {
 		return
 	}
 
-	// TODO: Verify Captcha
+	ip := c.ClientIP()
+
+	// 多租户模式下按租户编码识别租户，之后的查询都限定在该租户内
+	tenantId, err := tenantService.ResolveTenant(l.TenantCode)
+	if err != nil {
+		c.JSON(400, gin.H{"code": 7, "msg": err.Error()})
+		return
+	}
+	ctx := tenantContext(c, tenantId)
+
+	// 账号锁定期间不校验密码；验证身份前统一返回登录失败，避免通过锁定提示判断用户名是否存在
+	if lockedUntil := loginGuardService.LockedUntil(ctx, l.Username); lockedUntil != nil {
+		recordLoginFailure(c, tenantId, l.Username, fmt.Sprintf("账号已锁定至 %s", lockedUntil.Format("15:04:05")))
+		respondLoginFailure(ctx, c, l.Username, ip, "用户名或密码错误")
+		return
+	}
+
+	// 失败次数过多时需要验证码
+	if loginGuardService.CaptchaRequired(ctx, l.Username, ip) && !loginGuardService.VerifyCaptcha(l.CaptchaId, l.Captcha) {
+		c.JSON(400, gin.H{"code": 7, "data": gin.H{"captchaRequired": true}, "msg": "验证码错误"})
+		return
+	}
 
 	u := &model.LvUser{Username: l.Username, Password: l.Password}
 	userService := service.UserService{}
 
-	user, err := userService.Login(u)
+	user, err := userService.Login(ctx, u)
 	if err != nil {
 		global.LV_LOG.Error("login failed", zap.Error(err))
-		c.JSON(400, gin.H{"code": 7, "msg": "用户名或密码错误"})
+		recordLoginFailure(c, tenantId, l.Username, err.Error())
+		if lockedUntil := loginGuardService.RecordFailure(ctx, l.Username, ip); lockedUntil != nil {
+			recordLockout(c, tenantId, l.Username, *lockedUntil)
+		}
+		msg := "用户名或密码错误"
+		if errors.Is(err, service.ErrNoRoleMapped) {
+			msg = err.Error()
+		}
+		respondLoginFailure(ctx, c, l.Username, ip, msg)
+		return
+	}
+	loginGuardService.RecordSuccess(ctx, l.Username)
+
+	completeLogin(c, user, l.Device)
+}
+
+// respondLoginFailure 密码登录失败的统一响应，锁定与否都不在提示中体现
+func respondLoginFailure(ctx context.Context, c *gin.Context, username, ip, msg string) {
+	c.JSON(400, gin.H{
+		"code": 7,
+		"data": gin.H{"captchaRequired": loginGuardService.CaptchaRequired(ctx, username, ip)},
+		"msg":  msg,
+	})
+}
+
+// LoginMfa
+// @Tags Base
+// @Summary Complete login with a TOTP code or recovery code
+// @accept application/json
+// @Produce application/json
+// @Param data body request.LoginMfa true "Mfa Token, Code"
+// @Success 200 {object} response.Response{data=response.LoginResponse,msg=string}
+// @Router /base/login/2fa [post]
+func (b *UserApi) LoginMfa(c *gin.Context) {
+	var req request.LoginMfa
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, gin.H{"code": 7, "msg": err.Error()})
+		return
+	}
+
+	claims, err := utils.ParseToken(req.MfaToken)
+	if err != nil || !claims.MfaPending {
+		c.JSON(401, gin.H{"code": 401, "msg": "验证已过期，请重新登录"})
+		return
+	}
+
+	ctx := tenantContext(c, claims.TenantId)
+	if lockedUntil := loginGuardService.LockedUntil(ctx, claims.Username); lockedUntil != nil {
+		c.JSON(400, gin.H{"code": 7, "msg": fmt.Sprintf("账号已锁定，请于 %s 后重试", lockedUntil.Format("15:04:05"))})
+		return
+	}
+
+	var user model.LvUser
+	if err := global.LV_DB.Preload("Role").First(&user, claims.UserId).Error; err != nil {
+		c.JSON(401, gin.H{"code": 401, "msg": "用户不存在"})
+		return
+	}
+
+	// 动态码错误同样计入登录失败次数
+	if !twoFactorService.Verify(&user, req.Code) {
+		recordLoginFailure(c, user.TenantId, user.Username, "两步验证码错误")
+		if lockedUntil := loginGuardService.RecordFailure(ctx, user.Username, c.ClientIP()); lockedUntil != nil {
+			recordLockout(c, user.TenantId, user.Username, *lockedUntil)
+			c.JSON(400, gin.H{"code": 7, "msg": fmt.Sprintf("登录失败次数过多，账号已锁定至 %s", lockedUntil.Format("15:04:05"))})
+			return
+		}
+		c.JSON(400, gin.H{"code": 7, "msg": "验证码错误"})
+		return
+	}
+	loginGuardService.RecordSuccess(ctx, user.Username)
+
+	if user.Status != 1 {
+		c.JSON(400, gin.H{"code": 7, "msg": "用户被冻结"})
+		return
+	}
+
+	respondLogin(c, &user, req.Device)
+}
+
+// OidcUrl
+// @Tags Base
+// @Summary Get the OIDC authorization URL
+// @Produce application/json
+// @Param tenantCode query string false "Tenant code (multi-tenant mode only)"
+// @Router /base/oidc/url [get]
+func (b *UserApi) OidcUrl(c *gin.Context) {
+	tenantId, err := tenantService.ResolveTenant(c.Query("tenantCode"))
+	if err != nil {
+		c.JSON(400, gin.H{"code": 7, "msg": err.Error()})
+		return
+	}
+
+	url, state, err := oidcAuthProvider.AuthCodeURL(c.Request.Context(), tenantId)
+	if err != nil {
+		global.LV_LOG.Error("oidc auth url failed", zap.Error(err))
+		c.JSON(400, gin.H{"code": 7, "msg": "单点登录不可用"})
+		return
+	}
+
+	c.JSON(200, gin.H{"code": 0, "data": gin.H{"url": url, "state": state}, "msg": "success"})
+}
+
+// OidcLogin
+// @Tags Base
+// @Summary Complete OIDC login with the authorization code
+// @accept application/json
+// @Produce application/json
+// @Param data body request.OidcLogin true "Code, State"
+// @Success 200 {object} response.Response{data=response.LoginResponse,msg=string}
+// @Router /base/oidc/login [post]
+func (b *UserApi) OidcLogin(c *gin.Context) {
+	var req request.OidcLogin
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, gin.H{"code": 7, "msg": err.Error()})
+		return
+	}
+
+	user, err := oidcAuthProvider.Exchange(c.Request.Context(), req.Code, req.State)
+	if err != nil {
+		global.LV_LOG.Error("oidc login failed", zap.Error(err))
+		c.JSON(400, gin.H{"code": 7, "msg": "单点登录失败: " + err.Error()})
+		return
+	}
+
+	completeLogin(c, user, req.Device)
+}
+
+// Refresh
+// @Tags Base
+// @Summary Exchange a refresh token for a new access token (the refresh token is rotated)
+// @accept application/json
+// @Produce application/json
+// @Param data body request.RefreshToken true "Refresh Token"
+// @Success 200 {object} response.Response{data=response.RefreshResponse,msg=string}
+// @Router /base/refresh [post]
+func (b *UserApi) Refresh(c *gin.Context) {
+	var req request.RefreshToken
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, gin.H{"code": 7, "msg": err.Error()})
+		return
+	}
+
+	session, refreshToken, err := userSessionService.RotateRefreshToken(req.RefreshToken, c.ClientIP(), c.Request.UserAgent())
+	if err != nil {
+		global.LV_LOG.Warn("refresh token failed", zap.Error(err))
+		c.JSON(401, gin.H{"code": 401, "msg": err.Error()})
+		return
+	}
+
+	// 重新读取用户，确保角色、状态为最新
+	var user model.LvUser
+	if err := global.LV_DB.First(&user, session.UserId).Error; err != nil || user.Status != 1 {
+		userSessionService.RevokeSession(session.SessionId)
+		c.JSON(401, gin.H{"code": 401, "msg": "用户不存在或已被冻结"})
+		return
+	}
+	if err := tenantService.CheckTenantActive(user.TenantId); err != nil {
+		userSessionService.RevokeSession(session.SessionId)
+		c.JSON(401, gin.H{"code": 401, "msg": err.Error()})
+		return
+	}
+
+	userService := service.UserService{}
+	token, expiresAt, err := userService.CreateToken(user, session.SessionId)
+	if err != nil {
+		global.LV_LOG.Error("get token failed", zap.Error(err))
+		c.JSON(500, gin.H{"code": 7, "msg": "获取Token失败"})
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"code": 0,
+		"data": response.RefreshResponse{
+			Token:            token,
+			ExpiresAt:        expiresAt,
+			RefreshToken:     refreshToken,
+			RefreshExpiresAt: session.ExpiresAt.Unix(),
+		},
+		"msg": "刷新成功",
+	})
+}
+
+// Logout
+// @Tags Base
+// @Summary Revoke the current session
+// @Router /base/logout [post]
+func (b *UserApi) Logout(c *gin.Context) {
+	claims := utils.GetClaims(c)
+	if err := userSessionService.RevokeSession(claims.ID); err != nil {
+		global.LV_LOG.Error("logout failed", zap.Error(err))
+		c.JSON(500, gin.H{"code": 7, "msg": "退出失败"})
+		return
+	}
+	c.JSON(200, gin.H{"code": 0, "msg": "已退出登录"})
+}
+
+// LogoutAll
+// @Tags Base
+// @Summary Revoke all sessions of the current user
+// @Router /base/logout/all [post]
+func (b *UserApi) LogoutAll(c *gin.Context) {
+	claims := utils.GetClaims(c)
+	if err := userSessionService.RevokeUserSessions(claims.UserId, ""); err != nil {
+		global.LV_LOG.Error("logout all failed", zap.Error(err))
+		c.JSON(500, gin.H{"code": 7, "msg": "退出失败"})
+		return
+	}
+	c.JSON(200, gin.H{"code": 0, "msg": "已退出全部设备"})
+}
+
+// Captcha
+// @Tags Base
+// @Summary Generate an image captcha
+// @Produce application/json
+// @Router /base/captcha [get]
+func (b *UserApi) Captcha(c *gin.Context) {
+	id, b64s, err := loginGuardService.GenerateCaptcha()
+	if err != nil {
+		global.LV_LOG.Error("generate captcha failed", zap.Error(err))
+		c.JSON(500, gin.H{"code": 7, "msg": "验证码获取失败"})
 		return
 	}
 
+	c.JSON(200, gin.H{
+		"code": 0,
+		"data": gin.H{
+			"captchaId":       id,
+			"picPath":         b64s,
+			"captchaRequired": loginGuardService.CaptchaRequired(captchaContext(c), c.Query("username"), c.ClientIP()),
+		},
+		"msg": "success",
+	})
+}
+
+// completeLogin 第一因素认证通过后：检查用户状态，已启用两步验证时返回待完成 Token，否则直接登录
+func completeLogin(c *gin.Context, user *model.LvUser, device string) {
 	if user.Status != 1 {
 		c.JSON(400, gin.H{"code": 7, "msg": "用户被冻结"})
 		return
 	}
 
-	// Generate Token
-	token, expiresAt, err := userService.CreateToken(*user)
+	// 已启用两步验证：仅返回待完成 Token，需在 /base/login/2fa 提交动态码
+	if user.TotpEnabled {
+		userService := service.UserService{}
+		mfaToken, mfaExpiresAt, err := userService.CreateMfaToken(*user)
+		if err != nil {
+			global.LV_LOG.Error("get mfa token failed", zap.Error(err))
+			c.JSON(500, gin.H{"code": 7, "msg": "获取Token失败"})
+			return
+		}
+		c.JSON(200, gin.H{
+			"code": 0,
+			"data": response.MfaPendingResponse{
+				MfaRequired:  true,
+				MfaToken:     mfaToken,
+				MfaExpiresAt: mfaExpiresAt,
+			},
+			"msg": "请输入两步验证码",
+		})
+		return
+	}
+
+	respondLogin(c, user, device)
+}
+
+// respondLogin 创建会话并返回登录结果
+func respondLogin(c *gin.Context, user *model.LvUser, device string) {
+	session, refreshToken, err := userSessionService.CreateSession(user.ID, device, c.ClientIP(), c.Request.UserAgent())
+	if err != nil {
+		global.LV_LOG.Error("create session failed", zap.Error(err))
+		c.JSON(500, gin.H{"code": 7, "msg": "创建会话失败"})
+		return
+	}
+	userService := service.UserService{}
+	token, expiresAt, err := userService.CreateToken(*user, session.SessionId)
 	if err != nil {
 		global.LV_LOG.Error("get token failed", zap.Error(err))
 		c.JSON(500, gin.H{"code": 7, "msg": "获取Token失败"})
@@ -56,10 +356,55 @@ func (b *UserApi) Login(c *gin.Context) {
 	c.JSON(200, gin.H{
 		"code": 0,
 		"data": response.LoginResponse{
-			User:      *user,
-			Token:     token,
-			ExpiresAt: expiresAt,
+			User:               *user,
+			Token:              token,
+			ExpiresAt:          expiresAt,
+			RefreshToken:       refreshToken,
+			RefreshExpiresAt:   session.ExpiresAt.Unix(),
+			MustChangePassword: passwordPolicyService.IsExpired(user),
+			MustSetupMfa:       twoFactorService.SetupRequired(user),
 		},
 		"msg": "登录成功",
 	})
 }
+
+// tenantContext 多租户模式下返回携带登录租户的 context（登录接口未经过 JWTAuth）
+func tenantContext(c *gin.Context, tenantId uint) context.Context {
+	if global.LV_CONFIG.Tenant.Enable {
+		return utils.WithTenant(c.Request.Context(), tenantId)
+	}
+	return c.Request.Context()
+}
+
+// captchaContext 获取验证码时按租户编码区分同名用户的失败计数，租户无效时按平台处理
+func captchaContext(c *gin.Context) context.Context {
+	tenantId, _ := tenantService.ResolveTenant(c.Query("tenantCode"))
+	return tenantContext(c, tenantId)
+}
+
+// recordLockout 将账号锁定事件写入操作日志
+func recordLockout(c *gin.Context, tenantId uint, username string, lockedUntil time.Time) {
+	global.LV_LOG.Warn("account locked", zap.String("username", username), zap.String("ip", c.ClientIP()))
+	writeLoginLog(c, tenantId, username, "账号锁定", "账号锁定至 "+lockedUntil.Format("2006-01-02 15:04:05"))
+}
+
+// recordLoginFailure 将登录失败写入操作日志（登录接口不经过操作日志中间件），用于审计和安全告警
+func recordLoginFailure(c *gin.Context, tenantId uint, username, reason string) {
+	writeLoginLog(c, tenantId, username, "登录失败", reason)
+}
+
+// writeLoginLog 写入登录相关的安全事件，不记录请求体（含密码）
+func writeLoginLog(c *gin.Context, tenantId uint, username, action, response string) {
+	logsink.Write(&model.LvOperationLog{
+		TenantId:  tenantId,
+		Username:  username,
+		Ip:        c.ClientIP(),
+		Method:    c.Request.Method,
+		Path:      c.Request.URL.Path,
+		Status:    400,
+		UserAgent: c.Request.UserAgent(),
+		Response:  response,
+		Module:    "登录",
+		Action:    action,
+	})
+}
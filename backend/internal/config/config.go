@@ -7,6 +7,13 @@ type Config struct {
 	Zap      Zap      `mapstructure:"zap" json:"zap" yaml:"zap"`
 	Cors     Cors     `mapstructure:"cors" json:"cors" yaml:"cors"`
 	Storage  Storage  `mapstructure:"storage" json:"storage" yaml:"storage"`
+	Login    Login    `mapstructure:"login" json:"login" yaml:"login"`
+	Auth     Auth     `mapstructure:"auth" json:"auth" yaml:"auth"`
+	Tenant   Tenant   `mapstructure:"tenant" json:"tenant" yaml:"tenant"`
+
+	OperationLog OperationLog `mapstructure:"operation_log" json:"operation_log" yaml:"operation_log"`
+	Export       Export       `mapstructure:"export" json:"export" yaml:"export"`
+	Alert        Alert        `mapstructure:"alert" json:"alert" yaml:"alert"`
 }
 
 type Server struct {
@@ -21,9 +28,113 @@ type Database struct {
 }
 
 type JWT struct {
-	SigningKey  string `mapstructure:"signing_key" json:"signing_key" yaml:"signing_key"`
-	ExpiresTime string `mapstructure:"expires_time" json:"expires_time" yaml:"expires_time"`
-	BufferTime  string `mapstructure:"buffer_time" json:"buffer_time" yaml:"buffer_time"`
+	SigningKey         string `mapstructure:"signing_key" json:"signing_key" yaml:"signing_key"`
+	ExpiresTime        string `mapstructure:"expires_time" json:"expires_time" yaml:"expires_time"`                         // Access Token 有效期
+	RefreshExpiresTime string `mapstructure:"refresh_expires_time" json:"refresh_expires_time" yaml:"refresh_expires_time"` // Refresh Token 有效期
+	ImpersonateExpires string `mapstructure:"impersonate_expires" json:"impersonate_expires" yaml:"impersonate_expires"`    // 模拟登录会话有效期（不可刷新）
+}
+
+// Login 登录安全配置（验证码、账号锁定与两步验证）
+type Login struct {
+	CaptchaThreshold int    `mapstructure:"captcha_threshold" json:"captcha_threshold" yaml:"captcha_threshold"` // 失败 N 次后需要验证码，0 表示始终需要
+	LockThreshold    int    `mapstructure:"lock_threshold" json:"lock_threshold" yaml:"lock_threshold"`          // 失败 M 次后锁定账号，0 表示不锁定
+	LockDuration     string `mapstructure:"lock_duration" json:"lock_duration" yaml:"lock_duration"`             // 锁定时长
+	FailureWindow    string `mapstructure:"failure_window" json:"failure_window" yaml:"failure_window"`          // 失败次数统计窗口
+	CaptchaLength    int    `mapstructure:"captcha_length" json:"captcha_length" yaml:"captcha_length"`          // 验证码长度
+	MfaTokenExpires  string `mapstructure:"mfa_token_expires" json:"mfa_token_expires" yaml:"mfa_token_expires"` // 两步验证待完成 Token 有效期
+}
+
+// Tenant 多租户模式，开启后业务数据按租户隔离，TenantId 为 0 的数据属于平台
+type Tenant struct {
+	Enable bool `mapstructure:"enable" json:"enable" yaml:"enable"`
+}
+
+// OperationLog 操作日志配置，请求和响应内容先按字段名脱敏再截断保存
+type OperationLog struct {
+	RedactKeys       []string `mapstructure:"redact_keys" json:"redact_keys" yaml:"redact_keys"`                   // 需要脱敏的字段名，不区分大小写，支持 * 通配；为空时使用默认规则
+	RedactSkipPaths  []string `mapstructure:"redact_skip_paths" json:"redact_skip_paths" yaml:"redact_skip_paths"` // 不做脱敏的接口路径前缀
+	CaptureMultipart bool     `mapstructure:"capture_multipart" json:"capture_multipart" yaml:"capture_multipart"` // 是否记录 multipart 请求体（文件上传），默认不记录
+
+	// 异步批量写入：日志先进入有界队列，由后台协程按条数或时间批量插入
+	QueueSize     int    `mapstructure:"queue_size" json:"queue_size" yaml:"queue_size"`             // 队列容量
+	BatchSize     int    `mapstructure:"batch_size" json:"batch_size" yaml:"batch_size"`             // 每批最多写入条数
+	FlushInterval string `mapstructure:"flush_interval" json:"flush_interval" yaml:"flush_interval"` // 未满一批时的最长等待时间
+	BlockTimeout  string `mapstructure:"block_timeout" json:"block_timeout" yaml:"block_timeout"`    // 队列满时请求最长等待时间，超时丢弃；为空时直接丢弃
+
+	// 保留策略：超过保留天数的日志压缩为 JSONL 归档到存储驱动后从数据库删除
+	RetentionDays   int    `mapstructure:"retention_days" json:"retention_days" yaml:"retention_days"`       // 数据库中保留的天数，0 表示不清理
+	ArchiveInterval string `mapstructure:"archive_interval" json:"archive_interval" yaml:"archive_interval"` // 归档任务执行间隔，默认 24h
+}
+
+// Export 数据导出配置
+type Export struct {
+	AsyncThreshold int `mapstructure:"async_threshold" json:"async_threshold" yaml:"async_threshold"` // 超过该条数时转为后台导出，结果文件保存到存储驱动；默认 5000
+}
+
+// Alert 安全告警配置，规则通过管理接口维护，这里配置规则引擎和通知渠道
+type Alert struct {
+	QueueSize int          `mapstructure:"queue_size" json:"queue_size" yaml:"queue_size"` // 待评估日志的队列容量，队列满时丢弃，不影响日志写入
+	Webhook   AlertWebhook `mapstructure:"webhook" json:"webhook" yaml:"webhook"`
+	Smtp      AlertSmtp    `mapstructure:"smtp" json:"smtp" yaml:"smtp"`
+}
+
+// AlertWebhook 以 JSON POST 推送告警，配置 Secret 时附带 HMAC-SHA256 签名
+type AlertWebhook struct {
+	Url     string `mapstructure:"url" json:"url" yaml:"url"`
+	Secret  string `mapstructure:"secret" json:"secret" yaml:"secret"`    // 签名放在 X-Signature 头
+	Timeout string `mapstructure:"timeout" json:"timeout" yaml:"timeout"` // 默认 5s
+}
+
+// AlertSmtp 邮件通知
+type AlertSmtp struct {
+	Host     string   `mapstructure:"host" json:"host" yaml:"host"`
+	Port     int      `mapstructure:"port" json:"port" yaml:"port"` // 465 使用 TLS 直连，其他端口在服务器支持时使用 STARTTLS
+	Username string   `mapstructure:"username" json:"username" yaml:"username"`
+	Password string   `mapstructure:"password" json:"password" yaml:"password"`
+	From     string   `mapstructure:"from" json:"from" yaml:"from"`
+	To       []string `mapstructure:"to" json:"to" yaml:"to"`
+}
+
+// Auth 认证源配置，本地账号始终可用，LDAP / OIDC 按需开启
+type Auth struct {
+	Ldap Ldap `mapstructure:"ldap" json:"ldap" yaml:"ldap"`
+	Oidc Oidc `mapstructure:"oidc" json:"oidc" yaml:"oidc"`
+}
+
+// RoleMapping 目录组与系统角色的映射
+type RoleMapping struct {
+	Group string `mapstructure:"group" json:"group" yaml:"group"` // 组名或组 DN，不区分大小写
+	Role  string `mapstructure:"role" json:"role" yaml:"role"`    // 角色关键字
+}
+
+type Ldap struct {
+	Enable             bool          `mapstructure:"enable" json:"enable" yaml:"enable"`
+	Url                string        `mapstructure:"url" json:"url" yaml:"url"`                                                    // ldap://host:389 或 ldaps://host:636
+	StartTls           bool          `mapstructure:"start_tls" json:"start_tls" yaml:"start_tls"`                                  // ldap:// 连接后升级为 TLS
+	InsecureSkipVerify bool          `mapstructure:"insecure_skip_verify" json:"insecure_skip_verify" yaml:"insecure_skip_verify"` // 跳过证书校验，仅用于测试
+	BindDn             string        `mapstructure:"bind_dn" json:"bind_dn" yaml:"bind_dn"`                                        // 用于查找用户的服务账号
+	BindPassword       string        `mapstructure:"bind_password" json:"bind_password" yaml:"bind_password"`
+	BaseDn             string        `mapstructure:"base_dn" json:"base_dn" yaml:"base_dn"`
+	UserFilter         string        `mapstructure:"user_filter" json:"user_filter" yaml:"user_filter"` // %s 替换为用户名
+	NicknameAttr       string        `mapstructure:"nickname_attr" json:"nickname_attr" yaml:"nickname_attr"`
+	EmailAttr          string        `mapstructure:"email_attr" json:"email_attr" yaml:"email_attr"`
+	PhoneAttr          string        `mapstructure:"phone_attr" json:"phone_attr" yaml:"phone_attr"`
+	GroupAttr          string        `mapstructure:"group_attr" json:"group_attr" yaml:"group_attr"` // 用户条目上的组属性，如 memberOf
+	RoleMappings       []RoleMapping `mapstructure:"role_mappings" json:"role_mappings" yaml:"role_mappings"`
+	DefaultRole        string        `mapstructure:"default_role" json:"default_role" yaml:"default_role"` // 未匹配到组时使用的角色，为空则拒绝登录
+}
+
+type Oidc struct {
+	Enable        bool          `mapstructure:"enable" json:"enable" yaml:"enable"`
+	Issuer        string        `mapstructure:"issuer" json:"issuer" yaml:"issuer"` // 通过 /.well-known/openid-configuration 自动发现
+	ClientId      string        `mapstructure:"client_id" json:"client_id" yaml:"client_id"`
+	ClientSecret  string        `mapstructure:"client_secret" json:"client_secret" yaml:"client_secret"`
+	RedirectUrl   string        `mapstructure:"redirect_url" json:"redirect_url" yaml:"redirect_url"` // 前端回调页地址
+	Scopes        []string      `mapstructure:"scopes" json:"scopes" yaml:"scopes"`
+	UsernameClaim string        `mapstructure:"username_claim" json:"username_claim" yaml:"username_claim"`
+	GroupsClaim   string        `mapstructure:"groups_claim" json:"groups_claim" yaml:"groups_claim"`
+	RoleMappings  []RoleMapping `mapstructure:"role_mappings" json:"role_mappings" yaml:"role_mappings"`
+	DefaultRole   string        `mapstructure:"default_role" json:"default_role" yaml:"default_role"`
 }
 
 type Zap struct {
@@ -0,0 +1,294 @@
+package core
+
+import (
+	"fmt"
+	"go-lv-vue-admin/internal/model"
+	"go-lv-vue-admin/internal/service"
+	"reflect"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// auditMaxRows 单条更新、删除语句最多记录的行数，超出部分不记录审计日志
+const auditMaxRows = 500
+
+// auditMask 标记为 audit:"mask" 的字段发生变化时记录的值
+const auditMask = "******"
+
+// registerAuditCallbacks 注册审计回调：实现 model.Auditable 的模型在创建、更新、删除后记录字段级变更
+// 更新、删除前按相同条件查出旧数据，更新后重新查出新数据进行比较；审计日志在业务事务提交前写入
+func registerAuditCallbacks(db *gorm.DB) error {
+	callbacks := []error{
+		db.Callback().Create().After("gorm:create").Before("gorm:commit_or_rollback_transaction").Register("audit:create", auditCreate),
+		db.Callback().Update().Before("gorm:update").After("tenant:update").Register("audit:before_update", auditSnapshot),
+		db.Callback().Update().After("gorm:update").Before("gorm:commit_or_rollback_transaction").Register("audit:update", auditUpdate),
+		db.Callback().Delete().Before("gorm:delete").After("tenant:delete").Register("audit:before_delete", auditSnapshot),
+		db.Callback().Delete().After("gorm:delete").Before("gorm:commit_or_rollback_transaction").Register("audit:delete", auditDelete),
+	}
+	for _, err := range callbacks {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// auditEntity 模型实现 model.Auditable 时返回其实体类型
+func auditEntity(db *gorm.DB) (string, bool) {
+	if db.Statement.Schema == nil || db.Statement.Schema.PrioritizedPrimaryField == nil {
+		return "", false
+	}
+	auditable, ok := reflect.New(db.Statement.Schema.ModelType).Interface().(model.Auditable)
+	if !ok {
+		return "", false
+	}
+	return auditable.AuditEntity(), true
+}
+
+// auditCreate 记录新建数据的全部字段
+func auditCreate(db *gorm.DB) {
+	if db.Error != nil {
+		return
+	}
+	entity, ok := auditEntity(db)
+	if !ok {
+		return
+	}
+
+	var logs []model.LvAuditLog
+	eachRow(db.Statement.ReflectValue, func(row reflect.Value) {
+		var changes model.AuditChanges
+		for _, field := range auditFields(db.Statement.Schema) {
+			if value := auditValue(db, field, row); value != "" {
+				changes = append(changes, model.AuditChange{Field: field.DBName, After: maskValue(field, value)})
+			}
+		}
+		logs = append(logs, auditLog(db, entity, model.AuditCreate, row, changes))
+	})
+	service.WriteAuditLogs(db, logs)
+}
+
+// auditSnapshot 更新、删除前查出将被修改的旧数据
+func auditSnapshot(db *gorm.DB) {
+	if db.Error != nil {
+		return
+	}
+	if _, ok := auditEntity(db); !ok {
+		return
+	}
+	tx, ok := auditQuery(db)
+	if !ok {
+		return
+	}
+	rows := reflect.New(reflect.SliceOf(db.Statement.Schema.ModelType))
+	if err := tx.Limit(auditMaxRows).Find(rows.Interface()).Error; err != nil {
+		return
+	}
+	db.Statement.Settings.Store("audit:before", rows.Elem())
+}
+
+// auditUpdate 重新查出更新后的数据，与旧数据逐字段比较
+func auditUpdate(db *gorm.DB) {
+	entity, before, ok := auditBefore(db)
+	if !ok {
+		return
+	}
+	stmt := db.Statement
+	pk := stmt.Schema.PrioritizedPrimaryField
+
+	ids := make([]interface{}, 0, before.Len())
+	for i := 0; i < before.Len(); i++ {
+		id, _ := pk.ValueOf(stmt.Context, before.Index(i))
+		ids = append(ids, id)
+	}
+	after := reflect.New(reflect.SliceOf(stmt.Schema.ModelType))
+	err := db.Session(&gorm.Session{NewDB: true, SkipHooks: true}).Unscoped().
+		Where(clause.IN{Column: clause.Column{Table: clause.CurrentTable, Name: pk.DBName}, Values: ids}).
+		Find(after.Interface()).Error
+	if err != nil {
+		return
+	}
+	afterRows := make(map[string]reflect.Value, after.Elem().Len())
+	for i := 0; i < after.Elem().Len(); i++ {
+		row := after.Elem().Index(i)
+		afterRows[auditValue(db, pk, row)] = row
+	}
+
+	var logs []model.LvAuditLog
+	for i := 0; i < before.Len(); i++ {
+		old := before.Index(i)
+		row, found := afterRows[auditValue(db, pk, old)]
+		if !found {
+			continue
+		}
+		var changes model.AuditChanges
+		for _, field := range auditFields(stmt.Schema) {
+			oldValue, newValue := auditValue(db, field, old), auditValue(db, field, row)
+			if oldValue != newValue {
+				changes = append(changes, model.AuditChange{Field: field.DBName, Before: maskValue(field, oldValue), After: maskValue(field, newValue)})
+			}
+		}
+		if len(changes) > 0 {
+			logs = append(logs, auditLog(db, entity, model.AuditUpdate, row, changes))
+		}
+	}
+	service.WriteAuditLogs(db, logs)
+}
+
+// auditDelete 记录被删除数据的全部字段
+func auditDelete(db *gorm.DB) {
+	entity, before, ok := auditBefore(db)
+	if !ok {
+		return
+	}
+
+	var logs []model.LvAuditLog
+	for i := 0; i < before.Len(); i++ {
+		row := before.Index(i)
+		var changes model.AuditChanges
+		for _, field := range auditFields(db.Statement.Schema) {
+			if value := auditValue(db, field, row); value != "" {
+				changes = append(changes, model.AuditChange{Field: field.DBName, Before: maskValue(field, value)})
+			}
+		}
+		logs = append(logs, auditLog(db, entity, model.AuditDelete, row, changes))
+	}
+	service.WriteAuditLogs(db, logs)
+}
+
+// auditBefore 获取 auditSnapshot 查出的旧数据，语句执行失败或未影响任何行时不记录
+func auditBefore(db *gorm.DB) (string, reflect.Value, bool) {
+	if db.Error != nil || db.Statement.RowsAffected == 0 {
+		return "", reflect.Value{}, false
+	}
+	entity, ok := auditEntity(db)
+	if !ok {
+		return "", reflect.Value{}, false
+	}
+	value, ok := db.Statement.Settings.Load("audit:before")
+	if !ok {
+		return "", reflect.Value{}, false
+	}
+	before := value.(reflect.Value)
+	return entity, before, before.Len() > 0
+}
+
+// auditQuery 构造与当前更新、删除语句条件相同的查询：WHERE 条件加上模型中非零的主键
+// 没有任何条件时（全表操作）不查询
+func auditQuery(db *gorm.DB) (*gorm.DB, bool) {
+	stmt := db.Statement
+	tx := db.Session(&gorm.Session{NewDB: true, SkipHooks: true}).Model(reflect.New(stmt.Schema.ModelType).Interface())
+	if stmt.Unscoped {
+		tx = tx.Unscoped()
+	}
+
+	hasCondition := false
+	if where, ok := stmt.Clauses["WHERE"]; ok {
+		if expr, ok := where.Expression.(clause.Where); ok && len(expr.Exprs) > 0 {
+			tx.Statement.AddClause(clause.Where{Exprs: expr.Exprs})
+			hasCondition = true
+		}
+	}
+
+	pk := stmt.Schema.PrioritizedPrimaryField
+	var ids []interface{}
+	eachRow(stmt.ReflectValue, func(row reflect.Value) {
+		if id, isZero := pk.ValueOf(stmt.Context, row); !isZero {
+			ids = append(ids, id)
+		}
+	})
+	if len(ids) > 0 {
+		tx.Statement.AddClause(clause.Where{Exprs: []clause.Expression{
+			clause.IN{Column: clause.Column{Table: clause.CurrentTable, Name: pk.DBName}, Values: ids},
+		}})
+		hasCondition = true
+	}
+	return tx, hasCondition
+}
+
+// auditLog 构造一条审计日志，多租户数据归属该数据所在的租户
+func auditLog(db *gorm.DB, entity, action string, row reflect.Value, changes model.AuditChanges) model.LvAuditLog {
+	log := model.LvAuditLog{
+		Entity:   entity,
+		EntityId: auditValue(db, db.Statement.Schema.PrioritizedPrimaryField, row),
+		Action:   action,
+		Changes:  changes,
+	}
+	if field := db.Statement.Schema.LookUpField("TenantId"); field != nil {
+		if tenantId, ok := auditFieldValue(db, field, row).(uint); ok {
+			log.TenantId = tenantId
+		}
+	}
+	return log
+}
+
+// auditFields 需要记录的字段：排除关联、时间戳和标记为 audit:"-" 的字段
+func auditFields(s *schema.Schema) []*schema.Field {
+	var fields []*schema.Field
+	for _, field := range s.Fields {
+		if field.DBName == "" || field.AutoCreateTime > 0 || field.AutoUpdateTime > 0 || field.Name == "DeletedAt" {
+			continue
+		}
+		if field.Tag.Get("audit") == "-" {
+			continue
+		}
+		fields = append(fields, field)
+	}
+	return fields
+}
+
+// auditFieldValue 读取字段值，指针解引用后返回，nil 指针返回 nil
+func auditFieldValue(db *gorm.DB, field *schema.Field, row reflect.Value) interface{} {
+	value, _ := field.ValueOf(db.Statement.Context, row)
+	rv := reflect.ValueOf(value)
+	for rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return nil
+		}
+		rv = rv.Elem()
+	}
+	if !rv.IsValid() {
+		return nil
+	}
+	return rv.Interface()
+}
+
+// auditValue 字段值转为字符串用于比较和展示
+func auditValue(db *gorm.DB, field *schema.Field, row reflect.Value) string {
+	switch value := auditFieldValue(db, field, row).(type) {
+	case nil:
+		return ""
+	case time.Time:
+		if value.IsZero() {
+			return ""
+		}
+		return value.Format("2006-01-02 15:04:05")
+	case []byte:
+		return string(value)
+	default:
+		return fmt.Sprint(value)
+	}
+}
+
+// maskValue 脱敏字段只记录是否有值
+func maskValue(field *schema.Field, value string) string {
+	if value != "" && field.Tag.Get("audit") == "mask" {
+		return auditMask
+	}
+	return value
+}
+
+// eachRow 遍历结构体或切片中的每一行
+func eachRow(rv reflect.Value, fn func(row reflect.Value)) {
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < rv.Len(); i++ {
+			fn(reflect.Indirect(rv.Index(i)))
+		}
+	case reflect.Struct:
+		fn(rv)
+	}
+}
@@ -2,6 +2,7 @@ package core
 
 import (
 	"go-lv-vue-admin/internal/global"
+	"go-lv-vue-admin/internal/service"
 	"os"
 	"path/filepath"
 
@@ -35,6 +36,23 @@ func InitCasbin() *casbin.Enforcer {
 		return nil
 	}
 
+	// 超级管理员角色拥有全部接口权限（keyMatch2 通配）
+	if _, err := enforcer.AddPolicy("admin", "/*", "*"); err != nil {
+		global.LV_LOG.Error("Casbin init admin policy failed", zap.Error(err))
+	}
+
 	global.LV_LOG.Info("Casbin init success")
 	return enforcer
 }
+
+// InitUserRoles 同步用户角色关联到 Casbin g 规则，需在 InitCasbin 之后调用
+func InitUserRoles() {
+	if global.LV_ENFORCER == nil {
+		return
+	}
+	if err := service.SyncAllUserRoles(); err != nil {
+		global.LV_LOG.Error("Casbin sync user roles failed", zap.Error(err))
+		return
+	}
+	global.LV_LOG.Info("Casbin sync user roles success")
+}
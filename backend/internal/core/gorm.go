@@ -25,6 +25,12 @@ func Gorm() *gorm.DB {
 	if db, err := gorm.Open(mysql.New(mysqlConfig), gormConfig()); err != nil {
 		return nil
 	} else {
+		if err := registerTenantCallbacks(db); err != nil {
+			return nil
+		}
+		if err := registerAuditCallbacks(db); err != nil {
+			return nil
+		}
 		sqlDB, _ := db.DB()
 		sqlDB.SetMaxIdleConns(10)
 		sqlDB.SetMaxOpenConns(100)
@@ -0,0 +1,64 @@
+package core
+
+import (
+	"go-lv-vue-admin/internal/global"
+	"go-lv-vue-admin/internal/model"
+	"regexp"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// handlerNameRe 匹配 gin 处理函数名，如 go-lv-vue-admin/internal/api/v1.(*SystemUserApi).GetUserList-fm
+var handlerNameRe = regexp.MustCompile(`\.\(\*(\w+)\)\.(\w+)-fm$`)
+
+// InitApis 根据已注册的 gin 路由初始化接口资源表（仅补充缺失的记录，不覆盖已编辑的描述）
+func InitApis(routes gin.RoutesInfo) {
+	db := global.LV_DB
+	if db == nil {
+		return
+	}
+
+	// 清理早期软删除留下的记录，避免与 path + method 唯一索引冲突
+	if err := db.Unscoped().Where("deleted_at IS NOT NULL").Delete(&model.LvApi{}).Error; err != nil {
+		global.LV_LOG.Error("purge deleted apis failed", zap.Error(err))
+	}
+
+	var created int
+	for _, route := range routes {
+		// 跳过静态文件和 HEAD 路由
+		if route.Method == "HEAD" || strings.Contains(route.Path, "*filepath") {
+			continue
+		}
+
+		var count int64
+		db.Model(&model.LvApi{}).Where("path = ? AND method = ?", route.Path, route.Method).Count(&count)
+		if count > 0 {
+			continue
+		}
+
+		apiGroup, description := parseRouteHandler(route)
+		api := model.LvApi{
+			Path:        route.Path,
+			Method:      route.Method,
+			ApiGroup:    apiGroup,
+			Description: description,
+		}
+		if err := db.Create(&api).Error; err != nil {
+			global.LV_LOG.Error("init api failed", zap.String("path", route.Path), zap.Error(err))
+			continue
+		}
+		created++
+	}
+	global.LV_LOG.Info("init apis success", zap.Int("created", created))
+}
+
+// parseRouteHandler 从处理函数名推断接口分组和描述，无法识别时按路径首段分组
+func parseRouteHandler(route gin.RouteInfo) (apiGroup, description string) {
+	if m := handlerNameRe.FindStringSubmatch(route.Handler); m != nil {
+		return strings.TrimSuffix(m[1], "Api"), m[2]
+	}
+	segments := strings.Split(strings.Trim(route.Path, "/"), "/")
+	return segments[0], route.Method + " " + route.Path
+}
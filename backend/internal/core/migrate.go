@@ -12,11 +12,24 @@ import (
 
 func RegisterTables() {
 	db := global.LV_DB
+	dropLegacyUniqueIndexes(db)
 	err := db.AutoMigrate(
+		&model.LvTenant{},
 		&model.LvUser{},
+		&model.LvUserSession{},
+		&model.LvPasswordHistory{},
+		&model.LvUserRecoveryCode{},
+		&model.LvAccessToken{},
 		&model.LvRole{},
+		&model.LvDept{},
 		&model.LvMenu{},
+		&model.LvApi{},
 		&model.LvOperationLog{},
+		&model.LvAuditLog{},
+		&model.LvLogArchive{},
+		&model.LvExportJob{},
+		&model.LvAlertRule{},
+		&model.LvAlert{},
 		&model.LvSetting{},
 		&model.LvDemo{},
 	)
@@ -27,13 +40,34 @@ func RegisterTables() {
 	global.LV_LOG.Info("register table success")
 	InitData(db)
 	InitSettings(db)
+	InitAlertRules(db)
 	InitDemoData(db)
 }
 
+// dropLegacyUniqueIndexes 角色关键字、设置键由全局唯一改为租户内唯一，迁移前删除旧的唯一索引
+func dropLegacyUniqueIndexes(db *gorm.DB) {
+	legacy := map[string][]string{
+		"lv_roles":    {"keyword", "uni_lv_roles_keyword", "idx_lv_roles_keyword"},
+		"lv_settings": {"key", "uni_lv_settings_key", "idx_lv_settings_key"},
+	}
+	for table, indexes := range legacy {
+		if !db.Migrator().HasTable(table) {
+			continue
+		}
+		for _, index := range indexes {
+			if db.Migrator().HasIndex(table, index) {
+				if err := db.Migrator().DropIndex(table, index); err != nil {
+					global.LV_LOG.Error("drop legacy index failed", zap.String("table", table), zap.String("index", index), zap.Error(err))
+				}
+			}
+		}
+	}
+}
+
 func InitData(db *gorm.DB) {
 	var adminRole model.LvRole
 	// Check if admin role exists
-	if err := db.Where("keyword = ?", "admin").First(&adminRole).Error; err != nil {
+	if err := db.Where("tenant_id = ? AND keyword = ?", 0, "admin").First(&adminRole).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			// Create Admin Role
 			adminRole = model.LvRole{
@@ -54,9 +88,18 @@ func InitData(db *gorm.DB) {
 		}
 	}
 
+	// 初始化根部门
+	var rootDept model.LvDept
+	if err := db.Where("tenant_id = ? AND parent_id = ?", 0, 0).Order("id ASC").First(&rootDept).Error; err == gorm.ErrRecordNotFound {
+		rootDept = model.LvDept{Name: "总公司", Sort: 1, Status: 1}
+		if err := db.Create(&rootDept).Error; err != nil {
+			global.LV_LOG.Error("init root dept failed", zap.Error(err))
+		}
+	}
+
 	var adminUser model.LvUser
 	// Check if admin user exists
-	if err := db.Where("username = ?", "admin").First(&adminUser).Error; err != nil {
+	if err := db.Where("tenant_id = ? AND username = ?", 0, "admin").First(&adminUser).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			// Create Admin User with bcrypt hashed password
 			hashedPassword, _ := utils.HashPassword("password")
@@ -65,6 +108,7 @@ func InitData(db *gorm.DB) {
 				Password: hashedPassword,
 				Nickname: "超级管理员",
 				RoleId:   adminRole.ID,
+				DeptId:   rootDept.ID,
 				Status:   1,
 			}
 			if err := db.Create(&adminUser).Error; err != nil {
@@ -81,6 +125,8 @@ func InitData(db *gorm.DB) {
 	if menuCount == 0 {
 		initMenus(db)
 	}
+	initPlatformMenus(db)
+	initAlertMenu(db)
 }
 
 func initMenus(db *gorm.DB) {
@@ -146,14 +192,106 @@ func initMenus(db *gorm.DB) {
 		Type:      2,
 	})
 
+	// 部门管理
+	db.Create(&model.LvMenu{
+		ParentId:  system.ID,
+		Title:     "部门管理",
+		Path:      "/system/dept",
+		Name:      "SystemDept",
+		Component: "views/system/dept/index",
+		Icon:      "GitNetworkOutline",
+		Sort:      4,
+		Type:      2,
+	})
+
 	global.LV_LOG.Info("init menus success")
 }
 
+// initPlatformMenus 初始化平台管理菜单（已有数据的系统升级后同样补齐），仅平台用户可见
+func initPlatformMenus(db *gorm.DB) {
+	var count int64
+	db.Model(&model.LvMenu{}).Where("path = ?", "/platform/tenant").Count(&count)
+	if count > 0 {
+		return
+	}
+
+	var platform model.LvMenu
+	if err := db.Where("path = ?", "/platform").First(&platform).Error; err == gorm.ErrRecordNotFound {
+		platform = model.LvMenu{
+			ParentId:  0,
+			Title:     "平台管理",
+			Path:      "/platform",
+			Name:      "Platform",
+			Component: "",
+			Icon:      "BusinessOutline",
+			Sort:      90,
+			Type:      1,
+		}
+		db.Create(&platform)
+	}
+
+	// 租户管理
+	db.Create(&model.LvMenu{
+		ParentId:  platform.ID,
+		Title:     "租户管理",
+		Path:      "/platform/tenant",
+		Name:      "PlatformTenant",
+		Component: "views/platform/tenant/index",
+		Icon:      "BusinessOutline",
+		Sort:      1,
+		Type:      2,
+	})
+
+	global.LV_LOG.Info("init platform menus success")
+}
+
+// initAlertMenu 初始化安全告警菜单（已有数据的系统升级后同样补齐），挂在平台管理下
+func initAlertMenu(db *gorm.DB) {
+	var count int64
+	db.Model(&model.LvMenu{}).Where("path = ?", "/platform/alert").Count(&count)
+	if count > 0 {
+		return
+	}
+
+	var platform model.LvMenu
+	if err := db.Where("path = ?", "/platform").First(&platform).Error; err != nil {
+		global.LV_LOG.Error("query platform menu failed", zap.Error(err))
+		return
+	}
+	db.Create(&model.LvMenu{
+		ParentId:  platform.ID,
+		Title:     "安全告警",
+		Path:      "/platform/alert",
+		Name:      "PlatformAlert",
+		Component: "views/platform/alert/index",
+		Icon:      "WarningOutline",
+		Sort:      2,
+		Type:      2,
+	})
+
+	global.LV_LOG.Info("init alert menu success")
+}
+
+// InitAlertRules 首次启动时初始化默认告警规则，之后由管理员维护
+func InitAlertRules(db *gorm.DB) {
+	var count int64
+	db.Unscoped().Model(&model.LvAlertRule{}).Count(&count)
+	if count > 0 {
+		return
+	}
+	rules := append([]model.LvAlertRule(nil), model.DefaultAlertRules...)
+	if err := db.Create(&rules).Error; err != nil {
+		global.LV_LOG.Error("init alert rules failed", zap.Error(err))
+		return
+	}
+	global.LV_LOG.Info("init alert rules success")
+}
+
 // InitSettings 初始化默认设置
 func InitSettings(db *gorm.DB) {
 	for _, setting := range model.DefaultSettings {
 		var count int64
-		db.Model(&model.LvSetting{}).Where("`key` = ?", setting.Key).Count(&count)
+		db.Model(&model.LvSetting{}).Where("tenant_id = ? AND `key` = ?", 0, setting.Key).Count(&count)
 		if count == 0 {
 			db.Create(&setting)
 		}
@@ -0,0 +1,94 @@
+package core
+
+import (
+	"go-lv-vue-admin/pkg/utils"
+	"reflect"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// registerTenantCallbacks 注册多租户回调：
+// 查询、更新、删除时按 context 中的租户追加 tenant_id 条件，创建时强制写入当前租户（忽略请求中提交的 TenantId）
+// 只作用于含 TenantId 字段的模型，context 中没有租户时不做处理
+func registerTenantCallbacks(db *gorm.DB) error {
+	callbacks := []error{
+		db.Callback().Query().Before("gorm:query").Register("tenant:query", tenantFilter),
+		db.Callback().Row().Before("gorm:row").Register("tenant:row", tenantFilter),
+		db.Callback().Update().Before("gorm:update").Register("tenant:update", tenantUpdate),
+		db.Callback().Delete().Before("gorm:delete").Register("tenant:delete", tenantFilter),
+		db.Callback().Create().Before("gorm:create").Register("tenant:create", tenantAssign),
+	}
+	for _, err := range callbacks {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// tenantFilter 追加 tenant_id 条件，同一 Statement 只追加一次（如先 Count 再 Find）
+func tenantFilter(db *gorm.DB) {
+	if db.Statement.Schema == nil {
+		return
+	}
+	field := db.Statement.Schema.LookUpField("TenantId")
+	if field == nil {
+		return
+	}
+	tenantId, ok := utils.TenantFromContext(db.Statement.Context)
+	if !ok {
+		return
+	}
+	if _, applied := db.Statement.Settings.LoadOrStore("tenant:applied", true); applied {
+		return
+	}
+	db.Statement.AddClause(clause.Where{Exprs: []clause.Expression{
+		clause.Eq{Column: clause.Column{Table: clause.CurrentTable, Name: field.DBName}, Value: tenantId},
+	}})
+}
+
+// tenantUpdate 更新时追加租户条件，以结构体更新时同时锁定 TenantId 不被修改
+func tenantUpdate(db *gorm.DB) {
+	tenantFilter(db)
+	if db.Statement.Schema == nil || db.Statement.Schema.LookUpField("TenantId") == nil {
+		return
+	}
+	tenantId, ok := utils.TenantFromContext(db.Statement.Context)
+	if !ok || db.Statement.Dest == nil {
+		return
+	}
+	if reflect.Indirect(reflect.ValueOf(db.Statement.Dest)).Kind() == reflect.Struct {
+		db.Statement.SetColumn("TenantId", tenantId, true)
+	}
+}
+
+// tenantAssign 创建时写入当前租户
+func tenantAssign(db *gorm.DB) {
+	if db.Statement.Schema == nil {
+		return
+	}
+	field := db.Statement.Schema.LookUpField("TenantId")
+	if field == nil {
+		return
+	}
+	tenantId, ok := utils.TenantFromContext(db.Statement.Context)
+	if !ok {
+		return
+	}
+
+	ctx := db.Statement.Context
+	assign := func(rv reflect.Value) {
+		if err := field.Set(ctx, rv, tenantId); err != nil {
+			db.AddError(err)
+		}
+	}
+	switch db.Statement.ReflectValue.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < db.Statement.ReflectValue.Len(); i++ {
+			assign(reflect.Indirect(db.Statement.ReflectValue.Index(i)))
+		}
+	case reflect.Struct:
+		assign(db.Statement.ReflectValue)
+	}
+}
@@ -0,0 +1,199 @@
+package logsink
+
+import (
+	"context"
+	"errors"
+	"go-lv-vue-admin/internal/model"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// ErrClosed 日志写入器已关闭
+var ErrClosed = errors.New("log sink closed")
+
+// BatchOptions 批量写入参数
+type BatchOptions struct {
+	QueueSize     int           // 队列容量
+	BatchSize     int           // 每批最多写入条数
+	FlushInterval time.Duration // 未满一批时的最长等待时间
+	BlockTimeout  time.Duration // 队列满时最长等待时间，0 表示直接丢弃
+}
+
+// BatchWriter 批量写入日志的存储，BatchSink 每攒够一批调用一次
+type BatchWriter interface {
+	WriteBatch(logs []*model.LvOperationLog) error
+}
+
+// DBWriter 写入数据库的 BatchWriter
+type DBWriter struct {
+	db *gorm.DB
+}
+
+// NewDBWriter 创建数据库写入器
+func NewDBWriter(db *gorm.DB) *DBWriter {
+	return &DBWriter{db: db}
+}
+
+// WriteBatch 一条 INSERT 写入整批日志
+func (w *DBWriter) WriteBatch(logs []*model.LvOperationLog) error {
+	return w.db.CreateInBatches(logs, len(logs)).Error
+}
+
+// BatchSink 有界队列 + 后台协程批量写入
+// 满 BatchSize 条或距上次写入超过 FlushInterval 时交给 BatchWriter 写入一批
+type BatchSink struct {
+	writer BatchWriter
+	logger *zap.Logger
+	opts   BatchOptions
+
+	queue   chan *model.LvOperationLog
+	done    chan struct{}
+	closeMu sync.RWMutex
+	closed  bool
+
+	accepted   atomic.Int64
+	written    atomic.Int64
+	dropped    atomic.Int64
+	failed     atomic.Int64
+	batchCount atomic.Int64
+	// reportedDrops 已告警过的丢弃条数，避免每次丢弃都打印日志
+	reportedDrops atomic.Int64
+}
+
+// NewBatchSink 创建并启动批量写入器
+func NewBatchSink(writer BatchWriter, logger *zap.Logger, opts BatchOptions) *BatchSink {
+	if opts.QueueSize <= 0 {
+		opts.QueueSize = 10000
+	}
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = 100
+	}
+	if opts.FlushInterval <= 0 {
+		opts.FlushInterval = 2 * time.Second
+	}
+	s := &BatchSink{
+		writer: writer,
+		logger: logger,
+		opts:   opts,
+		queue:  make(chan *model.LvOperationLog, opts.QueueSize),
+		done:   make(chan struct{}),
+	}
+	go s.run()
+	return s
+}
+
+func (s *BatchSink) Write(log *model.LvOperationLog) bool {
+	// 读锁保证 Close 关闭队列后不会再有写入
+	s.closeMu.RLock()
+	defer s.closeMu.RUnlock()
+	if s.closed {
+		s.dropped.Add(1)
+		return false
+	}
+
+	select {
+	case s.queue <- log:
+		s.accepted.Add(1)
+		return true
+	default:
+	}
+
+	// 队列已满：短暂等待以对请求施加背压，超时则丢弃
+	if s.opts.BlockTimeout > 0 {
+		timer := time.NewTimer(s.opts.BlockTimeout)
+		defer timer.Stop()
+		select {
+		case s.queue <- log:
+			s.accepted.Add(1)
+			return true
+		case <-timer.C:
+		}
+	}
+	s.dropped.Add(1)
+	return false
+}
+
+func (s *BatchSink) Close(ctx context.Context) error {
+	s.closeMu.Lock()
+	if s.closed {
+		s.closeMu.Unlock()
+		return ErrClosed
+	}
+	s.closed = true
+	close(s.queue)
+	s.closeMu.Unlock()
+
+	select {
+	case <-s.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (s *BatchSink) Stats() Stats {
+	return Stats{
+		Accepted:   s.accepted.Load(),
+		Written:    s.written.Load(),
+		Dropped:    s.dropped.Load(),
+		Failed:     s.failed.Load(),
+		QueueLen:   len(s.queue),
+		QueueCap:   cap(s.queue),
+		BatchCount: s.batchCount.Load(),
+	}
+}
+
+// run 后台写入协程，队列关闭后写完剩余日志退出
+func (s *BatchSink) run() {
+	defer close(s.done)
+
+	ticker := time.NewTicker(s.opts.FlushInterval)
+	defer ticker.Stop()
+
+	batch := make([]*model.LvOperationLog, 0, s.opts.BatchSize)
+	for {
+		select {
+		case log, ok := <-s.queue:
+			if !ok {
+				s.flush(batch)
+				return
+			}
+			batch = append(batch, log)
+			if len(batch) >= s.opts.BatchSize {
+				s.flush(batch)
+				batch = batch[:0]
+			}
+		case <-ticker.C:
+			s.flush(batch)
+			batch = batch[:0]
+			s.reportDrops()
+		}
+	}
+}
+
+// flush 写入一批日志，失败时只记录错误（不重试，避免阻塞后续日志）
+func (s *BatchSink) flush(batch []*model.LvOperationLog) {
+	if len(batch) == 0 {
+		return
+	}
+	if err := s.writer.WriteBatch(batch); err != nil {
+		s.failed.Add(int64(len(batch)))
+		s.logger.Error("操作日志批量写入失败", zap.Int("count", len(batch)), zap.Error(err))
+		return
+	}
+	s.written.Add(int64(len(batch)))
+	s.batchCount.Add(1)
+}
+
+// reportDrops 自上次告警以来有新的丢弃时打印一次告警
+func (s *BatchSink) reportDrops() {
+	dropped := s.dropped.Load()
+	if reported := s.reportedDrops.Swap(dropped); dropped > reported {
+		s.logger.Warn("操作日志队列已满，部分日志被丢弃",
+			zap.Int64("dropped", dropped-reported), zap.Int64("totalDropped", dropped), zap.Int("queueCap", cap(s.queue)))
+	}
+}
@@ -0,0 +1,233 @@
+package logsink
+
+import (
+	"context"
+	"errors"
+	"go-lv-vue-admin/internal/model"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// gatedWriter 在 release 关闭前阻塞写入，用于模拟数据库变慢、队列被占满
+type gatedWriter struct {
+	*MemorySink
+	entered chan struct{}
+	release chan struct{}
+}
+
+func newGatedWriter() *gatedWriter {
+	return &gatedWriter{
+		MemorySink: NewMemorySink(),
+		entered:    make(chan struct{}, 16),
+		release:    make(chan struct{}),
+	}
+}
+
+func (w *gatedWriter) WriteBatch(logs []*model.LvOperationLog) error {
+	w.entered <- struct{}{}
+	<-w.release
+	return w.MemorySink.WriteBatch(logs)
+}
+
+// waitEntered 等待后台协程取走一批并阻塞在写入中
+func (w *gatedWriter) waitEntered(t *testing.T) {
+	t.Helper()
+	select {
+	case <-w.entered:
+	case <-time.After(time.Second):
+		t.Fatal("后台协程未开始写入")
+	}
+}
+
+type failingWriter struct{}
+
+func (failingWriter) WriteBatch([]*model.LvOperationLog) error {
+	return errors.New("db down")
+}
+
+func testLog(i int) *model.LvOperationLog {
+	return &model.LvOperationLog{Path: "/test/" + strconv.Itoa(i), Method: "POST"}
+}
+
+// waitFor 轮询直到 cond 成立，超时失败
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("等待超时")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func closeSink(t *testing.T, s Sink) {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := s.Close(ctx); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+}
+
+func TestBatchSinkFlushesFullBatches(t *testing.T) {
+	mem := NewMemorySink()
+	s := NewBatchSink(mem, zap.NewNop(), BatchOptions{QueueSize: 100, BatchSize: 3, FlushInterval: time.Hour})
+
+	for i := 0; i < 7; i++ {
+		if !s.Write(testLog(i)) {
+			t.Fatalf("Write %d rejected", i)
+		}
+	}
+	// 满批立即写入，不等 FlushInterval
+	waitFor(t, func() bool { return len(mem.Logs()) == 6 })
+	if got := mem.Batches(); !reflect.DeepEqual(got, []int{3, 3}) {
+		t.Fatalf("batches = %v, want [3 3]", got)
+	}
+
+	closeSink(t, s)
+	if got := mem.Batches(); !reflect.DeepEqual(got, []int{3, 3, 1}) {
+		t.Fatalf("batches after close = %v, want [3 3 1]", got)
+	}
+	logs := mem.Logs()
+	for i, log := range logs {
+		if log.Path != "/test/"+strconv.Itoa(i) {
+			t.Fatalf("logs[%d].Path = %q, want order preserved", i, log.Path)
+		}
+	}
+
+	stats := s.Stats()
+	if stats.Accepted != 7 || stats.Written != 7 || stats.BatchCount != 3 || stats.Dropped != 0 {
+		t.Fatalf("stats = %+v", stats)
+	}
+}
+
+func TestBatchSinkFlushInterval(t *testing.T) {
+	mem := NewMemorySink()
+	s := NewBatchSink(mem, zap.NewNop(), BatchOptions{QueueSize: 100, BatchSize: 100, FlushInterval: 20 * time.Millisecond})
+	defer closeSink(t, s)
+
+	s.Write(testLog(0))
+	s.Write(testLog(1))
+	// 未满一批，到 FlushInterval 后写入
+	waitFor(t, func() bool { return len(mem.Logs()) == 2 })
+	if got := mem.Batches(); !reflect.DeepEqual(got, []int{2}) {
+		t.Fatalf("batches = %v, want [2]", got)
+	}
+}
+
+func TestBatchSinkDropsWhenQueueFull(t *testing.T) {
+	w := newGatedWriter()
+	s := NewBatchSink(w, zap.NewNop(), BatchOptions{QueueSize: 1, BatchSize: 1, FlushInterval: time.Hour})
+
+	s.Write(testLog(0))
+	w.waitEntered(t)
+	if !s.Write(testLog(1)) {
+		t.Fatal("queue has room, Write should succeed")
+	}
+
+	// BlockTimeout 为 0 时队列满立即丢弃
+	start := time.Now()
+	if s.Write(testLog(2)) {
+		t.Fatal("queue full, Write should be dropped")
+	}
+	if elapsed := time.Since(start); elapsed > 50*time.Millisecond {
+		t.Fatalf("Write blocked %v without block_timeout", elapsed)
+	}
+	if stats := s.Stats(); stats.Dropped != 1 || stats.Accepted != 2 || stats.QueueLen != 1 {
+		t.Fatalf("stats = %+v", stats)
+	}
+
+	close(w.release)
+	closeSink(t, s)
+	if got := len(w.Logs()); got != 2 {
+		t.Fatalf("written = %d, want 2", got)
+	}
+}
+
+func TestBatchSinkBlockTimeout(t *testing.T) {
+	w := newGatedWriter()
+	s := NewBatchSink(w, zap.NewNop(), BatchOptions{QueueSize: 1, BatchSize: 1, FlushInterval: time.Hour, BlockTimeout: 50 * time.Millisecond})
+
+	s.Write(testLog(0))
+	w.waitEntered(t)
+	s.Write(testLog(1))
+
+	// 队列一直满：等待 BlockTimeout 后丢弃
+	start := time.Now()
+	if s.Write(testLog(2)) {
+		t.Fatal("queue full, Write should be dropped after block_timeout")
+	}
+	if elapsed := time.Since(start); elapsed < 50*time.Millisecond {
+		t.Fatalf("Write returned after %v, want to wait block_timeout", elapsed)
+	}
+
+	// 等待期间队列腾出空间：写入成功
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		close(w.release)
+	}()
+	if !s.Write(testLog(3)) {
+		t.Fatal("queue drained within block_timeout, Write should succeed")
+	}
+
+	closeSink(t, s)
+	if stats := s.Stats(); stats.Dropped != 1 || stats.Written != 3 {
+		t.Fatalf("stats = %+v", stats)
+	}
+}
+
+func TestBatchSinkCloseDrains(t *testing.T) {
+	mem := NewMemorySink()
+	s := NewBatchSink(mem, zap.NewNop(), BatchOptions{QueueSize: 100, BatchSize: 100, FlushInterval: time.Hour})
+
+	for i := 0; i < 5; i++ {
+		s.Write(testLog(i))
+	}
+	closeSink(t, s)
+	if got := len(mem.Logs()); got != 5 {
+		t.Fatalf("written after close = %d, want 5", got)
+	}
+
+	if s.Write(testLog(5)) {
+		t.Fatal("Write after Close should be rejected")
+	}
+	if err := s.Close(context.Background()); !errors.Is(err, ErrClosed) {
+		t.Fatalf("second Close = %v, want ErrClosed", err)
+	}
+	if stats := s.Stats(); stats.Dropped != 1 {
+		t.Fatalf("stats = %+v", stats)
+	}
+}
+
+func TestBatchSinkCloseTimeout(t *testing.T) {
+	w := newGatedWriter()
+	s := NewBatchSink(w, zap.NewNop(), BatchOptions{QueueSize: 10, BatchSize: 1, FlushInterval: time.Hour})
+	defer close(w.release)
+
+	s.Write(testLog(0))
+	w.waitEntered(t)
+
+	// 写入卡住时 ctx 到期放弃等待
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := s.Close(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Close = %v, want DeadlineExceeded", err)
+	}
+}
+
+func TestBatchSinkCountsFailedBatches(t *testing.T) {
+	s := NewBatchSink(failingWriter{}, zap.NewNop(), BatchOptions{QueueSize: 10, BatchSize: 2, FlushInterval: time.Hour})
+
+	for i := 0; i < 3; i++ {
+		s.Write(testLog(i))
+	}
+	closeSink(t, s)
+	if stats := s.Stats(); stats.Failed != 3 || stats.Written != 0 || stats.BatchCount != 0 {
+		t.Fatalf("stats = %+v", stats)
+	}
+}
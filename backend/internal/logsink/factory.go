@@ -0,0 +1,69 @@
+package logsink
+
+import (
+	"context"
+	"go-lv-vue-admin/internal/global"
+	"go-lv-vue-admin/pkg/utils"
+	"sync"
+)
+
+var (
+	sink   Sink
+	sinkMu sync.RWMutex
+)
+
+// InitSink 按配置创建操作日志写入器
+func InitSink() {
+	SetSink(newSink())
+}
+
+// newSink 未连接数据库时使用内存写入器
+func newSink() Sink {
+	if global.LV_DB == nil {
+		return NewMemorySink()
+	}
+	cfg := global.LV_CONFIG.OperationLog
+	flushInterval, _ := utils.ParseDuration(cfg.FlushInterval)
+	blockTimeout, _ := utils.ParseDuration(cfg.BlockTimeout)
+	return NewBatchSink(NewDBWriter(global.LV_DB), global.LV_LOG, BatchOptions{
+		QueueSize:     cfg.QueueSize,
+		BatchSize:     cfg.BatchSize,
+		FlushInterval: flushInterval,
+		BlockTimeout:  blockTimeout,
+	})
+}
+
+// SetSink 替换操作日志写入器（测试时可替换为 MemorySink）
+func SetSink(s Sink) {
+	sinkMu.Lock()
+	defer sinkMu.Unlock()
+	sink = s
+}
+
+// GetSink 获取操作日志写入器，未初始化时按配置创建
+func GetSink() Sink {
+	sinkMu.RLock()
+	s := sink
+	sinkMu.RUnlock()
+	if s != nil {
+		return s
+	}
+
+	sinkMu.Lock()
+	defer sinkMu.Unlock()
+	if sink == nil {
+		sink = newSink()
+	}
+	return sink
+}
+
+// Close 关闭写入器并写入缓冲中的日志，用于优雅退出
+func Close(ctx context.Context) error {
+	sinkMu.RLock()
+	s := sink
+	sinkMu.RUnlock()
+	if s == nil {
+		return nil
+	}
+	return s.Close(ctx)
+}
@@ -0,0 +1,70 @@
+package logsink
+
+import (
+	"context"
+	"go-lv-vue-admin/internal/model"
+	"sync"
+)
+
+// MemorySink 内存写入器，日志保存在内存中，用于测试或未连接数据库时
+// 同时实现 BatchWriter，可作为 BatchSink 的存储验证批量写入
+type MemorySink struct {
+	mu      sync.Mutex
+	logs    []model.LvOperationLog
+	batches []int
+	closed  bool
+}
+
+// NewMemorySink 创建内存写入器
+func NewMemorySink() *MemorySink {
+	return &MemorySink{}
+}
+
+func (s *MemorySink) Write(log *model.LvOperationLog) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return false
+	}
+	s.logs = append(s.logs, *log)
+	return true
+}
+
+// WriteBatch 追加一批日志并记录批大小
+func (s *MemorySink) WriteBatch(logs []*model.LvOperationLog) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, log := range logs {
+		s.logs = append(s.logs, *log)
+	}
+	s.batches = append(s.batches, len(logs))
+	return nil
+}
+
+func (s *MemorySink) Close(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closed = true
+	return nil
+}
+
+func (s *MemorySink) Stats() Stats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	n := int64(len(s.logs))
+	return Stats{Accepted: n, Written: n}
+}
+
+// Logs 已写入的日志副本
+func (s *MemorySink) Logs() []model.LvOperationLog {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]model.LvOperationLog(nil), s.logs...)
+}
+
+// Batches 通过 WriteBatch 写入的每批条数
+func (s *MemorySink) Batches() []int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]int(nil), s.batches...)
+}
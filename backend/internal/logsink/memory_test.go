@@ -0,0 +1,66 @@
+package logsink
+
+import (
+	"context"
+	"go-lv-vue-admin/internal/model"
+	"testing"
+	"time"
+)
+
+func TestMemorySink(t *testing.T) {
+	s := NewMemorySink()
+
+	log := testLog(0)
+	if !s.Write(log) {
+		t.Fatal("Write rejected")
+	}
+	// 保存副本，调用方之后修改日志不影响已写入的内容
+	log.Path = "/changed"
+	if logs := s.Logs(); len(logs) != 1 || logs[0].Path != "/test/0" {
+		t.Fatalf("logs = %+v", logs)
+	}
+
+	if err := s.Close(context.Background()); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	if s.Write(testLog(1)) {
+		t.Fatal("Write after Close should be rejected")
+	}
+	if stats := s.Stats(); stats.Accepted != 1 || stats.Written != 1 {
+		t.Fatalf("stats = %+v", stats)
+	}
+}
+
+func TestWriteNotifiesObserversAndSink(t *testing.T) {
+	mem := NewMemorySink()
+	SetSink(mem)
+	defer SetSink(nil)
+
+	var observed []model.LvOperationLog
+	AddObserver(func(log model.LvOperationLog) {
+		observed = append(observed, log)
+	})
+	defer func() {
+		observersMu.Lock()
+		observers = nil
+		observersMu.Unlock()
+	}()
+
+	before := time.Now()
+	log := testLog(0)
+	if !Write(log) {
+		t.Fatal("Write rejected")
+	}
+	// 观察者收到的是副本
+	log.Path = "/changed"
+	if len(observed) != 1 || observed[0].Path != "/test/0" {
+		t.Fatalf("observed = %+v", observed)
+	}
+	// 日志时间为提交时刻，观察者和写入队列看到的一致
+	if log.CreatedAt.Before(before) || !observed[0].CreatedAt.Equal(log.CreatedAt) {
+		t.Fatalf("CreatedAt = %v, observed %v", log.CreatedAt, observed[0].CreatedAt)
+	}
+	if logs := mem.Logs(); len(logs) != 1 {
+		t.Fatalf("sink logs = %+v", logs)
+	}
+}
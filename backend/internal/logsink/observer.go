@@ -0,0 +1,35 @@
+package logsink
+
+import (
+	"go-lv-vue-admin/internal/model"
+	"sync"
+	"time"
+)
+
+// Observer 在日志进入写入队列前接收日志副本（如安全告警规则），须立即返回，不能阻塞请求
+type Observer func(log model.LvOperationLog)
+
+var (
+	observers   []Observer
+	observersMu sync.RWMutex
+)
+
+// AddObserver 注册日志观察者
+func AddObserver(o Observer) {
+	observersMu.Lock()
+	defer observersMu.Unlock()
+	observers = append(observers, o)
+}
+
+// Write 通知观察者后提交到写入队列，所有操作日志（中间件和登录失败等安全事件）都经过这里
+// 日志时间取提交时刻，而不是批量写入数据库的时刻
+func Write(log *model.LvOperationLog) bool {
+	log.CreatedAt = time.Now()
+	observersMu.RLock()
+	for _, o := range observers {
+		// 传值：写入队列后 log 会被后台协程修改（ID）
+		o(*log)
+	}
+	observersMu.RUnlock()
+	return GetSink().Write(log)
+}
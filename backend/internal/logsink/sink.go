@@ -0,0 +1,29 @@
+package logsink
+
+import (
+	"context"
+	"go-lv-vue-admin/internal/model"
+)
+
+// Sink 操作日志写入接口，请求处理中调用 Write，不应阻塞请求
+type Sink interface {
+	// Write 提交一条日志，队列已满且等待超时时丢弃并返回 false
+	Write(log *model.LvOperationLog) bool
+
+	// Close 停止接收新日志并写入缓冲中的全部日志，ctx 到期时放弃剩余日志
+	Close(ctx context.Context) error
+
+	// Stats 写入统计
+	Stats() Stats
+}
+
+// Stats 写入统计，Dropped 为队列满被丢弃的条数，Failed 为写入数据库失败的条数
+type Stats struct {
+	Accepted   int64 `json:"accepted"`
+	Written    int64 `json:"written"`
+	Dropped    int64 `json:"dropped"`
+	Failed     int64 `json:"failed"`
+	QueueLen   int   `json:"queueLen"`
+	QueueCap   int   `json:"queueCap"`
+	BatchCount int64 `json:"batchCount"`
+}
@@ -0,0 +1,87 @@
+package middleware
+
+import (
+	"go-lv-vue-admin/internal/global"
+	"go-lv-vue-admin/internal/model"
+	"go-lv-vue-admin/internal/service"
+	"go-lv-vue-admin/pkg/utils"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// CasbinAuth Casbin 接口鉴权中间件，需放在 JWTAuth 之后使用
+func CasbinAuth() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		path := c.Request.URL.Path
+		method := c.Request.Method
+
+		if global.LV_ENFORCER == nil {
+			global.LV_LOG.Error("Casbin 未初始化，拒绝访问", zap.String("path", path))
+			denyAccess(c, "", path, method)
+			return
+		}
+
+		// 指定了生效角色时按该角色鉴权，否则按用户主体（g 规则关联全部有效角色）鉴权
+		claims := utils.GetClaims(c)
+		roles, err := service.EffectiveRoles(claims.UserId, claims.ActiveRoleId)
+		if err != nil || len(roles) == 0 {
+			denyAccess(c, "", path, method)
+			return
+		}
+		keywords := make([]string, len(roles))
+		for i, role := range roles {
+			keywords[i] = role.Keyword
+		}
+		roleKeyword := strings.Join(keywords, ",")
+
+		subject := service.UserSubject(claims.UserId)
+		if claims.ActiveRoleId != 0 {
+			subject = service.RoleSubject(roles[0])
+		}
+		ok, err := global.LV_ENFORCER.Enforce(subject, path, method)
+		if err != nil {
+			global.LV_LOG.Error("Casbin 鉴权失败", zap.Error(err))
+		}
+		if !ok {
+			denyAccess(c, roleKeyword, path, method)
+			return
+		}
+
+		// 访问令牌限定了接口子集时，额外校验
+		if token, exists := c.Get("accessToken"); exists && !service.TokenAllows(token.(*model.LvAccessToken), path, method) {
+			denyAccess(c, roleKeyword, path, method)
+			return
+		}
+
+		c.Set("roleKeyword", roleKeyword)
+		c.Next()
+	}
+}
+
+// PlatformAuth 平台接口仅平台用户（租户 0）可访问，租户管理员即使拥有 /* 策略也不能访问
+func PlatformAuth() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if utils.GetClaims(c).TenantId != 0 {
+			denyAccess(c, c.GetString("roleKeyword"), c.Request.URL.Path, c.Request.Method)
+			return
+		}
+		c.Next()
+	}
+}
+
+// denyAccess 返回 403，并标记给操作日志中间件记录
+func denyAccess(c *gin.Context, role, path, method string) {
+	c.Set("permissionDenied", true)
+	c.JSON(403, gin.H{
+		"code": 403,
+		"data": gin.H{
+			"role":   role,
+			"path":   path,
+			"method": method,
+		},
+		"msg": "权限不足，禁止访问",
+	})
+	c.Abort()
+}
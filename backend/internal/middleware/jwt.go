@@ -2,12 +2,44 @@ package middleware
 
 import (
 	"go-lv-vue-admin/internal/global"
+	"go-lv-vue-admin/internal/service"
 	"go-lv-vue-admin/pkg/utils"
 	"strings"
 
 	"github.com/gin-gonic/gin"
 )
 
+var (
+	sessionService     = service.UserSessionService{}
+	accessTokenService = service.AccessTokenService{}
+)
+
+// passwordExpiredAllowedPaths 密码过期时仍可访问的接口
+var passwordExpiredAllowedPaths = map[string]bool{
+	"/profile":          true,
+	"/profile/password": true,
+	"/base/logout":      true,
+}
+
+// mfaSetupAllowedPaths 角色要求两步验证但尚未绑定时仍可访问的接口（含修改密码，避免与密码过期互相阻塞）
+var mfaSetupAllowedPaths = map[string]bool{
+	"/profile":            true,
+	"/profile/password":   true,
+	"/profile/2fa":        true,
+	"/profile/2fa/setup":  true,
+	"/profile/2fa/enable": true,
+	"/base/logout":        true,
+}
+
+// impersonationDeniedPaths 模拟登录时不可访问的接口前缀（涉及被模拟用户的凭据和会话）
+var impersonationDeniedPaths = []string{
+	"/profile/password",
+	"/profile/2fa",
+	"/profile/tokens",
+	"/profile/sessions",
+	"/base/logout/all",
+}
+
 // JWTAuth JWT 认证中间件
 func JWTAuth() gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -27,6 +59,12 @@ func JWTAuth() gin.HandlerFunc {
 			return
 		}
 
+		// 个人访问令牌
+		if service.IsAccessToken(parts[1]) {
+			accessTokenAuth(c, parts[1])
+			return
+		}
+
 		// 解析 token
 		claims, err := utils.ParseToken(parts[1])
 		if err != nil {
@@ -36,12 +74,123 @@ func JWTAuth() gin.HandlerFunc {
 			return
 		}
 
+		// 两步验证待完成的 Token 只能用于 /base/login/2fa
+		if claims.MfaPending {
+			c.JSON(401, gin.H{"code": 401, "msg": "请先完成两步验证"})
+			c.Abort()
+			return
+		}
+
+		// 校验会话是否已被吊销（退出登录、强制下线等）
+		if !sessionService.IsSessionActive(claims.ID) {
+			c.JSON(401, gin.H{"code": 401, "msg": "登录已失效，请重新登录"})
+			c.Abort()
+			return
+		}
+
+		sessionService.TouchSession(claims.ID, c.ClientIP())
+
+		// 密码已过期时只允许修改密码
+		if claims.PasswordExpired && !passwordExpiredAllowedPaths[c.Request.URL.Path] {
+			c.JSON(403, gin.H{"code": 403, "data": gin.H{"mustChangePassword": true}, "msg": "密码已过期，请先修改密码"})
+			c.Abort()
+			return
+		}
+
+		// 角色要求两步验证时，未绑定前只允许绑定
+		if claims.MfaSetupRequired && !mfaSetupAllowedPaths[c.Request.URL.Path] {
+			c.JSON(403, gin.H{"code": 403, "data": gin.H{"mustSetupMfa": true}, "msg": "当前角色要求启用两步验证，请先完成绑定"})
+			c.Abort()
+			return
+		}
+
+		// 模拟登录时不能修改被模拟用户的密码、两步验证等
+		if claims.ImpersonatorId != 0 {
+			for _, prefix := range impersonationDeniedPaths {
+				if strings.HasPrefix(c.Request.URL.Path, prefix) {
+					c.JSON(403, gin.H{"code": 403, "data": gin.H{"impersonating": true}, "msg": "模拟登录时不能进行该操作"})
+					c.Abort()
+					return
+				}
+			}
+		}
+
 		// 将用户信息存入 context
 		c.Set("userId", claims.UserId)
 		c.Set("username", claims.Username)
 		c.Set("roleId", claims.RoleId)
+		c.Set("tenantId", claims.TenantId)
+		c.Set("impersonatorId", claims.ImpersonatorId)
+		c.Set("impersonatorName", claims.ImpersonatorName)
 		c.Set("claims", claims)
+		setRequestContext(c, claims)
 
 		c.Next()
 	}
 }
+
+// setRequestContext 将操作人写入请求 context，多租户模式下同时写入租户
+// 携带该 context 的查询由 GORM 回调按租户过滤，数据变更由审计回调记录操作人
+func setRequestContext(c *gin.Context, claims *utils.CustomClaims) {
+	ctx := utils.WithOperator(c.Request.Context(), utils.Operator{
+		UserId:           claims.UserId,
+		Username:         claims.Username,
+		ImpersonatorId:   claims.ImpersonatorId,
+		ImpersonatorName: claims.ImpersonatorName,
+		Ip:               c.ClientIP(),
+	})
+	if global.LV_CONFIG.Tenant.Enable {
+		ctx = utils.WithTenant(ctx, claims.TenantId)
+	}
+	c.Request = c.Request.WithContext(ctx)
+}
+
+// accessTokenDeniedPaths 访问令牌不可访问的接口前缀（令牌管理、密码、两步验证及会话需交互式登录）
+var accessTokenDeniedPaths = []string{
+	"/profile/tokens",
+	"/profile/password",
+	"/profile/2fa",
+	"/profile/sessions",
+	"/user/active-role",
+	"/base/logout",
+}
+
+// accessTokenAuth 个人访问令牌认证，令牌不关联会话，Claims 中 ID 为空
+func accessTokenAuth(c *gin.Context, raw string) {
+	token, user, err := accessTokenService.Authenticate(raw)
+	if err != nil {
+		c.JSON(401, gin.H{"code": 401, "msg": err.Error()})
+		c.Abort()
+		return
+	}
+
+	path := c.Request.URL.Path
+	for _, prefix := range accessTokenDeniedPaths {
+		if strings.HasPrefix(path, prefix) {
+			c.JSON(403, gin.H{"code": 403, "msg": "访问令牌不能用于该操作"})
+			c.Abort()
+			return
+		}
+	}
+
+	accessTokenService.TouchToken(token.ID, c.ClientIP())
+
+	claims := &utils.CustomClaims{
+		BaseClaims: utils.BaseClaims{
+			TenantId: user.TenantId,
+			UserId:   user.ID,
+			Username: user.Username,
+			RoleId:   user.RoleId,
+		},
+	}
+	c.Set("userId", claims.UserId)
+	c.Set("username", claims.Username)
+	c.Set("roleId", claims.RoleId)
+	c.Set("tenantId", claims.TenantId)
+	c.Set("claims", claims)
+	setRequestContext(c, claims)
+	c.Set("accessToken", token)
+	c.Set("accessTokenName", token.Name)
+
+	c.Next()
+}
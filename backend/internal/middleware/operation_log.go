@@ -3,7 +3,9 @@ package middleware
 import (
 	"bytes"
 	"go-lv-vue-admin/internal/global"
+	"go-lv-vue-admin/internal/logsink"
 	"go-lv-vue-admin/internal/model"
+	"go-lv-vue-admin/internal/service"
 	"io"
 	"strings"
 	"time"
@@ -11,14 +13,22 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
-// 自定义 ResponseWriter 用于捕获响应
+// logContentLimit 日志中请求参数和响应内容的最大长度
+const logContentLimit = 2000
+
+// logCaptureLimit 响应内容的最大缓存长度，先按完整内容脱敏再截断到 logContentLimit，避免敏感字段跨截断点时残留部分取值
+const logCaptureLimit = 64 << 10
+
+// 自定义 ResponseWriter 用于捕获响应，只缓存 logCaptureLimit 以内的内容（导出等大响应不整体驻留内存）
 type responseBodyWriter struct {
 	gin.ResponseWriter
 	body *bytes.Buffer
 }
 
 func (r responseBodyWriter) Write(b []byte) (int, error) {
-	r.body.Write(b)
+	if remaining := logCaptureLimit - r.body.Len(); remaining > 0 {
+		r.body.Write(b[:min(len(b), remaining)])
+	}
 	return r.ResponseWriter.Write(b)
 }
 
@@ -35,9 +45,10 @@ func OperationLog() gin.HandlerFunc {
 		// 记录开始时间
 		start := time.Now()
 
-		// 获取请求体
+		// 获取请求体，文件上传默认不记录
 		var body []byte
-		if c.Request.Body != nil {
+		skipBody := strings.HasPrefix(c.ContentType(), "multipart/") && !global.LV_CONFIG.OperationLog.CaptureMultipart
+		if c.Request.Body != nil && !skipBody {
 			body, _ = io.ReadAll(c.Request.Body)
 			c.Request.Body = io.NopCloser(bytes.NewBuffer(body))
 		}
@@ -55,91 +66,59 @@ func OperationLog() gin.HandlerFunc {
 		// 获取用户信息
 		userId, _ := c.Get("userId")
 		username, _ := c.Get("username")
+		tenantId, _ := c.Get("tenantId")
+		impersonatorId, _ := c.Get("impersonatorId")
+		impersonatorName, _ := c.Get("impersonatorName")
+		accessTokenName := c.GetString("accessTokenName")
+
+		// 模块和操作类型取自路由登记的元数据
+		module, action := routeModuleAction(c.Request.Method, c.FullPath())
+		if c.GetBool("permissionDenied") {
+			action = "越权访问"
+		}
 
-		// 解析模块和操作类型
-		module, action := parseModuleAction(c.Request.Method, path)
-
-		// 限制 body 和 response 长度
-		bodyStr := string(body)
-		if len(bodyStr) > 2000 {
-			bodyStr = bodyStr[:2000] + "..."
+		// 先脱敏再限制 body 和 response 长度（截断后的内容可能无法完整识别字段）
+		bodyStr := service.RedactLogContent(path, string(body))
+		if skipBody {
+			bodyStr = "[文件上传，未记录请求体]"
+		}
+		if len(bodyStr) > logContentLimit {
+			bodyStr = bodyStr[:logContentLimit] + "..."
 		}
-		respStr := blw.body.String()
-		if len(respStr) > 2000 {
-			respStr = respStr[:2000] + "..."
+		respStr := service.RedactLogContent(path, blw.body.String())
+		if strings.HasPrefix(c.Writer.Header().Get("Content-Disposition"), "attachment") {
+			respStr = "[文件下载，未记录响应内容]"
+		}
+		if len(respStr) > logContentLimit {
+			respStr = respStr[:logContentLimit] + "..."
 		}
 
 		// 创建日志记录
 		log := model.LvOperationLog{
-			UserId:    toUint(userId),
-			Username:  toString(username),
-			Ip:        c.ClientIP(),
-			Method:    c.Request.Method,
-			Path:      path,
-			Status:    c.Writer.Status(),
-			Latency:   latency,
-			UserAgent: c.Request.UserAgent(),
-			Body:      bodyStr,
-			Response:  respStr,
-			Module:    module,
-			Action:    action,
+			TenantId:    toUint(tenantId),
+			UserId:      toUint(userId),
+			Username:    toString(username),
+			Ip:          c.ClientIP(),
+			Method:      c.Request.Method,
+			Path:        path,
+			Status:      c.Writer.Status(),
+			Latency:     latency,
+			UserAgent:   c.Request.UserAgent(),
+			Body:        bodyStr,
+			Response:    respStr,
+			Module:      module,
+			Action:      action,
+			AccessToken: accessTokenName,
+
+			ImpersonatorId:   toUint(impersonatorId),
+			ImpersonatorName: toString(impersonatorName),
 		}
 
-		// 异步写入日志
-		go func() {
-			if err := global.LV_DB.Create(&log).Error; err != nil {
-				global.LV_LOG.Error("操作日志写入失败: " + err.Error())
-			}
-		}()
+		// 提交到异步写入队列，由后台批量写入（同时交给告警规则评估）
+		logsink.Write(&log)
 	}
 }
 
-// 解析模块和操作类型
-func parseModuleAction(method, path string) (module, action string) {
-	// 解析模块
-	if strings.Contains(path, "/system/user") {
-		module = "用户管理"
-	} else if strings.Contains(path, "/system/role") {
-		module = "角色管理"
-	} else if strings.Contains(path, "/system/menu") {
-		module = "菜单管理"
-	} else if strings.Contains(path, "/dashboard") {
-		module = "仪表盘"
-	} else if strings.Contains(path, "/profile") {
-		module = "个人中心"
-	} else if strings.Contains(path, "/base/login") {
-		module = "登录"
-	} else {
-		module = "其他"
-	}
-
-	// 解析操作类型
-	switch method {
-	case "GET":
-		action = "查询"
-	case "POST":
-		if strings.Contains(path, "/login") {
-			action = "登录"
-		} else {
-			action = "新增"
-		}
-	case "PUT":
-		if strings.Contains(path, "reset-password") {
-			action = "重置密码"
-		} else if strings.Contains(path, "password") {
-			action = "修改密码"
-		} else {
-			action = "修改"
-		}
-	case "DELETE":
-		action = "删除"
-	default:
-		action = method
-	}
-
-	return
-}
-
 func toUint(v interface{}) uint {
 	if v == nil {
 		return 0
@@ -0,0 +1,53 @@
+package middleware
+
+import "sync"
+
+// RouteMeta 路由元数据，注册路由时登记，操作日志按匹配到的路由（c.FullPath()）读取模块和操作类型
+type RouteMeta struct {
+	Module string
+	Action string
+}
+
+var (
+	routeMetas   = make(map[string]RouteMeta)
+	routeMetasMu sync.RWMutex
+)
+
+// SetRouteMeta 登记路由的模块和操作类型，path 为完整的路由模板，如 /system/user/:id
+func SetRouteMeta(method, path string, meta RouteMeta) {
+	routeMetasMu.Lock()
+	defer routeMetasMu.Unlock()
+	routeMetas[method+" "+path] = meta
+}
+
+// LookupRouteMeta 获取路由登记的模块和操作类型
+func LookupRouteMeta(method, path string) (RouteMeta, bool) {
+	routeMetasMu.RLock()
+	defer routeMetasMu.RUnlock()
+	meta, ok := routeMetas[method+" "+path]
+	return meta, ok
+}
+
+// routeModuleAction 解析操作日志的模块和操作类型，未登记的路由（如 404）按请求方式推断
+func routeModuleAction(method, fullPath string) (module, action string) {
+	if meta, ok := LookupRouteMeta(method, fullPath); ok {
+		return meta.Module, meta.Action
+	}
+	return "其他", defaultAction(method)
+}
+
+// defaultAction 按请求方式推断的操作类型
+func defaultAction(method string) string {
+	switch method {
+	case "GET":
+		return "查询"
+	case "POST":
+		return "新增"
+	case "PUT":
+		return "修改"
+	case "DELETE":
+		return "删除"
+	default:
+		return method
+	}
+}
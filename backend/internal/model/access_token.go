@@ -0,0 +1,25 @@
+package model
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// LvAccessToken 个人访问令牌（lvpat_ 前缀），供脚本与 CI 调用接口，仅保存摘要
+type LvAccessToken struct {
+	gorm.Model
+	UserId      uint       `json:"userId" gorm:"index;comment:用户ID"`
+	Name        string     `json:"name" gorm:"size:64;comment:令牌名称"`
+	TokenPrefix string     `json:"tokenPrefix" gorm:"size:16;comment:令牌前缀，用于识别"`
+	TokenHash   string     `json:"-" gorm:"size:64;uniqueIndex;comment:令牌摘要"`
+	ExpiresAt   time.Time  `json:"expiresAt" gorm:"comment:过期时间"`
+	LastUsedAt  *time.Time `json:"lastUsedAt" gorm:"comment:最近使用时间"`
+	LastUsedIp  string     `json:"lastUsedIp" gorm:"size:64;comment:最近使用IP"`
+	// 可访问的接口子集，为空表示继承用户角色的全部权限
+	Apis []LvApi `json:"apis" gorm:"many2many:lv_access_token_apis;"`
+}
+
+func (LvAccessToken) TableName() string {
+	return "lv_access_tokens"
+}
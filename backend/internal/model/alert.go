@@ -0,0 +1,91 @@
+package model
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// 告警级别
+const (
+	AlertInfo     = "info"
+	AlertWarning  = "warning"
+	AlertCritical = "critical"
+)
+
+// LvAlertRule 安全告警规则，按操作日志逐条匹配
+// Module / Action / Method 可填写多个值（逗号分隔），为空表示不限；GroupBy 不为空时在 Window 内按 IP 或用户累计，达到 Threshold 次才触发
+type LvAlertRule struct {
+	gorm.Model
+	Name        string `json:"name" gorm:"size:64;comment:规则名称"`
+	Description string `json:"description" gorm:"size:256;comment:描述"`
+	Level       string `json:"level" gorm:"size:16;default:warning;comment:级别 info/warning/critical"`
+	Module      string `json:"module" gorm:"size:256;comment:操作模块，逗号分隔"`
+	Action      string `json:"action" gorm:"size:256;comment:操作类型，逗号分隔"`
+	Method      string `json:"method" gorm:"size:64;comment:请求方式，逗号分隔"`
+	PathPrefix  string `json:"pathPrefix" gorm:"size:256;comment:请求路径前缀"`
+	Result      string `json:"result" gorm:"size:16;comment:请求结果 success/failure，为空不限"`
+	MinItems    int    `json:"minItems" gorm:"default:0;comment:请求参数 ids 的最少条数（批量操作），0 表示不限"`
+	OffHours    bool   `json:"offHours" gorm:"comment:只匹配工作时间以外的请求"`
+	WorkHours   string `json:"workHours" gorm:"size:16;comment:工作时间，如 09:00-18:00"`
+	WorkDays    string `json:"workDays" gorm:"size:32;comment:工作日，0 为周日，如 1,2,3,4,5"`
+	GroupBy     string `json:"groupBy" gorm:"size:16;comment:累计维度 ip/user，为空表示每条日志单独判断"`
+	Threshold   int    `json:"threshold" gorm:"default:1;comment:触发次数"`
+	Window      string `json:"window" gorm:"size:16;comment:累计时间窗口，如 5m"`
+	Cooldown    string `json:"cooldown" gorm:"size:16;comment:同一规则同一维度的静默时间，如 30m"`
+	Channels    string `json:"channels" gorm:"size:128;comment:通知渠道，逗号分隔，如 webhook,email"`
+	Status      int    `json:"status" gorm:"default:1;comment:状态 1启用 2停用"`
+}
+
+func (LvAlertRule) TableName() string {
+	return "lv_alert_rules"
+}
+
+func (LvAlertRule) AuditEntity() string {
+	return "alert_rule"
+}
+
+// DefaultAlertRules 默认告警规则，首次启动时写入
+var DefaultAlertRules = []LvAlertRule{
+	{Name: "同一 IP 登录失败过多", Description: "同一 IP 5 分钟内登录失败 10 次，可能在尝试爆破密码", Level: AlertWarning,
+		Module: "登录", Action: "登录失败", GroupBy: "ip", Threshold: 10, Window: "5m", Cooldown: "30m", Channels: "webhook,email", Status: 1},
+	{Name: "清空操作日志", Description: "操作日志被清空", Level: AlertCritical,
+		Module: "操作日志", Action: "清空", Result: "success", Threshold: 1, Channels: "webhook,email", Status: 1},
+	{Name: "批量删除", Description: "单次请求删除 50 条以上数据", Level: AlertWarning,
+		Method: "DELETE", Result: "success", MinItems: 50, Threshold: 1, Channels: "webhook,email", Status: 1},
+	{Name: "频繁删除", Description: "同一用户 5 分钟内删除 30 次", Level: AlertWarning,
+		Action: "删除", Result: "success", GroupBy: "user", Threshold: 30, Window: "5m", Cooldown: "30m", Channels: "webhook,email", Status: 1},
+	{Name: "角色权限变更", Description: "角色的菜单、接口权限或数据范围被修改", Level: AlertWarning,
+		Module: "角色管理", Action: "分配菜单,分配接口,设置数据范围", Result: "success", Threshold: 1, Channels: "webhook,email", Status: 1},
+	{Name: "非工作时间操作", Description: "工作时间以外的新增、修改、删除操作，同一用户每小时最多提醒一次", Level: AlertInfo,
+		Method: "POST,PUT,DELETE", Result: "success", OffHours: true, WorkHours: "09:00-18:00", WorkDays: "1,2,3,4,5",
+		GroupBy: "user", Threshold: 1, Cooldown: "1h", Status: 1},
+}
+
+// LvAlert 安全告警记录，由规则匹配操作日志生成，在后台告警中心处理
+type LvAlert struct {
+	gorm.Model
+	TenantId    uint       `json:"tenantId" gorm:"default:0;index;comment:租户ID"`
+	RuleId      uint       `json:"ruleId" gorm:"index;comment:规则ID"`
+	RuleName    string     `json:"ruleName" gorm:"size:64;comment:规则名称"`
+	Level       string     `json:"level" gorm:"size:16;index;comment:级别"`
+	Message     string     `json:"message" gorm:"size:512;comment:告警内容"`
+	Count       int        `json:"count" gorm:"comment:窗口内累计次数"`
+	UserId      uint       `json:"userId" gorm:"comment:触发用户ID"`
+	Username    string     `json:"username" gorm:"size:64;comment:触发用户"`
+	Ip          string     `json:"ip" gorm:"size:64;comment:IP地址"`
+	Method      string     `json:"method" gorm:"size:16;comment:请求方式"`
+	Path        string     `json:"path" gorm:"size:256;comment:请求路径"`
+	Module      string     `json:"module" gorm:"size:64;comment:操作模块"`
+	Action      string     `json:"action" gorm:"size:64;comment:操作类型"`
+	Status      int        `json:"status" gorm:"index;comment:状态码"`
+	Notified    string     `json:"notified" gorm:"size:128;comment:已通知的渠道"`
+	NotifyError string     `json:"notifyError" gorm:"size:512;comment:通知失败原因"`
+	HandledBy   string     `json:"handledBy" gorm:"size:64;comment:处理人"`
+	HandledAt   *time.Time `json:"handledAt" gorm:"index;comment:处理时间，为空表示未处理"`
+	Remark      string     `json:"remark" gorm:"size:256;comment:处理备注"`
+}
+
+func (LvAlert) TableName() string {
+	return "lv_alerts"
+}
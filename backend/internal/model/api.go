@@ -0,0 +1,18 @@
+package model
+
+import (
+	"gorm.io/gorm"
+)
+
+// LvApi 接口资源（对应一条 gin 路由），用于 Casbin 接口鉴权
+type LvApi struct {
+	gorm.Model
+	Path        string `json:"path" gorm:"size:191;uniqueIndex:idx_api_path_method;comment:接口路径"`
+	Method      string `json:"method" gorm:"size:16;uniqueIndex:idx_api_path_method;comment:请求方式"`
+	ApiGroup    string `json:"apiGroup" gorm:"size:64;comment:接口分组"`
+	Description string `json:"description" gorm:"comment:接口描述"`
+}
+
+func (LvApi) TableName() string {
+	return "lv_apis"
+}
@@ -0,0 +1,73 @@
+package model
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+// Auditable 实现该接口的模型在创建、更新、删除时由 GORM 回调记录字段级审计日志，AuditEntity 为审计日志中的实体类型
+// 字段标签 audit:"-" 表示不记录该字段，audit:"mask" 表示只记录发生了变化、不记录取值（如密码）
+type Auditable interface {
+	AuditEntity() string
+}
+
+// 审计操作类型
+const (
+	AuditCreate = "create"
+	AuditUpdate = "update"
+	AuditDelete = "delete"
+)
+
+// LvAuditLog 数据变更审计日志，记录谁在什么时候把哪条数据的哪些字段从什么改成了什么
+type LvAuditLog struct {
+	gorm.Model
+	TenantId uint   `json:"tenantId" gorm:"default:0;index;comment:租户ID"`
+	UserId   uint   `json:"userId" gorm:"default:0;index;comment:操作人ID(0为系统)"`
+	Username string `json:"username" gorm:"size:64;comment:操作人"`
+	// 模拟登录期间的变更，UserId/Username 为被模拟的用户
+	ImpersonatorId   uint         `json:"impersonatorId" gorm:"default:0;comment:模拟登录的管理员ID"`
+	ImpersonatorName string       `json:"impersonatorName" gorm:"size:64;comment:模拟登录的管理员"`
+	Ip               string       `json:"ip" gorm:"size:64;comment:IP地址"`
+	Entity           string       `json:"entity" gorm:"size:64;index:idx_lv_audit_logs_entity,priority:1;comment:实体类型"`
+	EntityId         string       `json:"entityId" gorm:"size:64;index:idx_lv_audit_logs_entity,priority:2;comment:实体主键"`
+	Action           string       `json:"action" gorm:"size:16;comment:操作 create/update/delete"`
+	Changes          AuditChanges `json:"changes" gorm:"type:text;comment:字段变更"`
+}
+
+func (LvAuditLog) TableName() string {
+	return "lv_audit_logs"
+}
+
+// AuditChange 单个字段的变更，创建时 Before 为空，删除时 After 为空
+type AuditChange struct {
+	Field  string `json:"field"`
+	Before string `json:"before"`
+	After  string `json:"after"`
+}
+
+// AuditChanges 字段变更列表，以 JSON 保存
+type AuditChanges []AuditChange
+
+func (c AuditChanges) Value() (driver.Value, error) {
+	if c == nil {
+		return "[]", nil
+	}
+	b, err := json.Marshal(c)
+	return string(b), err
+}
+
+func (c *AuditChanges) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		*c = nil
+		return nil
+	case []byte:
+		return json.Unmarshal(v, c)
+	case string:
+		return json.Unmarshal([]byte(v), c)
+	}
+	return fmt.Errorf("unsupported audit changes type: %T", value)
+}
@@ -13,3 +13,7 @@ type LvDemo struct {
 	Amount      float64 `json:"amount" gorm:"type:decimal(10,2);comment:金额"`
 	Description string  `json:"description" gorm:"type:varchar(255);comment:描述"`
 }
+
+func (LvDemo) AuditEntity() string {
+	return "demo"
+}
@@ -0,0 +1,22 @@
+package model
+
+import (
+	"gorm.io/gorm"
+)
+
+// LvDept 部门，ParentId 为 0 表示顶级部门
+type LvDept struct {
+	gorm.Model
+	TenantId uint     `json:"tenantId" gorm:"default:0;index;comment:租户ID"`
+	ParentId uint     `json:"parentId" gorm:"default:0;index;comment:父部门ID"`
+	Name     string   `json:"name" gorm:"size:64;comment:部门名称"`
+	Leader   string   `json:"leader" gorm:"size:64;comment:负责人"`
+	Phone    string   `json:"phone" gorm:"size:32;comment:联系电话"`
+	Sort     int      `json:"sort" gorm:"default:0;comment:排序"`
+	Status   int      `json:"status" gorm:"default:1;comment:状态 1正常 2停用"`
+	Children []LvDept `json:"children" gorm:"-"`
+}
+
+func (LvDept) TableName() string {
+	return "lv_depts"
+}
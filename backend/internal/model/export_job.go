@@ -0,0 +1,35 @@
+package model
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// 导出任务状态
+const (
+	ExportPending = "pending"
+	ExportRunning = "running"
+	ExportSuccess = "success"
+	ExportFailed  = "failed"
+)
+
+// LvExportJob 后台导出任务，数据量超过阈值的导出在后台生成文件并保存到存储驱动
+type LvExportJob struct {
+	gorm.Model
+	TenantId   uint       `json:"tenantId" gorm:"default:0;index;comment:租户ID"`
+	UserId     uint       `json:"userId" gorm:"index;comment:发起人ID"`
+	Type       string     `json:"type" gorm:"size:32;comment:导出类型"`
+	Format     string     `json:"format" gorm:"size:8;comment:文件格式 csv/xlsx"`
+	Filename   string     `json:"filename" gorm:"size:256;comment:文件名"`
+	Status     string     `json:"status" gorm:"size:16;default:pending;comment:状态 pending/running/success/failed"`
+	Count      int64      `json:"count" gorm:"comment:导出条数"`
+	Size       int64      `json:"size" gorm:"comment:文件大小"`
+	Key        string     `json:"-" gorm:"size:512;comment:存储Key"`
+	Error      string     `json:"error" gorm:"size:512;comment:失败原因"`
+	FinishedAt *time.Time `json:"finishedAt" gorm:"comment:完成时间"`
+}
+
+func (LvExportJob) TableName() string {
+	return "lv_export_jobs"
+}
@@ -0,0 +1,27 @@
+package model
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// LvLogArchive 操作日志归档，超过保留天数的日志压缩为 JSONL（.jsonl.gz）保存到存储驱动
+type LvLogArchive struct {
+	gorm.Model
+	Key       string    `json:"key" gorm:"size:512;comment:存储Key"`
+	Url       string    `json:"url" gorm:"size:1024;comment:访问地址"`
+	Filename  string    `json:"filename" gorm:"size:256;comment:文件名"`
+	Size      int64     `json:"size" gorm:"comment:文件大小"`
+	Count     int64     `json:"count" gorm:"comment:日志条数"`
+	MinId     uint      `json:"minId" gorm:"comment:最小日志ID"`
+	MaxId     uint      `json:"maxId" gorm:"comment:最大日志ID"`
+	StartTime time.Time `json:"startTime" gorm:"comment:最早日志时间"`
+	EndTime   time.Time `json:"endTime" gorm:"comment:最晚日志时间"`
+	// 已恢复到日志表时记录恢复时间，这些日志不会被再次归档，清除恢复后置空
+	RestoredAt *time.Time `json:"restoredAt" gorm:"comment:恢复时间"`
+}
+
+func (LvLogArchive) TableName() string {
+	return "lv_log_archives"
+}
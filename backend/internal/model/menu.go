@@ -23,3 +23,7 @@ type LvMenu struct {
 func (LvMenu) TableName() string {
 	return "lv_menus"
 }
+
+func (LvMenu) AuditEntity() string {
+	return "menu"
+}
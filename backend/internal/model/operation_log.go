@@ -1,22 +1,37 @@
 package model
 
-import "gorm.io/gorm"
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
 
 // LvOperationLog 操作日志
+// 与 gorm.Model 字段相同，单独声明以便为 created_at 建立索引（按时间范围查询和排序）
 type LvOperationLog struct {
-	gorm.Model
-	UserId    uint   `json:"userId" gorm:"comment:用户ID"`
-	Username  string `json:"username" gorm:"size:64;comment:用户名"`
-	Ip        string `json:"ip" gorm:"size:64;comment:IP地址"`
-	Method    string `json:"method" gorm:"size:16;comment:请求方式"`
-	Path      string `json:"path" gorm:"size:256;comment:请求路径"`
-	Status    int    `json:"status" gorm:"comment:状态码"`
-	Latency   int64  `json:"latency" gorm:"comment:耗时(ms)"`
-	UserAgent string `json:"userAgent" gorm:"size:512;comment:User-Agent"`
-	Body      string `json:"body" gorm:"type:text;comment:请求参数"`
-	Response  string `json:"response" gorm:"type:text;comment:响应内容"`
-	Module    string `json:"module" gorm:"size:64;comment:操作模块"`
-	Action    string `json:"action" gorm:"size:64;comment:操作类型"`
+	ID        uint      `gorm:"primarykey"`
+	CreatedAt time.Time `gorm:"index"`
+	UpdatedAt time.Time
+	DeletedAt gorm.DeletedAt `gorm:"index"`
+	TenantId  uint           `json:"tenantId" gorm:"default:0;index;comment:租户ID"`
+	UserId    uint           `json:"userId" gorm:"index;comment:用户ID"`
+	Username  string         `json:"username" gorm:"size:64;index;comment:用户名"`
+	Ip        string         `json:"ip" gorm:"size:64;index;comment:IP地址"`
+	Method    string         `json:"method" gorm:"size:16;comment:请求方式"`
+	Path      string         `json:"path" gorm:"size:256;index;comment:请求路径"`
+	Status    int            `json:"status" gorm:"index;comment:状态码"`
+	Latency   int64          `json:"latency" gorm:"index;comment:耗时(ms)"`
+	UserAgent string         `json:"userAgent" gorm:"size:512;comment:User-Agent"`
+	// 请求参数和响应内容建立全文索引（ngram 分词，支持中文），用于关键字搜索
+	Body     string `json:"body" gorm:"type:text;index:idx_lv_operation_logs_content,class:FULLTEXT,option:WITH PARSER ngram;comment:请求参数"`
+	Response string `json:"response" gorm:"type:text;index:idx_lv_operation_logs_content,class:FULLTEXT,option:WITH PARSER ngram;comment:响应内容"`
+	Module   string `json:"module" gorm:"size:64;index:idx_lv_operation_logs_module_action;comment:操作模块"`
+	Action   string `json:"action" gorm:"size:64;index:idx_lv_operation_logs_module_action;comment:操作类型"`
+	// 通过个人访问令牌发起的请求记录令牌名称
+	AccessToken string `json:"accessToken" gorm:"size:64;comment:访问令牌名称"`
+	// 模拟登录期间的操作，UserId/Username 为被模拟的用户
+	ImpersonatorId   uint   `json:"impersonatorId" gorm:"default:0;index;comment:模拟登录的管理员ID"`
+	ImpersonatorName string `json:"impersonatorName" gorm:"size:64;comment:模拟登录的管理员"`
 }
 
 func (LvOperationLog) TableName() string {
@@ -0,0 +1,14 @@
+package model
+
+import "gorm.io/gorm"
+
+// LvPasswordHistory 历史密码（仅保存 bcrypt 摘要），用于禁止重复使用最近的密码
+type LvPasswordHistory struct {
+	gorm.Model
+	UserId       uint   `json:"userId" gorm:"index;comment:用户ID"`
+	PasswordHash string `json:"-" gorm:"comment:密码摘要"`
+}
+
+func (LvPasswordHistory) TableName() string {
+	return "lv_password_histories"
+}
@@ -0,0 +1,19 @@
+package model
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// LvUserRecoveryCode 两步验证恢复码（仅保存摘要），每个恢复码只能使用一次
+type LvUserRecoveryCode struct {
+	gorm.Model
+	UserId   uint       `json:"userId" gorm:"index;comment:用户ID"`
+	CodeHash string     `json:"-" gorm:"size:64;comment:恢复码摘要"`
+	UsedAt   *time.Time `json:"usedAt" gorm:"comment:使用时间"`
+}
+
+func (LvUserRecoveryCode) TableName() string {
+	return "lv_user_recovery_codes"
+}
@@ -2,8 +2,42 @@ package request
 
 // User Login Structure
 type Login struct {
-	Username  string `json:"username"`
-	Password  string `json:"password"`
-	Captcha   string `json:"captcha"`   // Verification code
-	CaptchaId string `json:"captchaId"` // Verification code ID
+	TenantCode string `json:"tenantCode"` // Tenant code, empty for the platform (multi-tenant mode only)
+	Username   string `json:"username"`
+	Password   string `json:"password"`
+	Captcha    string `json:"captcha"`   // Verification code
+	CaptchaId  string `json:"captchaId"` // Verification code ID
+	Device     string `json:"device"`    // Optional device name, parsed from User-Agent if empty
+}
+
+// Refresh Token Structure
+type RefreshToken struct {
+	RefreshToken string `json:"refreshToken" binding:"required"`
+}
+
+// Two-factor Login Structure
+type LoginMfa struct {
+	MfaToken string `json:"mfaToken" binding:"required"` // Token returned by /base/login
+	Code     string `json:"code" binding:"required"`     // TOTP code or recovery code
+	Device   string `json:"device"`
+}
+
+// OIDC Login Structure
+type OidcLogin struct {
+	Code   string `json:"code" binding:"required"`  // Authorization code from the identity provider
+	State  string `json:"state" binding:"required"` // State returned by /base/oidc/url
+	Device string `json:"device"`
+}
+
+// Create User Structure (LvUser does not bind the password from JSON)
+type CreateUser struct {
+	Username string `json:"username" binding:"required"`
+	Password string `json:"password" binding:"required"`
+	Nickname string `json:"nickname"`
+	Email    string `json:"email"`
+	Phone    string `json:"phone"`
+	RoleId   uint   `json:"role_id"`
+	RoleIds  []uint `json:"roleIds"`
+	DeptId   uint   `json:"deptId"`
+	Status   int    `json:"status"`
 }
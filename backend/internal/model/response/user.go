@@ -3,7 +3,32 @@ package response
 import "go-lv-vue-admin/internal/model"
 
 type LoginResponse struct {
-	User      model.LvUser `json:"user"`
-	Token     string       `json:"token"`
-	ExpiresAt int64        `json:"expiresAt"`
+	User               model.LvUser `json:"user"`
+	Token              string       `json:"token"`
+	ExpiresAt          int64        `json:"expiresAt"`
+	RefreshToken       string       `json:"refreshToken"`
+	RefreshExpiresAt   int64        `json:"refreshExpiresAt"`
+	MustChangePassword bool         `json:"mustChangePassword"` // 密码已过期，需先修改密码
+	MustSetupMfa       bool         `json:"mustSetupMfa"`       // 角色要求两步验证，需先完成绑定
+}
+
+// MfaPendingResponse 已启用两步验证时，密码校验通过后返回
+type MfaPendingResponse struct {
+	MfaRequired  bool   `json:"mfaRequired"`
+	MfaToken     string `json:"mfaToken"`
+	MfaExpiresAt int64  `json:"mfaExpiresAt"`
+}
+
+// ProfileResponse 个人信息，模拟登录的会话额外标明发起模拟的管理员
+type ProfileResponse struct {
+	model.LvUser
+	Impersonated     bool   `json:"impersonated"`
+	ImpersonatorName string `json:"impersonatorName,omitempty"`
+}
+
+type RefreshResponse struct {
+	Token            string `json:"token"`
+	ExpiresAt        int64  `json:"expiresAt"`
+	RefreshToken     string `json:"refreshToken"`
+	RefreshExpiresAt int64  `json:"refreshExpiresAt"`
 }
@@ -6,14 +6,26 @@ import (
 
 type LvRole struct {
 	gorm.Model
-	Name    string   `json:"name" gorm:"comment:角色名"`
-	Keyword string   `json:"keyword" gorm:"unique;comment:角色关键字"`
-	Desc    string   `json:"desc" gorm:"comment:角色说明"`
-	Status  int      `json:"status" gorm:"default:1;comment:角色状态"`
-	Sort    int      `json:"sort" gorm:"default:0;comment:角色排序"`
-	Menus   []LvMenu `json:"menus" gorm:"many2many:lv_role_menus;"`
+	TenantId uint   `json:"tenantId" gorm:"default:0;uniqueIndex:idx_lv_roles_tenant_keyword,priority:1;comment:租户ID"`
+	ParentId uint   `json:"parentId" gorm:"default:0;comment:父角色ID"`
+	Name     string `json:"name" gorm:"comment:角色名"`
+	Keyword  string `json:"keyword" gorm:"size:64;uniqueIndex:idx_lv_roles_tenant_keyword,priority:2;comment:角色关键字(租户内唯一)"`
+	Desc     string `json:"desc" gorm:"comment:角色说明"`
+	Status   int    `json:"status" gorm:"default:1;comment:角色状态"`
+	Sort     int    `json:"sort" gorm:"default:0;comment:角色排序"`
+	// 是否强制该角色下的用户启用两步验证
+	RequireMfa bool `json:"requireMfa" gorm:"default:false;comment:是否强制两步验证"`
+	// 数据范围，自定义时可访问的部门见 Depts
+	DataScope int      `json:"dataScope" gorm:"default:1;comment:数据范围 1全部 2本部门 3本部门及以下 4仅本人 5自定义"`
+	Depts     []LvDept `json:"depts" gorm:"many2many:lv_role_depts;"`
+	Menus     []LvMenu `json:"menus" gorm:"many2many:lv_role_menus;"`
+	Apis      []LvApi  `json:"apis" gorm:"many2many:lv_role_apis;"`
 }
 
 func (LvRole) TableName() string {
 	return "lv_roles"
 }
+
+func (LvRole) AuditEntity() string {
+	return "role"
+}
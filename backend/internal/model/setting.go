@@ -4,10 +4,11 @@ import (
 	"gorm.io/gorm"
 )
 
-// LvSetting 系统设置表
+// LvSetting 系统设置表，TenantId 为 0 的是全局默认值，租户设置覆盖同名的全局设置
 type LvSetting struct {
 	gorm.Model
-	Key         string `json:"key" gorm:"uniqueIndex;comment:设置键"`
+	TenantId    uint   `json:"tenantId" gorm:"default:0;uniqueIndex:idx_lv_settings_tenant_key,priority:1;comment:租户ID"`
+	Key         string `json:"key" gorm:"size:128;uniqueIndex:idx_lv_settings_tenant_key,priority:2;comment:设置键"`
 	Value       string `json:"value" gorm:"type:text;comment:设置值"`
 	Name        string `json:"name" gorm:"comment:显示名称"`
 	Description string `json:"description" gorm:"comment:描述"`
@@ -17,9 +18,22 @@ func (LvSetting) TableName() string {
 	return "lv_settings"
 }
 
+func (LvSetting) AuditEntity() string {
+	return "setting"
+}
+
 // 默认设置（仅基础信息）
 var DefaultSettings = []LvSetting{
 	{Key: "site_name", Value: "Go Lv Admin", Name: "系统名称", Description: "显示在标题栏和登录页"},
 	{Key: "site_logo", Value: "", Name: "系统Logo", Description: "Logo图片URL"},
 	{Key: "site_footer", Value: "© 2024 Go Lv Admin", Name: "底部版权", Description: "页面底部显示的版权信息"},
+	// 密码策略
+	{Key: "password_min_length", Value: "6", Name: "密码最小长度", Description: "新密码的最少字符数"},
+	{Key: "password_require_upper", Value: "false", Name: "必须包含大写字母", Description: "true/false"},
+	{Key: "password_require_lower", Value: "false", Name: "必须包含小写字母", Description: "true/false"},
+	{Key: "password_require_digit", Value: "false", Name: "必须包含数字", Description: "true/false"},
+	{Key: "password_require_symbol", Value: "false", Name: "必须包含特殊字符", Description: "true/false"},
+	{Key: "password_banned_list", Value: "", Name: "禁用密码", Description: "额外禁止使用的密码，逗号分隔（内置常见弱密码始终禁用）"},
+	{Key: "password_history_count", Value: "0", Name: "历史密码限制", Description: "不允许重复使用最近 N 次的密码，0 表示不限制"},
+	{Key: "password_max_age_days", Value: "0", Name: "密码有效期(天)", Description: "超过天数后登录需先修改密码，0 表示永不过期"},
 }
@@ -0,0 +1,23 @@
+package model
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// LvTenant 租户，多租户模式下用户登录时通过 Code 识别租户
+type LvTenant struct {
+	gorm.Model
+	Name      string     `json:"name" gorm:"size:64;comment:租户名称"`
+	Code      string     `json:"code" gorm:"size:64;uniqueIndex;comment:租户编码"`
+	Contact   string     `json:"contact" gorm:"size:64;comment:联系人"`
+	Phone     string     `json:"phone" gorm:"size:32;comment:联系电话"`
+	Status    int        `json:"status" gorm:"default:1;comment:状态 1正常 2停用"`
+	ExpiresAt *time.Time `json:"expiresAt" gorm:"comment:到期时间，为空表示不限"`
+	Remark    string     `json:"remark" gorm:"size:256;comment:备注"`
+}
+
+func (LvTenant) TableName() string {
+	return "lv_tenants"
+}
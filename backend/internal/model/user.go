@@ -1,22 +1,43 @@
 package model
 
 import (
+	"time"
+
 	"gorm.io/gorm"
 )
 
 type LvUser struct {
 	gorm.Model
+	TenantId uint   `json:"tenantId" gorm:"default:0;index;comment:租户ID"`
 	Username string `json:"username" gorm:"index;comment:用户登录名"`
-	Password string `json:"-"  gorm:"comment:用户登录密码"`
+	Password string `json:"-"  gorm:"comment:用户登录密码" audit:"mask"`
 	Nickname string `json:"nickname" gorm:"default:系统用户;comment:用户昵称"`
 	Avatar   string `json:"avatar" gorm:"default:https://via.placeholder.com/200;comment:用户头像"`
 	Email    string `json:"email" gorm:"comment:用户邮箱"`
 	Phone    string `json:"phone" gorm:"comment:用户手机号"`
 	Status   int    `json:"status" gorm:"default:1;comment:用户状态 1正常 2冻结"`
-	RoleId   uint   `json:"role_id" gorm:"comment:用户角色ID"`
+	RoleId   uint   `json:"role_id" gorm:"comment:用户主角色ID"`
 	Role     LvRole `json:"Role" gorm:"foreignKey:RoleId"`
+	// 用户拥有的全部角色，权限取并集；RoleId 为其中的主角色
+	Roles   []LvRole `json:"roles" gorm:"many2many:lv_user_roles;"`
+	RoleIds []uint   `json:"roleIds" gorm:"-"`
+	DeptId  uint     `json:"deptId" gorm:"default:0;index;comment:所属部门ID"`
+	// 账号来源，外部账号（ldap/oidc）只能通过对应认证源登录
+	Source string `json:"source" gorm:"size:20;default:local;comment:账号来源 local/ldap/oidc"`
+	// 登录失败过多时的锁定截止时间
+	LockedUntil *time.Time `json:"lockedUntil" gorm:"comment:锁定截止时间"`
+	// 最近一次修改密码的时间，用于密码有效期
+	PasswordChangedAt *time.Time `json:"passwordChangedAt" gorm:"comment:密码修改时间"`
+	// TOTP 两步验证，TotpSecret 在确认绑定前即写入，TotpEnabled 为真后才生效
+	TotpSecret   string `json:"-" gorm:"comment:TOTP密钥" audit:"mask"`
+	TotpEnabled  bool   `json:"totpEnabled" gorm:"default:false;comment:是否启用两步验证"`
+	TotpLastStep int64  `json:"-" gorm:"default:0;comment:最近使用的TOTP时间步" audit:"-"`
 }
 
 func (LvUser) TableName() string {
 	return "lv_users"
 }
+
+func (LvUser) AuditEntity() string {
+	return "user"
+}
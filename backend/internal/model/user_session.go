@@ -0,0 +1,31 @@
+package model
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// LvUserSession 用户登录会话，一次登录对应一条记录
+// Access Token 的 jti 即 SessionId，Refresh Token 每次刷新都会轮换
+type LvUserSession struct {
+	gorm.Model
+	SessionId        string     `json:"sessionId" gorm:"size:64;uniqueIndex;comment:会话ID(JWT jti)"`
+	UserId           uint       `json:"userId" gorm:"index;comment:用户ID"`
+	RefreshTokenHash string     `json:"-" gorm:"size:64;index;comment:当前Refresh Token摘要"`
+	PrevTokenHash    string     `json:"-" gorm:"size:64;index;comment:上一个Refresh Token摘要(用于重放检测)"`
+	Device           string     `json:"device" gorm:"size:128;comment:设备"`
+	Ip               string     `json:"ip" gorm:"size:64;comment:IP地址"`
+	UserAgent        string     `json:"userAgent" gorm:"size:512;comment:User-Agent"`
+	LastSeenAt       time.Time  `json:"lastSeenAt" gorm:"comment:最后活跃时间"`
+	ExpiresAt        time.Time  `json:"expiresAt" gorm:"comment:Refresh Token过期时间"`
+	RevokedAt        *time.Time `json:"revokedAt" gorm:"comment:吊销时间"`
+	ActiveRoleId     uint       `json:"activeRoleId" gorm:"default:0;comment:当前生效角色ID(0为全部角色)"`
+	// 管理员模拟登录该用户时创建的会话，记录发起模拟的管理员
+	ImpersonatorId   uint   `json:"impersonatorId" gorm:"default:0;index;comment:模拟登录的管理员ID"`
+	ImpersonatorName string `json:"impersonatorName" gorm:"size:64;comment:模拟登录的管理员"`
+}
+
+func (LvUserSession) TableName() string {
+	return "lv_user_sessions"
+}
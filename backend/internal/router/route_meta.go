@@ -0,0 +1,45 @@
+package router
+
+import (
+	"go-lv-vue-admin/internal/middleware"
+	"net/http"
+	"path"
+
+	"github.com/gin-gonic/gin"
+)
+
+// moduleGroup 注册路由的同时登记操作日志的模块和操作类型
+type moduleGroup struct {
+	*gin.RouterGroup
+	module string
+}
+
+// withModule 为路由组指定操作日志中的模块名
+func withModule(group *gin.RouterGroup, module string) moduleGroup {
+	return moduleGroup{RouterGroup: group, module: module}
+}
+
+func (g moduleGroup) GET(relativePath, action string, handlers ...gin.HandlerFunc) {
+	g.handle(http.MethodGet, relativePath, action, handlers)
+}
+
+func (g moduleGroup) POST(relativePath, action string, handlers ...gin.HandlerFunc) {
+	g.handle(http.MethodPost, relativePath, action, handlers)
+}
+
+func (g moduleGroup) PUT(relativePath, action string, handlers ...gin.HandlerFunc) {
+	g.handle(http.MethodPut, relativePath, action, handlers)
+}
+
+func (g moduleGroup) DELETE(relativePath, action string, handlers ...gin.HandlerFunc) {
+	g.handle(http.MethodDelete, relativePath, action, handlers)
+}
+
+func (g moduleGroup) handle(method, relativePath, action string, handlers []gin.HandlerFunc) {
+	g.RouterGroup.Handle(method, relativePath, handlers...)
+	fullPath := g.BasePath()
+	if relativePath != "" {
+		fullPath = path.Join(fullPath, relativePath)
+	}
+	middleware.SetRouteMeta(method, fullPath, middleware.RouteMeta{Module: g.module, Action: action})
+}
@@ -29,108 +29,226 @@ func InitRouter(r *gin.Engine) {
 	baseApi := v1.UserApi{}
 	baseGroup := r.Group("base")
 	{
+		baseGroup.GET("captcha", baseApi.Captcha)
 		baseGroup.POST("login", baseApi.Login)
+		baseGroup.POST("login/2fa", baseApi.LoginMfa)
+		baseGroup.GET("oidc/url", baseApi.OidcUrl)
+		baseGroup.POST("oidc/login", baseApi.OidcLogin)
+		baseGroup.POST("refresh", baseApi.Refresh)
 	}
 
-	// =========== 以下路由需要 JWT 认证 ===========
+	// =========== 以下路由仅需登录 (JWT 认证，不做接口鉴权) ===========
+	authGroup := r.Group("")
+	authGroup.Use(middleware.JWTAuth())
+	authGroup.Use(middleware.OperationLog()) // 操作日志中间件
+	{
+		// Logout
+		logoutGroup := withModule(authGroup, "登录")
+		logoutGroup.POST("/base/logout", "退出登录", baseApi.Logout)
+		logoutGroup.POST("/base/logout/all", "退出登录", baseApi.LogoutAll)
+
+		// Dashboard Router
+		dashboardApi := v1.DashboardApi{}
+		dashboardGroup := withModule(authGroup, "仪表盘")
+		dashboardGroup.GET("/dashboard/stats", "查询", dashboardApi.GetStats)
+		dashboardGroup.GET("/dashboard/charts", "查询", dashboardApi.GetCharts)
+
+		// Profile Router
+		profileApi := v1.ProfileApi{}
+		profileGroup := withModule(authGroup.Group("profile"), "个人中心")
+		{
+			profileGroup.GET("", "查询", profileApi.GetProfile)
+			profileGroup.PUT("", "修改", profileApi.UpdateProfile)
+			profileGroup.PUT("password", "修改密码", profileApi.ChangePassword)
+			profileGroup.GET("sessions", "查询", profileApi.GetSessions)
+			profileGroup.DELETE("sessions/:sessionId", "下线会话", profileApi.RevokeSession)
+			profileGroup.GET("2fa", "查询", profileApi.GetTwoFactor)
+			profileGroup.POST("2fa/setup", "绑定两步验证", profileApi.SetupTwoFactor)
+			profileGroup.POST("2fa/enable", "绑定两步验证", profileApi.EnableTwoFactor)
+			profileGroup.POST("2fa/disable", "关闭两步验证", profileApi.DisableTwoFactor)
+			profileGroup.POST("2fa/recovery-codes", "重置恢复码", profileApi.RegenerateRecoveryCodes)
+			profileGroup.GET("tokens", "查询", profileApi.GetAccessTokens)
+			profileGroup.GET("tokens/apis", "查询", profileApi.GetAccessTokenApis)
+			profileGroup.POST("tokens", "新增", profileApi.CreateAccessToken)
+			profileGroup.DELETE("tokens/:id", "删除", profileApi.DeleteAccessToken)
+		}
+
+		// User Permission Router (获取当前登录用户的权限信息)
+		permissionApi := v1.PermissionApi{}
+		userGroup := withModule(authGroup.Group("user"), "个人中心")
+		{
+			userGroup.GET("permissions", "查询", permissionApi.GetUserPermissions)
+			userGroup.GET("menus", "查询", permissionApi.GetUserMenus)
+			userGroup.GET("roles", "查询", permissionApi.GetUserRoles)
+			userGroup.PUT("active-role", "切换角色", permissionApi.SwitchActiveRole)
+		}
+
+		// Export Job Router (当前用户的后台导出任务，导出接口本身按业务接口鉴权)
+		exportApi := v1.ExportApi{}
+		exportGroup := withModule(authGroup.Group("export"), "数据导出")
+		{
+			exportGroup.GET("jobs", "查询", exportApi.GetJobList)
+			exportGroup.GET("jobs/:id/download", "下载", exportApi.DownloadJob)
+		}
+	}
+
+	// =========== 以下路由需要 JWT 认证 + Casbin 接口鉴权 ===========
 	privateGroup := r.Group("")
 	privateGroup.Use(middleware.JWTAuth())
-	privateGroup.Use(middleware.OperationLog()) // 操作日志中间件
+	privateGroup.Use(middleware.OperationLog()) // 操作日志中间件（需在鉴权前，以记录越权访问）
+	privateGroup.Use(middleware.CasbinAuth())   // Casbin 接口鉴权
 	{
 		// Settings (Private)
 		settingApi := v1.SettingApi{}
-		privateGroup.GET("/settings", settingApi.GetSettings)
-		privateGroup.PUT("/settings", settingApi.UpdateSettings)
-
-		// Dashboard Router
-		dashboardApi := v1.DashboardApi{}
-		privateGroup.GET("/dashboard/stats", dashboardApi.GetStats)
-		privateGroup.GET("/dashboard/charts", dashboardApi.GetCharts)
+		settingGroup := withModule(privateGroup, "系统设置")
+		settingGroup.GET("/settings", "查询", settingApi.GetSettings)
+		settingGroup.PUT("/settings", "修改", settingApi.UpdateSettings)
 
 		// Upload Router
 		uploadApi := v1.UploadApi{}
-		privateGroup.POST("/upload/image", uploadApi.UploadImage)
-		privateGroup.POST("/upload/file", uploadApi.UploadFile)
-		privateGroup.DELETE("/upload/file", uploadApi.DeleteFile)
+		uploadGroup := withModule(privateGroup, "文件管理")
+		uploadGroup.POST("/upload/image", "上传", uploadApi.UploadImage)
+		uploadGroup.POST("/upload/file", "上传", uploadApi.UploadFile)
+		uploadGroup.DELETE("/upload/file", "删除", uploadApi.DeleteFile)
 
 		// System User Router
 		systemUserApi := v1.SystemUserApi{}
-		systemUserGroup := privateGroup.Group("system/user")
+		systemUserGroup := withModule(privateGroup.Group("system/user"), "用户管理")
 		{
-			systemUserGroup.GET("list", systemUserApi.GetUserList)
-			systemUserGroup.GET("role-options", systemUserApi.GetRoleOptions)
-			systemUserGroup.POST("", systemUserApi.CreateUser)
-			systemUserGroup.PUT(":id", systemUserApi.UpdateUser)
-			systemUserGroup.DELETE(":id", systemUserApi.DeleteUser)
-			systemUserGroup.PUT(":id/reset-password", systemUserApi.ResetPassword)
+			systemUserGroup.GET("list", "查询", systemUserApi.GetUserList)
+			systemUserGroup.GET("export", "导出", systemUserApi.ExportUsers)
+			systemUserGroup.GET("import/template", "查询", systemUserApi.GetImportTemplate)
+			systemUserGroup.POST("import", "导入", systemUserApi.ImportUsers)
+			systemUserGroup.GET("role-options", "查询", systemUserApi.GetRoleOptions)
+			systemUserGroup.POST("", "新增", systemUserApi.CreateUser)
+			systemUserGroup.PUT(":id", "修改", systemUserApi.UpdateUser)
+			systemUserGroup.DELETE(":id", "删除", systemUserApi.DeleteUser)
+			systemUserGroup.PUT(":id/reset-password", "重置密码", systemUserApi.ResetPassword)
+			systemUserGroup.PUT(":id/unlock", "解锁", systemUserApi.UnlockUser)
+			systemUserGroup.PUT(":id/reset-2fa", "重置两步验证", systemUserApi.ResetTwoFactor)
+			systemUserGroup.POST(":id/impersonate", "模拟登录", systemUserApi.Impersonate)
 		}
 
 		// System Role Router
 		systemRoleApi := v1.SystemRoleApi{}
 		permissionApi := v1.PermissionApi{}
-		systemRoleGroup := privateGroup.Group("system/role")
+		systemRoleGroup := withModule(privateGroup.Group("system/role"), "角色管理")
+		{
+			systemRoleGroup.GET("list", "查询", systemRoleApi.GetRoleList)
+			systemRoleGroup.POST("", "新增", systemRoleApi.CreateRole)
+			systemRoleGroup.PUT(":id", "修改", systemRoleApi.UpdateRole)
+			systemRoleGroup.DELETE(":id", "删除", systemRoleApi.DeleteRole)
+			systemRoleGroup.GET(":id/menus", "查询", permissionApi.GetRoleMenus)
+			systemRoleGroup.PUT(":id/menus", "分配菜单", permissionApi.SetRoleMenus)
+			systemRoleGroup.GET(":id/apis", "查询", permissionApi.GetRoleApis)
+			systemRoleGroup.PUT(":id/apis", "分配接口", permissionApi.SetRoleApis)
+			systemRoleGroup.GET(":id/data-scope", "查询", systemRoleApi.GetRoleDataScope)
+			systemRoleGroup.PUT(":id/data-scope", "设置数据范围", systemRoleApi.SetRoleDataScope)
+		}
+
+		// System Dept Router
+		systemDeptApi := v1.SystemDeptApi{}
+		systemDeptGroup := withModule(privateGroup.Group("system/dept"), "部门管理")
 		{
-			systemRoleGroup.GET("list", systemRoleApi.GetRoleList)
-			systemRoleGroup.POST("", systemRoleApi.CreateRole)
-			systemRoleGroup.PUT(":id", systemRoleApi.UpdateRole)
-			systemRoleGroup.DELETE(":id", systemRoleApi.DeleteRole)
-			systemRoleGroup.GET(":id/menus", permissionApi.GetRoleMenus)
-			systemRoleGroup.PUT(":id/menus", permissionApi.SetRoleMenus)
+			systemDeptGroup.GET("tree", "查询", systemDeptApi.GetDeptTree)
+			systemDeptGroup.POST("", "新增", systemDeptApi.CreateDept)
+			systemDeptGroup.PUT(":id", "修改", systemDeptApi.UpdateDept)
+			systemDeptGroup.PUT(":id/move", "移动", systemDeptApi.MoveDept)
+			systemDeptGroup.DELETE(":id", "删除", systemDeptApi.DeleteDept)
 		}
 
-		// System Menu Router
+		// System Api Router (接口资源管理，全局资源仅平台用户可管理)
+		systemApiApi := v1.SystemApiApi{}
+		systemApiGroup := withModule(privateGroup.Group("system/api", middleware.PlatformAuth()), "接口管理")
+		{
+			systemApiGroup.GET("list", "查询", systemApiApi.GetApiList)
+			systemApiGroup.GET("all", "查询", systemApiApi.GetAllApis)
+			systemApiGroup.POST("", "新增", systemApiApi.CreateApi)
+			systemApiGroup.PUT(":id", "修改", systemApiApi.UpdateApi)
+			systemApiGroup.DELETE(":id", "删除", systemApiApi.DeleteApi)
+		}
+
+		// System Menu Router (全局菜单仅平台用户可管理)
 		systemMenuApi := v1.SystemMenuApi{}
-		systemMenuGroup := privateGroup.Group("system/menu")
+		systemMenuGroup := withModule(privateGroup.Group("system/menu", middleware.PlatformAuth()), "菜单管理")
 		{
-			systemMenuGroup.GET("list", systemMenuApi.GetMenuList)
-			systemMenuGroup.POST("", systemMenuApi.CreateMenu)
-			systemMenuGroup.PUT(":id", systemMenuApi.UpdateMenu)
-			systemMenuGroup.DELETE(":id", systemMenuApi.DeleteMenu)
+			systemMenuGroup.GET("list", "查询", systemMenuApi.GetMenuList)
+			systemMenuGroup.POST("", "新增", systemMenuApi.CreateMenu)
+			systemMenuGroup.PUT(":id", "修改", systemMenuApi.UpdateMenu)
+			systemMenuGroup.DELETE(":id", "删除", systemMenuApi.DeleteMenu)
+		}
+
+		// System Session Router (在线会话管理)
+		systemSessionApi := v1.SystemSessionApi{}
+		systemSessionGroup := withModule(privateGroup.Group("system/session"), "在线用户")
+		{
+			systemSessionGroup.GET("list", "查询", systemSessionApi.GetSessionList)
+			systemSessionGroup.DELETE(":sessionId", "强制下线", systemSessionApi.ForceLogout)
+			systemSessionGroup.DELETE("user/:userId", "强制下线", systemSessionApi.ForceLogoutUser)
 		}
 
 		// Operation Log Router
 		operationLogApi := v1.OperationLogApi{}
-		operationLogGroup := privateGroup.Group("system/log")
+		operationLogGroup := withModule(privateGroup.Group("system/log"), "操作日志")
 		{
-			operationLogGroup.GET("list", operationLogApi.GetOperationLogList)
-			operationLogGroup.DELETE("", operationLogApi.DeleteOperationLogs)
-			operationLogGroup.DELETE("clear", operationLogApi.ClearOperationLogs)
+			operationLogGroup.GET("list", "查询", operationLogApi.GetOperationLogList)
+			operationLogGroup.GET("export", "导出", operationLogApi.ExportOperationLogs)
+			operationLogGroup.DELETE("", "删除", operationLogApi.DeleteOperationLogs)
+			operationLogGroup.DELETE("clear", "清空", operationLogApi.ClearOperationLogs)
+			operationLogGroup.POST("scrub", "脱敏", operationLogApi.ScrubOperationLogs)
+			operationLogGroup.GET("sink", "查询", middleware.PlatformAuth(), operationLogApi.GetSinkStats)
+			operationLogGroup.GET("archive/list", "查询", middleware.PlatformAuth(), operationLogApi.GetArchiveList)
+			operationLogGroup.POST("archive", "归档", middleware.PlatformAuth(), operationLogApi.ArchiveOperationLogs)
+			operationLogGroup.POST("archive/:id/restore", "恢复归档", middleware.PlatformAuth(), operationLogApi.RestoreArchive)
+			operationLogGroup.DELETE("archive/:id/restore", "清除恢复", middleware.PlatformAuth(), operationLogApi.ClearRestoredArchive)
 		}
 
-		// Profile Router
-		profileApi := v1.ProfileApi{}
-		profileGroup := privateGroup.Group("profile")
+		// Audit Log Router (数据变更审计)
+		auditApi := v1.AuditApi{}
+		auditGroup := withModule(privateGroup, "审计日志")
+		auditGroup.GET("/system/audit", "查询", auditApi.GetAuditList)
+
+		// Generator Router (代码生成器)
+		generatorApi := v1.GeneratorApi{}
+		generatorGroup := withModule(privateGroup.Group("generator", middleware.PlatformAuth()), "代码生成")
 		{
-			profileGroup.GET("", profileApi.GetProfile)
-			profileGroup.PUT("", profileApi.UpdateProfile)
-			profileGroup.PUT("password", profileApi.ChangePassword)
+			generatorGroup.GET("tables", "查询", generatorApi.GetTables)
+			generatorGroup.GET("columns", "查询", generatorApi.GetTableColumns)
+			generatorGroup.POST("preview", "预览", generatorApi.PreviewCode)
+			generatorGroup.POST("generate", "生成代码", generatorApi.GenerateCode)
 		}
 
-		// User Permission Router (获取当前登录用户的权限信息)
-		userGroup := privateGroup.Group("user")
+		// Platform Tenant Router (租户管理，仅平台用户)
+		tenantApi := v1.TenantApi{}
+		tenantGroup := withModule(privateGroup.Group("platform/tenant", middleware.PlatformAuth()), "租户管理")
 		{
-			userGroup.GET("permissions", permissionApi.GetUserPermissions)
-			userGroup.GET("menus", permissionApi.GetUserMenus)
+			tenantGroup.GET("list", "查询", tenantApi.GetTenantList)
+			tenantGroup.POST("", "新增", tenantApi.CreateTenant)
+			tenantGroup.PUT(":id", "修改", tenantApi.UpdateTenant)
+			tenantGroup.DELETE(":id", "删除", tenantApi.DeleteTenant)
 		}
 
-		// Generator Router (代码生成器)
-		generatorApi := v1.GeneratorApi{}
-		generatorGroup := privateGroup.Group("generator")
+		// Platform Alert Router (安全告警，仅平台用户)
+		alertApi := v1.AlertApi{}
+		alertGroup := withModule(privateGroup.Group("platform/alert", middleware.PlatformAuth()), "安全告警")
 		{
-			generatorGroup.GET("tables", generatorApi.GetTables)
-			generatorGroup.GET("columns", generatorApi.GetTableColumns)
-			generatorGroup.POST("preview", generatorApi.PreviewCode)
-			generatorGroup.POST("generate", generatorApi.GenerateCode)
+			alertGroup.GET("list", "查询", alertApi.GetAlertList)
+			alertGroup.GET("unhandled", "查询", alertApi.GetUnhandledCount)
+			alertGroup.PUT("handle", "处理告警", alertApi.HandleAlerts)
+			alertGroup.GET("rules", "查询", alertApi.GetRuleList)
+			alertGroup.POST("rules", "新增", alertApi.CreateRule)
+			alertGroup.PUT("rules/:id", "修改", alertApi.UpdateRule)
+			alertGroup.DELETE("rules/:id", "删除", alertApi.DeleteRule)
 		}
 
 		// Demo Router (ProTable Presentation)
 		demoApi := v1.DemoApi{}
-		demoGroup := privateGroup.Group("demo")
+		demoGroup := withModule(privateGroup.Group("demo"), "演示数据")
 		{
-			demoGroup.GET("list", demoApi.GetDemoList)
-			demoGroup.POST("", demoApi.CreateDemo)
-			demoGroup.PUT(":id", demoApi.UpdateDemo)
-			demoGroup.DELETE(":id", demoApi.DeleteDemo)
+			demoGroup.GET("list", "查询", demoApi.GetDemoList)
+			demoGroup.POST("", "新增", demoApi.CreateDemo)
+			demoGroup.PUT(":id", "修改", demoApi.UpdateDemo)
+			demoGroup.DELETE(":id", "删除", demoApi.DeleteDemo)
 		}
 	}
 
@@ -0,0 +1,177 @@
+package service
+
+import (
+	"errors"
+	"go-lv-vue-admin/internal/global"
+	"go-lv-vue-admin/internal/model"
+	"go-lv-vue-admin/pkg/utils"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/casbin/casbin/v2/util"
+	"gorm.io/gorm"
+)
+
+// AccessTokenPrefix 个人访问令牌前缀，JWTAuth 据此区分 JWT 与访问令牌
+const AccessTokenPrefix = "lvpat_"
+
+const (
+	accessTokenMaxDays    = 365 // 最长有效期（天）
+	accessTokenMaxPerUser = 20  // 每个用户最多持有的令牌数
+)
+
+var accessTokenLastUsedCache sync.Map
+
+type AccessTokenService struct{}
+
+// IsAccessToken 判断 Bearer 凭证是否为个人访问令牌
+func IsAccessToken(token string) bool {
+	return strings.HasPrefix(token, AccessTokenPrefix)
+}
+
+// GetUserTokens 获取用户的访问令牌列表
+func (s *AccessTokenService) GetUserTokens(userId uint) ([]model.LvAccessToken, error) {
+	var tokens []model.LvAccessToken
+	err := global.LV_DB.Preload("Apis").Where("user_id = ?", userId).Order("id DESC").Find(&tokens).Error
+	return tokens, err
+}
+
+// CreateToken 创建访问令牌，返回的明文令牌只在创建时可见
+func (s *AccessTokenService) CreateToken(user *model.LvUser, name string, expiresInDays int, apiIds []uint) (*model.LvAccessToken, string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, "", errors.New("请填写令牌名称")
+	}
+	if expiresInDays < 1 || expiresInDays > accessTokenMaxDays {
+		return nil, "", errors.New("有效期需在 1-365 天之间")
+	}
+
+	var count int64
+	global.LV_DB.Model(&model.LvAccessToken{}).Where("user_id = ?", user.ID).Count(&count)
+	if count >= accessTokenMaxPerUser {
+		return nil, "", errors.New("访问令牌数量已达上限")
+	}
+	global.LV_DB.Model(&model.LvAccessToken{}).Where("user_id = ? AND name = ?", user.ID, name).Count(&count)
+	if count > 0 {
+		return nil, "", errors.New("令牌名称已存在")
+	}
+
+	// 令牌权限只能是用户当前权限的子集
+	var apis []model.LvApi
+	if len(apiIds) > 0 {
+		if err := global.LV_DB.Where("id IN ?", apiIds).Find(&apis).Error; err != nil {
+			return nil, "", err
+		}
+		allowed, err := s.GetAvailableApis(user.ID)
+		if err != nil {
+			return nil, "", err
+		}
+		allowedIds := make(map[uint]bool, len(allowed))
+		for _, api := range allowed {
+			allowedIds[api.ID] = true
+		}
+		for _, api := range apis {
+			if !allowedIds[api.ID] {
+				return nil, "", errors.New("令牌权限超出当前用户权限: " + api.Method + " " + api.Path)
+			}
+		}
+	}
+
+	random, err := utils.RandomToken(32)
+	if err != nil {
+		return nil, "", err
+	}
+	raw := AccessTokenPrefix + random
+
+	token := model.LvAccessToken{
+		UserId:      user.ID,
+		Name:        name,
+		TokenPrefix: raw[:len(AccessTokenPrefix)+6],
+		TokenHash:   utils.HashToken(raw),
+		ExpiresAt:   time.Now().AddDate(0, 0, expiresInDays),
+		Apis:        apis,
+	}
+	if err := global.LV_DB.Create(&token).Error; err != nil {
+		return nil, "", err
+	}
+	return &token, raw, nil
+}
+
+// DeleteToken 吊销用户的访问令牌
+func (s *AccessTokenService) DeleteToken(userId, id uint) error {
+	var token model.LvAccessToken
+	if err := global.LV_DB.Where("id = ? AND user_id = ?", id, userId).First(&token).Error; err != nil {
+		return errors.New("令牌不存在")
+	}
+	return global.LV_DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&token).Association("Apis").Clear(); err != nil {
+			return err
+		}
+		return tx.Delete(&token).Error
+	})
+}
+
+// Authenticate 校验访问令牌，返回令牌（含接口子集）与所属用户
+func (s *AccessTokenService) Authenticate(raw string) (*model.LvAccessToken, *model.LvUser, error) {
+	var token model.LvAccessToken
+	if err := global.LV_DB.Preload("Apis").Where("token_hash = ?", utils.HashToken(raw)).First(&token).Error; err != nil {
+		return nil, nil, errors.New("访问令牌无效")
+	}
+	if time.Now().After(token.ExpiresAt) {
+		return nil, nil, errors.New("访问令牌已过期")
+	}
+
+	var user model.LvUser
+	if err := global.LV_DB.First(&user, token.UserId).Error; err != nil || user.Status != 1 {
+		return nil, nil, errors.New("用户不存在或已被冻结")
+	}
+	return &token, &user, nil
+}
+
+// TouchToken 更新令牌最近使用时间和 IP（按 lastSeenInterval 节流）
+func (s *AccessTokenService) TouchToken(id uint, ip string) {
+	now := time.Now()
+	if last, ok := accessTokenLastUsedCache.Load(id); ok && now.Sub(last.(time.Time)) < lastSeenInterval {
+		return
+	}
+	accessTokenLastUsedCache.Store(id, now)
+
+	global.LV_DB.Model(&model.LvAccessToken{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"last_used_at": now,
+		"last_used_ip": ip,
+	})
+}
+
+// GetAvailableApis 获取用户（全部有效角色）可访问的接口，用于选择令牌权限
+func (s *AccessTokenService) GetAvailableApis(userId uint) ([]model.LvApi, error) {
+	var apis []model.LvApi
+	if err := global.LV_DB.Order("api_group ASC, path ASC").Find(&apis).Error; err != nil {
+		return nil, err
+	}
+	if global.LV_ENFORCER == nil {
+		return nil, errors.New("casbin not initialized")
+	}
+
+	subject := UserSubject(userId)
+	available := make([]model.LvApi, 0, len(apis))
+	for _, api := range apis {
+		if ok, _ := global.LV_ENFORCER.Enforce(subject, api.Path, api.Method); ok {
+			available = append(available, api)
+		}
+	}
+	return available, nil
+}
+
+// TokenAllows 判断访问令牌是否允许访问该接口，未限定接口子集时不做额外限制
+func TokenAllows(token *model.LvAccessToken, path, method string) bool {
+	if len(token.Apis) == 0 {
+		return true
+	}
+	for _, api := range token.Apis {
+		if api.Method == method && util.KeyMatch2(path, api.Path) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,601 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go-lv-vue-admin/internal/global"
+	"go-lv-vue-admin/internal/logsink"
+	"go-lv-vue-admin/internal/model"
+	"go-lv-vue-admin/pkg/utils"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// alertQueueSize 未配置 alert.queue_size 时待评估日志的队列容量
+const alertQueueSize = 1000
+
+// 告警通知由固定数量的协程发送，待发送的通知超出队列容量时丢弃（告警本身已保存）
+const (
+	alertNotifyWorkers   = 4
+	alertNotifyQueueSize = 100
+)
+
+// alertIdsPattern 请求参数中的 ids 数组（日志中的请求参数可能被截断，只取到截断处）
+var alertIdsPattern = regexp.MustCompile(`"ids"\s*:\s*\[([^\]]*)`)
+
+type AlertService struct{}
+
+// GetRuleList 获取全部告警规则
+func (s *AlertService) GetRuleList(ctx context.Context) ([]model.LvAlertRule, error) {
+	var rules []model.LvAlertRule
+	err := global.LV_DB.WithContext(ctx).Order("id ASC").Find(&rules).Error
+	return rules, err
+}
+
+// CreateRule 创建告警规则，保存后立即生效
+func (s *AlertService) CreateRule(ctx context.Context, rule *model.LvAlertRule) error {
+	rule.ID = 0
+	if rule.Level == "" {
+		rule.Level = model.AlertWarning
+	}
+	if rule.Status == 0 {
+		rule.Status = 1
+	}
+	if _, err := compileAlertRule(*rule); err != nil {
+		return err
+	}
+	if err := global.LV_DB.WithContext(ctx).Create(rule).Error; err != nil {
+		return err
+	}
+	return alertEngine.reload(ctx)
+}
+
+// UpdateRule 更新告警规则，保存后立即生效
+func (s *AlertService) UpdateRule(ctx context.Context, rule *model.LvAlertRule) error {
+	if _, err := compileAlertRule(*rule); err != nil {
+		return err
+	}
+	err := global.LV_DB.WithContext(ctx).Model(&model.LvAlertRule{}).Where("id = ?", rule.ID).Updates(map[string]interface{}{
+		"name":        rule.Name,
+		"description": rule.Description,
+		"level":       rule.Level,
+		"module":      rule.Module,
+		"action":      rule.Action,
+		"method":      rule.Method,
+		"path_prefix": rule.PathPrefix,
+		"result":      rule.Result,
+		"min_items":   rule.MinItems,
+		"off_hours":   rule.OffHours,
+		"work_hours":  rule.WorkHours,
+		"work_days":   rule.WorkDays,
+		"group_by":    rule.GroupBy,
+		"threshold":   rule.Threshold,
+		"window":      rule.Window,
+		"cooldown":    rule.Cooldown,
+		"channels":    rule.Channels,
+		"status":      rule.Status,
+	}).Error
+	if err != nil {
+		return err
+	}
+	return alertEngine.reload(ctx)
+}
+
+// DeleteRule 删除告警规则，已产生的告警保留
+func (s *AlertService) DeleteRule(ctx context.Context, id uint) error {
+	if err := global.LV_DB.WithContext(ctx).Delete(&model.LvAlertRule{}, id).Error; err != nil {
+		return err
+	}
+	return alertEngine.reload(ctx)
+}
+
+// GetAlertList 获取告警记录（包含所有租户），handled 为 "0" 只看未处理，"1" 只看已处理
+func (s *AlertService) GetAlertList(ctx context.Context, page, pageSize int, level, handled, keyword string) ([]model.LvAlert, int64, error) {
+	var alerts []model.LvAlert
+	var total int64
+
+	db := global.LV_DB.WithContext(utils.WithoutTenant(ctx)).Model(&model.LvAlert{})
+	if level != "" {
+		db = db.Where("level = ?", level)
+	}
+	switch handled {
+	case "0":
+		db = db.Where("handled_at IS NULL")
+	case "1":
+		db = db.Where("handled_at IS NOT NULL")
+	}
+	if keyword != "" {
+		db = db.Where("rule_name LIKE ? OR username LIKE ? OR ip LIKE ?", "%"+keyword+"%", "%"+keyword+"%", keyword+"%")
+	}
+	db.Count(&total)
+
+	offset := (page - 1) * pageSize
+	err := db.Order("id DESC").Offset(offset).Limit(pageSize).Find(&alerts).Error
+
+	return alerts, total, err
+}
+
+// UnhandledCount 未处理的告警数，用于顶部提醒
+func (s *AlertService) UnhandledCount(ctx context.Context) (int64, error) {
+	var count int64
+	err := global.LV_DB.WithContext(utils.WithoutTenant(ctx)).Model(&model.LvAlert{}).Where("handled_at IS NULL").Count(&count).Error
+	return count, err
+}
+
+// HandleAlerts 将告警标记为已处理
+func (s *AlertService) HandleAlerts(ctx context.Context, ids []uint, handledBy, remark string) error {
+	if len(ids) == 0 {
+		return errors.New("请选择告警")
+	}
+	now := time.Now()
+	return global.LV_DB.WithContext(utils.WithoutTenant(ctx)).Model(&model.LvAlert{}).
+		Where("id IN ? AND handled_at IS NULL", ids).
+		Updates(map[string]interface{}{"handled_by": handledBy, "handled_at": &now, "remark": remark}).Error
+}
+
+// RunAlertEngine 启动告警规则引擎：注册为操作日志的观察者，在后台逐条评估规则
+// ctx 取消后停止评估，发送完队列中的通知再返回
+func RunAlertEngine(ctx context.Context) {
+	if err := alertEngine.reload(ctx); err != nil {
+		global.LV_LOG.Error("加载告警规则失败", zap.Error(err))
+	}
+	queueSize := global.LV_CONFIG.Alert.QueueSize
+	if queueSize <= 0 {
+		queueSize = alertQueueSize
+	}
+	alertEngine.queue = make(chan model.LvOperationLog, queueSize)
+	alertEngine.notifications = make(chan alertNotification, alertNotifyQueueSize)
+	logsink.AddObserver(alertEngine.observe)
+
+	var notifying sync.WaitGroup
+	for i := 0; i < alertNotifyWorkers; i++ {
+		notifying.Add(1)
+		go func() {
+			defer notifying.Done()
+			for n := range alertEngine.notifications {
+				notifyAlert(n.alert, n.channels)
+			}
+		}()
+	}
+
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+	var reportedDrops int64
+	for {
+		select {
+		case <-ctx.Done():
+			// 只有本协程发送通知，停止评估后可以安全关闭队列
+			close(alertEngine.notifications)
+			notifying.Wait()
+			return
+		case log := <-alertEngine.queue:
+			alertEngine.evaluate(log)
+		case <-ticker.C:
+			alertEngine.prune(time.Now())
+			if dropped := alertEngine.dropped.Load(); dropped > reportedDrops {
+				global.LV_LOG.Warn("告警队列已满，部分日志未评估", zap.Int64("dropped", dropped-reportedDrops))
+				reportedDrops = dropped
+			}
+		}
+	}
+}
+
+// alertEngine 告警规则引擎，规则和累计计数只保存在当前进程中
+var alertEngine = &alertRuleEngine{
+	hits:  make(map[string]*alertHits),
+	fired: make(map[string]time.Time),
+}
+
+type alertRuleEngine struct {
+	queue   chan model.LvOperationLog
+	dropped atomic.Int64
+
+	notifications chan alertNotification
+
+	mu    sync.Mutex
+	rules []*alertRule
+	// hits 按 规则ID|维度 累计窗口内的命中时间
+	hits map[string]*alertHits
+	// fired 按 规则ID|维度 记录上次告警时间，用于静默
+	fired map[string]time.Time
+}
+
+// alertNotification 待发送的告警通知
+type alertNotification struct {
+	alert    *model.LvAlert
+	channels []string
+}
+
+type alertHits struct {
+	times  []time.Time
+	window time.Duration
+}
+
+// alertRule 解析后的告警规则
+type alertRule struct {
+	model.LvAlertRule
+	modules   map[string]bool
+	actions   map[string]bool
+	methods   map[string]bool
+	window    time.Duration
+	cooldown  time.Duration
+	workStart int // 工作时间，当天的分钟数
+	workEnd   int
+	workDays  map[time.Weekday]bool
+	channels  []string
+}
+
+// observe 日志观察者，队列已满时丢弃，不阻塞请求
+func (e *alertRuleEngine) observe(log model.LvOperationLog) {
+	select {
+	case e.queue <- log:
+	default:
+		e.dropped.Add(1)
+	}
+}
+
+// reload 从数据库加载启用的规则
+func (e *alertRuleEngine) reload(ctx context.Context) error {
+	if global.LV_DB == nil {
+		return nil
+	}
+	var rules []model.LvAlertRule
+	if err := global.LV_DB.WithContext(ctx).Where("status = ?", 1).Find(&rules).Error; err != nil {
+		return err
+	}
+	compiled := make([]*alertRule, 0, len(rules))
+	for _, rule := range rules {
+		r, err := compileAlertRule(rule)
+		if err != nil {
+			global.LV_LOG.Error("告警规则无效", zap.Uint("ruleId", rule.ID), zap.Error(err))
+			continue
+		}
+		compiled = append(compiled, r)
+	}
+
+	e.mu.Lock()
+	e.rules = compiled
+	e.mu.Unlock()
+	return nil
+}
+
+// evaluate 逐条规则匹配日志，命中的规则生成告警
+func (e *alertRuleEngine) evaluate(log model.LvOperationLog) {
+	now := log.CreatedAt
+	if now.IsZero() {
+		now = time.Now()
+	}
+
+	var alerts []*model.LvAlert
+	var channels [][]string
+	e.mu.Lock()
+	for _, r := range e.rules {
+		if !r.match(&log, now) {
+			continue
+		}
+		key := strconv.FormatUint(uint64(r.ID), 10) + "|" + r.groupKey(&log)
+		count := 1
+		if r.Threshold > 1 {
+			hits := e.hits[key]
+			if hits == nil {
+				hits = &alertHits{window: r.window}
+				e.hits[key] = hits
+			}
+			hits.add(now)
+			if count = len(hits.times); count < r.Threshold {
+				continue
+			}
+		}
+		if last, ok := e.fired[key]; ok && now.Sub(last) < r.cooldown {
+			continue
+		}
+		e.fired[key] = now
+		// 触发后重新累计，下一次告警需要再次达到阈值
+		delete(e.hits, key)
+		alerts = append(alerts, newAlert(r, &log, count))
+		channels = append(channels, r.channels)
+	}
+	e.mu.Unlock()
+
+	for i, alert := range alerts {
+		// 日志可能来自任一租户，告警按日志的租户保存，context 中不带租户
+		if err := global.LV_DB.Create(alert).Error; err != nil {
+			global.LV_LOG.Error("保存告警失败", zap.String("rule", alert.RuleName), zap.Error(err))
+			continue
+		}
+		if len(channels[i]) == 0 {
+			continue
+		}
+		select {
+		case e.notifications <- alertNotification{alert: alert, channels: channels[i]}:
+		default:
+			global.LV_LOG.Warn("告警通知队列已满，未发送通知", zap.Uint("alertId", alert.ID), zap.String("rule", alert.RuleName))
+		}
+	}
+}
+
+// prune 清理已过期的累计计数和静默记录
+func (e *alertRuleEngine) prune(now time.Time) {
+	cooldowns := make(map[string]time.Duration)
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	for _, r := range e.rules {
+		cooldowns[strconv.FormatUint(uint64(r.ID), 10)] = r.cooldown
+	}
+	for key, hits := range e.hits {
+		hits.trim(now)
+		if len(hits.times) == 0 {
+			delete(e.hits, key)
+		}
+	}
+	for key, last := range e.fired {
+		ruleId, _, _ := strings.Cut(key, "|")
+		if now.Sub(last) >= cooldowns[ruleId] {
+			delete(e.fired, key)
+		}
+	}
+}
+
+func (h *alertHits) add(t time.Time) {
+	h.times = append(h.times, t)
+	h.trim(t)
+}
+
+// trim 去掉窗口之外的命中
+func (h *alertHits) trim(now time.Time) {
+	i := 0
+	for i < len(h.times) && now.Sub(h.times[i]) > h.window {
+		i++
+	}
+	h.times = h.times[i:]
+}
+
+// compileAlertRule 校验并解析规则
+func compileAlertRule(rule model.LvAlertRule) (*alertRule, error) {
+	if strings.TrimSpace(rule.Name) == "" {
+		return nil, errors.New("请填写规则名称")
+	}
+	if _, ok := alertLevelNames[rule.Level]; !ok {
+		return nil, errors.New("告警级别只能为 info、warning 或 critical")
+	}
+	if rule.Result != "" && rule.Result != "success" && rule.Result != "failure" {
+		return nil, errors.New("请求结果只能为 success 或 failure")
+	}
+	if rule.GroupBy != "" && rule.GroupBy != "ip" && rule.GroupBy != "user" {
+		return nil, errors.New("累计维度只能为 ip 或 user")
+	}
+	if rule.MinItems < 0 {
+		return nil, errors.New("最少条数不能小于 0")
+	}
+
+	r := &alertRule{
+		LvAlertRule: rule,
+		modules:     alertValueSet(rule.Module, false),
+		actions:     alertValueSet(rule.Action, false),
+		methods:     alertValueSet(rule.Method, true),
+	}
+	if r.Threshold <= 0 {
+		r.Threshold = 1
+	}
+	if r.Threshold > 1 {
+		window, err := utils.ParseDuration(rule.Window)
+		if err != nil || window <= 0 {
+			return nil, errors.New("触发次数大于 1 时需要填写时间窗口，如 5m")
+		}
+		r.window = window
+	}
+	if rule.Cooldown != "" {
+		cooldown, err := utils.ParseDuration(rule.Cooldown)
+		if err != nil || cooldown < 0 {
+			return nil, errors.New("静默时间格式不正确，如 30m")
+		}
+		r.cooldown = cooldown
+	}
+
+	if rule.OffHours {
+		if err := r.parseWorkTime(); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, name := range strings.Split(rule.Channels, ",") {
+		if name = strings.TrimSpace(name); name == "" {
+			continue
+		}
+		if _, ok := GetAlertNotifier(name); !ok {
+			return nil, fmt.Errorf("通知渠道 %s 不存在", name)
+		}
+		r.channels = append(r.channels, name)
+	}
+	return r, nil
+}
+
+// parseWorkTime 解析工作时间，未填写时为周一至周五 09:00-18:00
+func (r *alertRule) parseWorkTime() error {
+	hours := r.WorkHours
+	if hours == "" {
+		hours = "09:00-18:00"
+	}
+	start, end, ok := strings.Cut(hours, "-")
+	startTime, err1 := time.Parse("15:04", strings.TrimSpace(start))
+	endTime, err2 := time.Parse("15:04", strings.TrimSpace(end))
+	if !ok || err1 != nil || err2 != nil || !endTime.After(startTime) {
+		return errors.New("工作时间格式不正确，如 09:00-18:00")
+	}
+	r.workStart = startTime.Hour()*60 + startTime.Minute()
+	r.workEnd = endTime.Hour()*60 + endTime.Minute()
+
+	days := r.WorkDays
+	if days == "" {
+		days = "1,2,3,4,5"
+	}
+	r.workDays = make(map[time.Weekday]bool)
+	for _, day := range strings.Split(days, ",") {
+		d, err := strconv.Atoi(strings.TrimSpace(day))
+		if err != nil || d < 0 || d > 6 {
+			return errors.New("工作日格式不正确，0 为周日，如 1,2,3,4,5")
+		}
+		r.workDays[time.Weekday(d)] = true
+	}
+	return nil
+}
+
+// alertValueSet 逗号分隔的取值，为空时返回 nil 表示不限
+func alertValueSet(values string, upper bool) map[string]bool {
+	var set map[string]bool
+	for _, value := range strings.FieldsFunc(values, func(r rune) bool { return r == ',' || r == '，' }) {
+		if value = strings.TrimSpace(value); value == "" {
+			continue
+		}
+		if upper {
+			value = strings.ToUpper(value)
+		}
+		if set == nil {
+			set = make(map[string]bool)
+		}
+		set[value] = true
+	}
+	return set
+}
+
+// match 日志是否满足规则的全部条件
+func (r *alertRule) match(log *model.LvOperationLog, now time.Time) bool {
+	if r.modules != nil && !r.modules[log.Module] {
+		return false
+	}
+	if r.actions != nil && !r.actions[log.Action] {
+		return false
+	}
+	if r.methods != nil && !r.methods[log.Method] {
+		return false
+	}
+	if r.PathPrefix != "" && !strings.HasPrefix(log.Path, r.PathPrefix) {
+		return false
+	}
+	switch r.Result {
+	case "success":
+		if log.Status >= 400 {
+			return false
+		}
+	case "failure":
+		if log.Status < 400 {
+			return false
+		}
+	}
+	if r.MinItems > 0 && requestItemCount(log.Body) < r.MinItems {
+		return false
+	}
+	if r.OffHours && r.inWorkTime(now) {
+		return false
+	}
+	return true
+}
+
+// inWorkTime 是否在工作时间内
+func (r *alertRule) inWorkTime(t time.Time) bool {
+	minutes := t.Hour()*60 + t.Minute()
+	return r.workDays[t.Weekday()] && minutes >= r.workStart && minutes < r.workEnd
+}
+
+// groupKey 累计维度的取值，用户维度按租户区分同名用户
+func (r *alertRule) groupKey(log *model.LvOperationLog) string {
+	switch r.GroupBy {
+	case "ip":
+		return log.Ip
+	case "user":
+		return fmt.Sprintf("%d:%s", log.TenantId, log.Username)
+	default:
+		return ""
+	}
+}
+
+// requestItemCount 请求参数中 ids 数组的元素个数（批量操作）
+func requestItemCount(body string) int {
+	match := alertIdsPattern.FindStringSubmatch(body)
+	if match == nil {
+		return 0
+	}
+	count := 0
+	for _, id := range strings.Split(match[1], ",") {
+		if strings.TrimSpace(id) != "" {
+			count++
+		}
+	}
+	return count
+}
+
+// newAlert 根据命中的规则和触发日志生成告警
+func newAlert(r *alertRule, log *model.LvOperationLog, count int) *model.LvAlert {
+	subject := "用户 " + log.Username
+	if r.GroupBy == "ip" || log.Username == "" {
+		subject = "IP " + log.Ip
+	}
+	message := fmt.Sprintf("%s 执行「%s / %s」", subject, log.Module, log.Action)
+	if count > 1 {
+		message += fmt.Sprintf("，%s 内累计 %d 次", r.Window, count)
+	}
+	if r.MinItems > 0 {
+		message += fmt.Sprintf("，单次操作 %d 条", requestItemCount(log.Body))
+	}
+	if r.OffHours {
+		message += "，发生在工作时间以外"
+	}
+
+	return &model.LvAlert{
+		TenantId: log.TenantId,
+		RuleId:   r.ID,
+		RuleName: r.Name,
+		Level:    r.Level,
+		Message:  message,
+		Count:    count,
+		UserId:   log.UserId,
+		Username: log.Username,
+		Ip:       log.Ip,
+		Method:   log.Method,
+		Path:     log.Path,
+		Module:   log.Module,
+		Action:   log.Action,
+		Status:   log.Status,
+	}
+}
+
+// notifyAlert 按规则选择的渠道发送通知，记录发送结果
+func notifyAlert(alert *model.LvAlert, channels []string) {
+	if len(channels) == 0 {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	var notified, failures []string
+	for _, name := range channels {
+		notifier, ok := GetAlertNotifier(name)
+		if !ok || !notifier.Enabled() {
+			continue
+		}
+		if err := notifier.Notify(ctx, alert); err != nil {
+			global.LV_LOG.Error("发送告警通知失败", zap.String("channel", name), zap.Uint("alertId", alert.ID), zap.Error(err))
+			failures = append(failures, name+": "+err.Error())
+			continue
+		}
+		notified = append(notified, name)
+	}
+
+	notifyError := []rune(strings.Join(failures, "；"))
+	if len(notifyError) > 500 {
+		notifyError = notifyError[:500]
+	}
+	if err := global.LV_DB.Model(alert).UpdateColumns(map[string]interface{}{
+		"notified":     strings.Join(notified, ","),
+		"notify_error": string(notifyError),
+	}).Error; err != nil {
+		global.LV_LOG.Error("更新告警通知结果失败", zap.Uint("alertId", alert.ID), zap.Error(err))
+	}
+}
@@ -0,0 +1,242 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"go-lv-vue-admin/internal/global"
+	"go-lv-vue-admin/internal/model"
+	"go-lv-vue-admin/pkg/utils"
+	"mime"
+	"net"
+	"net/http"
+	"net/smtp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// AlertNotifier 告警通知渠道，规则的 Channels 按 Name 选择渠道
+// 告警记录本身即站内通知（告警中心和顶部提醒），无需单独的渠道
+type AlertNotifier interface {
+	Name() string
+	// Enabled 渠道是否已配置，未配置的渠道不发送
+	Enabled() bool
+	Notify(ctx context.Context, alert *model.LvAlert) error
+}
+
+var (
+	alertNotifiers   = make(map[string]AlertNotifier)
+	alertNotifiersMu sync.RWMutex
+)
+
+func init() {
+	RegisterAlertNotifier(webhookNotifier{})
+	RegisterAlertNotifier(emailNotifier{})
+}
+
+// RegisterAlertNotifier 注册通知渠道，同名渠道会被替换（如接入钉钉、企业微信）
+func RegisterAlertNotifier(n AlertNotifier) {
+	alertNotifiersMu.Lock()
+	defer alertNotifiersMu.Unlock()
+	alertNotifiers[n.Name()] = n
+}
+
+// GetAlertNotifier 按名称获取通知渠道
+func GetAlertNotifier(name string) (AlertNotifier, bool) {
+	alertNotifiersMu.RLock()
+	defer alertNotifiersMu.RUnlock()
+	n, ok := alertNotifiers[name]
+	return n, ok
+}
+
+// AlertChannel 通知渠道及其配置状态，用于规则编辑时选择
+type AlertChannel struct {
+	Name    string `json:"name"`
+	Enabled bool   `json:"enabled"`
+}
+
+// AlertChannels 已注册的通知渠道
+func AlertChannels() []AlertChannel {
+	alertNotifiersMu.RLock()
+	defer alertNotifiersMu.RUnlock()
+	channels := make([]AlertChannel, 0, len(alertNotifiers))
+	for name, n := range alertNotifiers {
+		channels = append(channels, AlertChannel{Name: name, Enabled: n.Enabled()})
+	}
+	sort.Slice(channels, func(i, j int) bool { return channels[i].Name < channels[j].Name })
+	return channels
+}
+
+// alertLevelNames 告警级别的中文名称
+var alertLevelNames = map[string]string{
+	model.AlertInfo:     "提示",
+	model.AlertWarning:  "警告",
+	model.AlertCritical: "严重",
+}
+
+// alertTitle 通知标题
+func alertTitle(alert *model.LvAlert) string {
+	level := alertLevelNames[alert.Level]
+	if level == "" {
+		level = alert.Level
+	}
+	return fmt.Sprintf("[安全告警][%s] %s", level, alert.RuleName)
+}
+
+// alertText 通知正文
+func alertText(alert *model.LvAlert) string {
+	lines := []string{
+		alert.Message,
+		"",
+		"用户: " + alert.Username,
+		"IP: " + alert.Ip,
+		"操作: " + alert.Module + " / " + alert.Action,
+		"请求: " + alert.Method + " " + alert.Path,
+		"状态码: " + strconv.Itoa(alert.Status),
+		"时间: " + alert.CreatedAt.Format("2006-01-02 15:04:05"),
+	}
+	return strings.Join(lines, "\n")
+}
+
+// webhookNotifier 以 JSON POST 推送告警，配置 Secret 时在 X-Signature 头附带请求体的 HMAC-SHA256 签名
+type webhookNotifier struct{}
+
+func (webhookNotifier) Name() string {
+	return "webhook"
+}
+
+func (webhookNotifier) Enabled() bool {
+	return global.LV_CONFIG.Alert.Webhook.Url != ""
+}
+
+func (webhookNotifier) Notify(ctx context.Context, alert *model.LvAlert) error {
+	cfg := global.LV_CONFIG.Alert.Webhook
+	body, err := json.Marshal(map[string]interface{}{
+		"title": alertTitle(alert),
+		"text":  alertText(alert),
+		"alert": alert,
+	})
+	if err != nil {
+		return err
+	}
+
+	timeout, err := utils.ParseDuration(cfg.Timeout)
+	if err != nil || timeout <= 0 {
+		timeout = 5 * time.Second
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, cfg.Url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if cfg.Secret != "" {
+		mac := hmac.New(sha256.New, []byte(cfg.Secret))
+		mac.Write(body)
+		req.Header.Set("X-Signature", "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook 返回状态码 %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// emailNotifier 通过 SMTP 发送告警邮件
+type emailNotifier struct{}
+
+func (emailNotifier) Name() string {
+	return "email"
+}
+
+func (emailNotifier) Enabled() bool {
+	cfg := global.LV_CONFIG.Alert.Smtp
+	return cfg.Host != "" && cfg.From != "" && len(cfg.To) > 0
+}
+
+func (emailNotifier) Notify(ctx context.Context, alert *model.LvAlert) error {
+	cfg := global.LV_CONFIG.Alert.Smtp
+	port := cfg.Port
+	if port == 0 {
+		port = 465
+	}
+	addr := net.JoinHostPort(cfg.Host, strconv.Itoa(port))
+
+	var msg bytes.Buffer
+	msg.WriteString("From: " + cfg.From + "\r\n")
+	msg.WriteString("To: " + strings.Join(cfg.To, ", ") + "\r\n")
+	msg.WriteString("Subject: " + mime.BEncoding.Encode("UTF-8", alertTitle(alert)) + "\r\n")
+	msg.WriteString("Date: " + time.Now().Format(time.RFC1123Z) + "\r\n")
+	msg.WriteString("MIME-Version: 1.0\r\n")
+	msg.WriteString("Content-Type: text/plain; charset=UTF-8\r\n\r\n")
+	msg.WriteString(strings.ReplaceAll(alertText(alert), "\n", "\r\n"))
+
+	dialer := &net.Dialer{Timeout: 10 * time.Second}
+	var conn net.Conn
+	var err error
+	if port == 465 {
+		conn, err = tls.DialWithDialer(dialer, "tcp", addr, &tls.Config{ServerName: cfg.Host})
+	} else {
+		conn, err = dialer.DialContext(ctx, "tcp", addr)
+	}
+	if err != nil {
+		return err
+	}
+	// 整个发送过程的超时
+	conn.SetDeadline(time.Now().Add(30 * time.Second))
+
+	client, err := smtp.NewClient(conn, cfg.Host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer client.Close()
+
+	if port != 465 {
+		if ok, _ := client.Extension("STARTTLS"); ok {
+			if err := client.StartTLS(&tls.Config{ServerName: cfg.Host}); err != nil {
+				return err
+			}
+		}
+	}
+	if cfg.Username != "" {
+		if err := client.Auth(smtp.PlainAuth("", cfg.Username, cfg.Password, cfg.Host)); err != nil {
+			return err
+		}
+	}
+	if err := client.Mail(cfg.From); err != nil {
+		return err
+	}
+	for _, to := range cfg.To {
+		if err := client.Rcpt(to); err != nil {
+			return err
+		}
+	}
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(msg.Bytes()); err != nil {
+		w.Close()
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return client.Quit()
+}
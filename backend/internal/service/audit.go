@@ -0,0 +1,60 @@
+package service
+
+import (
+	"context"
+	"go-lv-vue-admin/internal/global"
+	"go-lv-vue-admin/internal/model"
+	"go-lv-vue-admin/pkg/utils"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type AuditService struct{}
+
+// GetAuditList 获取审计日志列表，entity、entityId 用于查询某条数据的变更历史，按操作人的数据范围过滤
+func (s *AuditService) GetAuditList(ctx context.Context, operatorId, activeRoleId uint, page, pageSize int, entity, entityId, username, action string) ([]model.LvAuditLog, int64, error) {
+	var logs []model.LvAuditLog
+	var total int64
+
+	db := global.LV_DB.WithContext(ctx).Model(&model.LvAuditLog{}).Scopes(DataScope(operatorId, activeRoleId, DataScopeColumns{User: "user_id"}))
+
+	if entity != "" {
+		db = db.Where("entity = ?", entity)
+	}
+	if entityId != "" {
+		db = db.Where("entity_id = ?", entityId)
+	}
+	if username != "" {
+		db = db.Where("username LIKE ?", "%"+username+"%")
+	}
+	if action != "" {
+		db = db.Where("action = ?", action)
+	}
+
+	db.Count(&total)
+
+	offset := (page - 1) * pageSize
+	err := db.Order("id DESC").Offset(offset).Limit(pageSize).Find(&logs).Error
+
+	return logs, total, err
+}
+
+// WriteAuditLogs 写入审计日志，操作人取自 tx 的 context（未设置时为系统操作）
+// 与 tx 在同一事务中写入，业务回滚时审计日志一并回滚；写入失败只记录错误，不影响业务
+func WriteAuditLogs(tx *gorm.DB, logs []model.LvAuditLog) {
+	if len(logs) == 0 {
+		return
+	}
+	operator, _ := utils.OperatorFromContext(tx.Statement.Context)
+	for i := range logs {
+		logs[i].UserId = operator.UserId
+		logs[i].Username = operator.Username
+		logs[i].ImpersonatorId = operator.ImpersonatorId
+		logs[i].ImpersonatorName = operator.ImpersonatorName
+		logs[i].Ip = operator.Ip
+	}
+	if err := tx.Session(&gorm.Session{NewDB: true, SkipHooks: true}).Create(&logs).Error; err != nil {
+		global.LV_LOG.Error("记录审计日志失败", zap.Error(err))
+	}
+}
@@ -0,0 +1,133 @@
+package service
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"go-lv-vue-admin/internal/global"
+	"go-lv-vue-admin/internal/model"
+	"time"
+
+	"github.com/go-ldap/ldap/v3"
+	"go.uber.org/zap"
+)
+
+// LdapAuthProvider LDAP 认证源：服务账号查找用户条目，再以用户 DN 和密码绑定校验
+type LdapAuthProvider struct{}
+
+func (p *LdapAuthProvider) Name() string {
+	return SourceLdap
+}
+
+func (p *LdapAuthProvider) Authenticate(ctx context.Context, username, password string) (*model.LvUser, error) {
+	identity, err := p.identify(username, password)
+	if err != nil {
+		return nil, err
+	}
+	cfg := global.LV_CONFIG.Auth.Ldap
+	return provisionUser(ctx, identity, cfg.RoleMappings, cfg.DefaultRole)
+}
+
+// identify 在目录中校验账号密码，返回用户信息和所属组
+func (p *LdapAuthProvider) identify(username, password string) (*ExternalIdentity, error) {
+	cfg := global.LV_CONFIG.Auth.Ldap
+	if !cfg.Enable {
+		return nil, ErrProviderDisabled
+	}
+	// 空密码在 LDAP 中会被视为匿名绑定而"成功"，必须拒绝
+	if username == "" || password == "" {
+		return nil, ErrInvalidCredentials
+	}
+
+	conn, err := p.dial()
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	if cfg.BindDn != "" {
+		if err := conn.Bind(cfg.BindDn, cfg.BindPassword); err != nil {
+			return nil, fmt.Errorf("ldap service bind failed: %w", err)
+		}
+	}
+
+	entry, err := p.searchUser(conn, username)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := conn.Bind(entry.DN, password); err != nil {
+		if ldap.IsErrorWithCode(err, ldap.LDAPResultInvalidCredentials) {
+			return nil, ErrInvalidCredentials
+		}
+		return nil, err
+	}
+
+	identity := &ExternalIdentity{
+		Source:   SourceLdap,
+		Username: username,
+		Nickname: entry.GetAttributeValue(cfg.NicknameAttr),
+		Email:    entry.GetAttributeValue(cfg.EmailAttr),
+		Phone:    entry.GetAttributeValue(cfg.PhoneAttr),
+	}
+	if cfg.GroupAttr != "" {
+		identity.Groups = entry.GetAttributeValues(cfg.GroupAttr)
+	}
+	return identity, nil
+}
+
+// dial 连接 LDAP 服务器，按配置启用 StartTLS
+func (p *LdapAuthProvider) dial() (*ldap.Conn, error) {
+	cfg := global.LV_CONFIG.Auth.Ldap
+	tlsConfig := &tls.Config{InsecureSkipVerify: cfg.InsecureSkipVerify}
+
+	conn, err := ldap.DialURL(cfg.Url, ldap.DialWithTLSConfig(tlsConfig))
+	if err != nil {
+		global.LV_LOG.Error("ldap dial failed", zap.String("url", cfg.Url), zap.Error(err))
+		return nil, errors.New("无法连接 LDAP 服务器")
+	}
+	conn.SetTimeout(10 * time.Second)
+
+	if cfg.StartTls {
+		if err := conn.StartTLS(tlsConfig); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("ldap start tls failed: %w", err)
+		}
+	}
+	return conn, nil
+}
+
+// searchUser 查找唯一的用户条目
+func (p *LdapAuthProvider) searchUser(conn *ldap.Conn, username string) (*ldap.Entry, error) {
+	cfg := global.LV_CONFIG.Auth.Ldap
+	filter := cfg.UserFilter
+	if filter == "" {
+		filter = "(uid=%s)"
+	}
+
+	attributes := []string{"dn"}
+	for _, attr := range []string{cfg.NicknameAttr, cfg.EmailAttr, cfg.PhoneAttr, cfg.GroupAttr} {
+		if attr != "" {
+			attributes = append(attributes, attr)
+		}
+	}
+
+	result, err := conn.Search(ldap.NewSearchRequest(
+		cfg.BaseDn,
+		ldap.ScopeWholeSubtree, ldap.NeverDerefAliases, 2, 10, false,
+		fmt.Sprintf(filter, ldap.EscapeFilter(username)),
+		attributes,
+		nil,
+	))
+	if err != nil {
+		if ldap.IsErrorWithCode(err, ldap.LDAPResultSizeLimitExceeded) {
+			return nil, errors.New("LDAP 中存在多个同名用户")
+		}
+		return nil, err
+	}
+	if len(result.Entries) != 1 {
+		return nil, ErrInvalidCredentials
+	}
+	return result.Entries[0], nil
+}
@@ -0,0 +1,239 @@
+package service
+
+import (
+	"errors"
+	"go-lv-vue-admin/internal/config"
+	"go-lv-vue-admin/internal/global"
+	"net"
+	"reflect"
+	"strings"
+	"testing"
+
+	ber "github.com/go-asn1-ber/asn1-ber"
+	"github.com/go-ldap/ldap/v3"
+	"go.uber.org/zap"
+)
+
+// fakeLdapEntry 目录中的一个条目
+type fakeLdapEntry struct {
+	dn       string
+	password string
+	attrs    map[string][]string
+}
+
+// fakeLdapServer 进程内的最小 LDAP 服务，只实现简单绑定和按等值过滤的搜索
+type fakeLdapServer struct {
+	listener net.Listener
+	entries  []fakeLdapEntry
+}
+
+func newFakeLdapServer(t *testing.T, entries ...fakeLdapEntry) *fakeLdapServer {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	s := &fakeLdapServer{listener: listener, entries: entries}
+	go s.serve()
+	t.Cleanup(func() { listener.Close() })
+	return s
+}
+
+func (s *fakeLdapServer) url() string {
+	return "ldap://" + s.listener.Addr().String()
+}
+
+func (s *fakeLdapServer) serve() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		go s.handle(conn)
+	}
+}
+
+func (s *fakeLdapServer) handle(conn net.Conn) {
+	defer conn.Close()
+	for {
+		packet, err := ber.ReadPacket(conn)
+		if err != nil || len(packet.Children) < 2 {
+			return
+		}
+		messageId := packet.Children[0].Value.(int64)
+		op := packet.Children[1]
+
+		switch op.Tag {
+		case ldap.ApplicationBindRequest:
+			dn := op.Children[1].Data.String()
+			password := op.Children[2].Data.String()
+			conn.Write(ldapMessage(messageId, ldapResult(ldap.ApplicationBindResponse, s.bind(dn, password))).Bytes())
+		case ldap.ApplicationSearchRequest:
+			filter, _ := ldap.DecompileFilter(op.Children[6])
+			for _, entry := range s.entries {
+				if entry.matches(filter) {
+					conn.Write(ldapMessage(messageId, entry.packet()).Bytes())
+				}
+			}
+			conn.Write(ldapMessage(messageId, ldapResult(ldap.ApplicationSearchResultDone, ldap.LDAPResultSuccess)).Bytes())
+		default:
+			// Unbind 等其他请求直接断开
+			return
+		}
+	}
+}
+
+// bind 匿名绑定成功，其余按条目密码校验
+func (s *fakeLdapServer) bind(dn, password string) uint16 {
+	if dn == "" && password == "" {
+		return ldap.LDAPResultSuccess
+	}
+	for _, entry := range s.entries {
+		if strings.EqualFold(entry.dn, dn) && entry.password == password {
+			return ldap.LDAPResultSuccess
+		}
+	}
+	return ldap.LDAPResultInvalidCredentials
+}
+
+// matches 只支持 (attr=value) 形式的过滤
+func (e fakeLdapEntry) matches(filter string) bool {
+	attr, value, ok := strings.Cut(strings.Trim(filter, "()"), "=")
+	if !ok {
+		return false
+	}
+	for _, v := range e.attrs[attr] {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
+
+func (e fakeLdapEntry) packet() *ber.Packet {
+	op := ber.Encode(ber.ClassApplication, ber.TypeConstructed, ldap.ApplicationSearchResultEntry, nil, "Search Result Entry")
+	op.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, e.dn, "Object Name"))
+	attributes := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "Attributes")
+	for name, values := range e.attrs {
+		attr := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "Attribute")
+		attr.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, name, "Type"))
+		vals := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSet, nil, "Values")
+		for _, value := range values {
+			vals.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, value, "Value"))
+		}
+		attr.AppendChild(vals)
+		attributes.AppendChild(attr)
+	}
+	op.AppendChild(attributes)
+	return op
+}
+
+func ldapMessage(messageId int64, op *ber.Packet) *ber.Packet {
+	packet := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "LDAP Response")
+	packet.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagInteger, messageId, "Message ID"))
+	packet.AppendChild(op)
+	return packet
+}
+
+func ldapResult(tag ber.Tag, code uint16) *ber.Packet {
+	op := ber.Encode(ber.ClassApplication, ber.TypeConstructed, tag, nil, "Result")
+	op.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagEnumerated, int64(code), "Result Code"))
+	op.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, "", "Matched DN"))
+	op.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, "", "Diagnostic Message"))
+	return op
+}
+
+// setupLdap 启动 fake 目录并将 LDAP 配置指向它
+func setupLdap(t *testing.T) {
+	t.Helper()
+	server := newFakeLdapServer(t,
+		fakeLdapEntry{dn: "cn=admin,dc=example,dc=org", password: "admin-secret"},
+		fakeLdapEntry{
+			dn:       "uid=alice,ou=people,dc=example,dc=org",
+			password: "alice-secret",
+			attrs: map[string][]string{
+				"uid":      {"alice"},
+				"cn":       {"Alice"},
+				"mail":     {"alice@example.org"},
+				"mobile":   {"13800000000"},
+				"memberOf": {"cn=admins,ou=groups,dc=example,dc=org", "cn=staff,ou=groups,dc=example,dc=org"},
+			},
+		},
+	)
+
+	oldConfig, oldLog := global.LV_CONFIG, global.LV_LOG
+	t.Cleanup(func() { global.LV_CONFIG, global.LV_LOG = oldConfig, oldLog })
+	global.LV_LOG = zap.NewNop()
+	global.LV_CONFIG.Auth.Ldap = config.Ldap{
+		Enable:       true,
+		Url:          server.url(),
+		BindDn:       "cn=admin,dc=example,dc=org",
+		BindPassword: "admin-secret",
+		BaseDn:       "dc=example,dc=org",
+		UserFilter:   "(uid=%s)",
+		NicknameAttr: "cn",
+		EmailAttr:    "mail",
+		PhoneAttr:    "mobile",
+		GroupAttr:    "memberOf",
+	}
+}
+
+func TestLdapIdentify(t *testing.T) {
+	setupLdap(t)
+	p := &LdapAuthProvider{}
+
+	identity, err := p.identify("alice", "alice-secret")
+	if err != nil {
+		t.Fatalf("identify: %v", err)
+	}
+	want := &ExternalIdentity{
+		Source:   SourceLdap,
+		Username: "alice",
+		Nickname: "Alice",
+		Email:    "alice@example.org",
+		Phone:    "13800000000",
+		Groups:   []string{"cn=admins,ou=groups,dc=example,dc=org", "cn=staff,ou=groups,dc=example,dc=org"},
+	}
+	if !reflect.DeepEqual(identity, want) {
+		t.Fatalf("identity = %+v, want %+v", identity, want)
+	}
+	if !groupMatches(identity.Groups, "admins") {
+		t.Fatal("memberOf DN should match group by CN")
+	}
+}
+
+func TestLdapIdentifyRejectsBadCredentials(t *testing.T) {
+	setupLdap(t)
+	p := &LdapAuthProvider{}
+
+	cases := map[string][2]string{
+		"wrong password": {"alice", "wrong"},
+		"unknown user":   {"bob", "alice-secret"},
+		// 空密码会被 LDAP 当作匿名绑定，必须在本地拒绝
+		"empty password": {"alice", ""},
+	}
+	for name, c := range cases {
+		if _, err := p.identify(c[0], c[1]); !errors.Is(err, ErrInvalidCredentials) {
+			t.Errorf("%s: err = %v, want ErrInvalidCredentials", name, err)
+		}
+	}
+}
+
+func TestLdapIdentifyServiceBindFailure(t *testing.T) {
+	setupLdap(t)
+	global.LV_CONFIG.Auth.Ldap.BindPassword = "wrong"
+
+	_, err := (&LdapAuthProvider{}).identify("alice", "alice-secret")
+	if err == nil || errors.Is(err, ErrInvalidCredentials) {
+		t.Fatalf("err = %v, want service bind error", err)
+	}
+}
+
+func TestLdapIdentifyDisabled(t *testing.T) {
+	setupLdap(t)
+	global.LV_CONFIG.Auth.Ldap.Enable = false
+
+	if _, err := (&LdapAuthProvider{}).identify("alice", "alice-secret"); !errors.Is(err, ErrProviderDisabled) {
+		t.Fatalf("err = %v, want ErrProviderDisabled", err)
+	}
+}
@@ -0,0 +1,198 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go-lv-vue-admin/internal/global"
+	"go-lv-vue-admin/internal/model"
+	"go-lv-vue-admin/pkg/utils"
+	"sync"
+	"time"
+
+	"github.com/coreos/go-oidc/v3/oidc"
+	"golang.org/x/oauth2"
+)
+
+// oidcStateTTL 授权请求（state）有效期
+const oidcStateTTL = 10 * time.Minute
+
+// oidcAuthRequest 发起授权时保存的 PKCE verifier、nonce 与登录租户，以 state 为键
+type oidcAuthRequest struct {
+	verifier  string
+	nonce     string
+	tenantId  uint
+	expiresAt time.Time
+}
+
+var (
+	oidcRequests sync.Map
+
+	oidcProviderMu     sync.Mutex
+	oidcProviderCached *oidc.Provider
+)
+
+// OidcAuthProvider OIDC 授权码模式认证源（带 PKCE）
+type OidcAuthProvider struct{}
+
+func (p *OidcAuthProvider) Name() string {
+	return SourceOidc
+}
+
+// AuthCodeURL 生成授权地址，前端跳转后由认证中心回调到 redirect_url，回调后用户创建在 tenantId 租户内
+func (p *OidcAuthProvider) AuthCodeURL(ctx context.Context, tenantId uint) (string, string, error) {
+	oauthConfig, _, err := p.oauthConfig(ctx)
+	if err != nil {
+		return "", "", err
+	}
+
+	state, err := utils.RandomToken(24)
+	if err != nil {
+		return "", "", err
+	}
+	nonce, err := utils.RandomToken(24)
+	if err != nil {
+		return "", "", err
+	}
+	verifier := oauth2.GenerateVerifier()
+
+	p.pruneRequests()
+	oidcRequests.Store(state, &oidcAuthRequest{
+		verifier:  verifier,
+		nonce:     nonce,
+		tenantId:  tenantId,
+		expiresAt: time.Now().Add(oidcStateTTL),
+	})
+
+	url := oauthConfig.AuthCodeURL(state, oidc.Nonce(nonce), oauth2.S256ChallengeOption(verifier))
+	return url, state, nil
+}
+
+// Exchange 用授权码换取 ID Token 并同步本地用户
+func (p *OidcAuthProvider) Exchange(ctx context.Context, code, state string) (*model.LvUser, error) {
+	identity, tenantId, err := p.identify(ctx, code, state)
+	if err != nil {
+		return nil, err
+	}
+	if global.LV_CONFIG.Tenant.Enable {
+		ctx = utils.WithTenant(ctx, tenantId)
+	}
+	cfg := global.LV_CONFIG.Auth.Oidc
+	return provisionUser(ctx, identity, cfg.RoleMappings, cfg.DefaultRole)
+}
+
+// identify 用授权码换取并校验 ID Token，返回用户信息和发起授权时的租户
+func (p *OidcAuthProvider) identify(ctx context.Context, code, state string) (*ExternalIdentity, uint, error) {
+	value, ok := oidcRequests.LoadAndDelete(state)
+	if !ok || time.Now().After(value.(*oidcAuthRequest).expiresAt) {
+		return nil, 0, errors.New("授权请求已失效，请重新登录")
+	}
+	authRequest := value.(*oidcAuthRequest)
+
+	oauthConfig, provider, err := p.oauthConfig(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	token, err := oauthConfig.Exchange(ctx, code, oauth2.VerifierOption(authRequest.verifier))
+	if err != nil {
+		return nil, 0, fmt.Errorf("oidc code exchange failed: %w", err)
+	}
+	rawIdToken, ok := token.Extra("id_token").(string)
+	if !ok {
+		return nil, 0, errors.New("认证中心未返回 id_token")
+	}
+
+	cfg := global.LV_CONFIG.Auth.Oidc
+	idToken, err := provider.Verifier(&oidc.Config{ClientID: cfg.ClientId}).Verify(ctx, rawIdToken)
+	if err != nil {
+		return nil, 0, fmt.Errorf("oidc id token invalid: %w", err)
+	}
+	if idToken.Nonce != authRequest.nonce {
+		return nil, 0, errors.New("id_token nonce 不匹配")
+	}
+
+	var claims map[string]interface{}
+	if err := idToken.Claims(&claims); err != nil {
+		return nil, 0, err
+	}
+
+	usernameClaim := cfg.UsernameClaim
+	if usernameClaim == "" {
+		usernameClaim = "preferred_username"
+	}
+	identity := &ExternalIdentity{
+		Source:   SourceOidc,
+		Username: claimString(claims, usernameClaim),
+		Nickname: claimString(claims, "name"),
+		Email:    claimString(claims, "email"),
+		Phone:    claimString(claims, "phone_number"),
+		Groups:   claimStrings(claims, cfg.GroupsClaim),
+	}
+	return identity, authRequest.tenantId, nil
+}
+
+// oauthConfig 通过 issuer 自动发现端点，发现结果缓存复用
+func (p *OidcAuthProvider) oauthConfig(ctx context.Context) (*oauth2.Config, *oidc.Provider, error) {
+	cfg := global.LV_CONFIG.Auth.Oidc
+	if !cfg.Enable {
+		return nil, nil, ErrProviderDisabled
+	}
+
+	oidcProviderMu.Lock()
+	defer oidcProviderMu.Unlock()
+	if oidcProviderCached == nil {
+		provider, err := oidc.NewProvider(ctx, cfg.Issuer)
+		if err != nil {
+			return nil, nil, fmt.Errorf("oidc discovery failed: %w", err)
+		}
+		oidcProviderCached = provider
+	}
+
+	scopes := cfg.Scopes
+	if len(scopes) == 0 {
+		scopes = []string{oidc.ScopeOpenID, "profile", "email"}
+	}
+	return &oauth2.Config{
+		ClientID:     cfg.ClientId,
+		ClientSecret: cfg.ClientSecret,
+		RedirectURL:  cfg.RedirectUrl,
+		Endpoint:     oidcProviderCached.Endpoint(),
+		Scopes:       scopes,
+	}, oidcProviderCached, nil
+}
+
+// pruneRequests 清理过期的授权请求
+func (p *OidcAuthProvider) pruneRequests() {
+	now := time.Now()
+	oidcRequests.Range(func(key, value interface{}) bool {
+		if now.After(value.(*oidcAuthRequest).expiresAt) {
+			oidcRequests.Delete(key)
+		}
+		return true
+	})
+}
+
+func claimString(claims map[string]interface{}, key string) string {
+	if v, ok := claims[key].(string); ok {
+		return v
+	}
+	return ""
+}
+
+// claimStrings 组信息可能是字符串数组或单个字符串
+func claimStrings(claims map[string]interface{}, key string) []string {
+	switch v := claims[key].(type) {
+	case string:
+		return []string{v}
+	case []interface{}:
+		values := make([]string, 0, len(v))
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				values = append(values, s)
+			}
+		}
+		return values
+	}
+	return nil
+}
@@ -0,0 +1,300 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"go-lv-vue-admin/internal/config"
+	"go-lv-vue-admin/internal/global"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	testOidcClientId     = "go-lv-admin"
+	testOidcClientSecret = "client-secret"
+	testOidcKeyId        = "test-key"
+)
+
+// fakeOidcGrant 授权码对应的 PKCE challenge 与签发的 ID Token 内容
+type fakeOidcGrant struct {
+	challenge string
+	claims    jwt.MapClaims
+}
+
+// fakeOidcIssuer 基于 httptest 的最小 OIDC 认证中心：发现文档、JWKS 和授权码换 Token
+type fakeOidcIssuer struct {
+	server *httptest.Server
+	key    *rsa.PrivateKey
+
+	mu     sync.Mutex
+	grants map[string]fakeOidcGrant
+}
+
+func newFakeOidcIssuer(t *testing.T) *fakeOidcIssuer {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	issuer := &fakeOidcIssuer{key: key, grants: make(map[string]fakeOidcGrant)}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", issuer.discovery)
+	mux.HandleFunc("/keys", issuer.jwks)
+	mux.HandleFunc("/token", issuer.token)
+	issuer.server = httptest.NewServer(mux)
+	t.Cleanup(issuer.server.Close)
+	return issuer
+}
+
+func (f *fakeOidcIssuer) discovery(w http.ResponseWriter, r *http.Request) {
+	base := f.server.URL
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"issuer":                                base,
+		"authorization_endpoint":                base + "/auth",
+		"token_endpoint":                        base + "/token",
+		"jwks_uri":                              base + "/keys",
+		"response_types_supported":              []string{"code"},
+		"subject_types_supported":               []string{"public"},
+		"id_token_signing_alg_values_supported": []string{"RS256"},
+	})
+}
+
+func (f *fakeOidcIssuer) jwks(w http.ResponseWriter, r *http.Request) {
+	pub := f.key.PublicKey
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"keys": []map[string]string{{
+			"kty": "RSA",
+			"alg": "RS256",
+			"use": "sig",
+			"kid": testOidcKeyId,
+			"n":   base64.RawURLEncoding.EncodeToString(pub.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes()),
+		}},
+	})
+}
+
+// token 校验客户端凭据和 PKCE verifier 后签发 ID Token，授权码只能使用一次
+func (f *fakeOidcIssuer) token(w http.ResponseWriter, r *http.Request) {
+	r.ParseForm()
+	clientId, clientSecret, ok := r.BasicAuth()
+	if !ok {
+		clientId, clientSecret = r.PostForm.Get("client_id"), r.PostForm.Get("client_secret")
+	}
+	if clientId != testOidcClientId || clientSecret != testOidcClientSecret {
+		http.Error(w, `{"error":"invalid_client"}`, http.StatusUnauthorized)
+		return
+	}
+
+	f.mu.Lock()
+	grant, ok := f.grants[r.PostForm.Get("code")]
+	delete(f.grants, r.PostForm.Get("code"))
+	f.mu.Unlock()
+	if !ok || pkceChallenge(r.PostForm.Get("code_verifier")) != grant.challenge {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"error":"invalid_grant"}`))
+		return
+	}
+
+	claims := jwt.MapClaims{
+		"iss": f.server.URL,
+		"aud": testOidcClientId,
+		"sub": "user-1",
+		"iat": time.Now().Unix(),
+		"exp": time.Now().Add(time.Hour).Unix(),
+	}
+	for k, v := range grant.claims {
+		claims[k] = v
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = testOidcKeyId
+	idToken, err := token.SignedString(f.key)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"access_token": "access-token",
+		"token_type":   "Bearer",
+		"expires_in":   3600,
+		"id_token":     idToken,
+	})
+}
+
+// authorize 模拟用户在认证中心登录：从授权地址中取出 state、nonce 和 challenge，发放授权码
+func (f *fakeOidcIssuer) authorize(t *testing.T, authURL, code string, claims jwt.MapClaims) (state string) {
+	t.Helper()
+	u, err := url.Parse(authURL)
+	if err != nil {
+		t.Fatalf("parse auth url: %v", err)
+	}
+	query := u.Query()
+	if !strings.HasPrefix(authURL, f.server.URL+"/auth?") {
+		t.Fatalf("auth url = %s, want issuer authorization endpoint", authURL)
+	}
+	if query.Get("code_challenge_method") != "S256" || query.Get("client_id") != testOidcClientId {
+		t.Fatalf("auth url query = %v", query)
+	}
+
+	grantClaims := jwt.MapClaims{"nonce": query.Get("nonce")}
+	for k, v := range claims {
+		grantClaims[k] = v
+	}
+	f.mu.Lock()
+	f.grants[code] = fakeOidcGrant{challenge: query.Get("code_challenge"), claims: grantClaims}
+	f.mu.Unlock()
+	return query.Get("state")
+}
+
+func pkceChallenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// setupOidc 启动 fake 认证中心并将 OIDC 配置指向它
+func setupOidc(t *testing.T) *fakeOidcIssuer {
+	t.Helper()
+	issuer := newFakeOidcIssuer(t)
+
+	oldConfig := global.LV_CONFIG
+	resetProvider := func() {
+		oidcProviderMu.Lock()
+		oidcProviderCached = nil
+		oidcProviderMu.Unlock()
+	}
+	resetProvider()
+	t.Cleanup(func() {
+		global.LV_CONFIG = oldConfig
+		resetProvider()
+	})
+	global.LV_CONFIG.Auth.Oidc = config.Oidc{
+		Enable:        true,
+		Issuer:        issuer.server.URL,
+		ClientId:      testOidcClientId,
+		ClientSecret:  testOidcClientSecret,
+		RedirectUrl:   "http://localhost:5173/oidc/callback",
+		UsernameClaim: "preferred_username",
+		GroupsClaim:   "groups",
+	}
+	return issuer
+}
+
+var testOidcUserClaims = jwt.MapClaims{
+	"preferred_username": "alice",
+	"name":               "Alice",
+	"email":              "alice@example.org",
+	"groups":             []string{"admins", "staff"},
+}
+
+func TestOidcIdentify(t *testing.T) {
+	issuer := setupOidc(t)
+	p := &OidcAuthProvider{}
+	ctx := context.Background()
+
+	authURL, state, err := p.AuthCodeURL(ctx, 7)
+	if err != nil {
+		t.Fatalf("AuthCodeURL: %v", err)
+	}
+	if got := issuer.authorize(t, authURL, "code-1", testOidcUserClaims); got != state {
+		t.Fatalf("state in url = %q, want %q", got, state)
+	}
+
+	identity, tenantId, err := p.identify(ctx, "code-1", state)
+	if err != nil {
+		t.Fatalf("identify: %v", err)
+	}
+	want := &ExternalIdentity{
+		Source:   SourceOidc,
+		Username: "alice",
+		Nickname: "Alice",
+		Email:    "alice@example.org",
+		Groups:   []string{"admins", "staff"},
+	}
+	if !reflect.DeepEqual(identity, want) {
+		t.Fatalf("identity = %+v, want %+v", identity, want)
+	}
+	if tenantId != 7 {
+		t.Fatalf("tenantId = %d, want 7", tenantId)
+	}
+
+	// state 只能使用一次
+	if _, _, err := p.identify(ctx, "code-1", state); err == nil {
+		t.Fatal("reused state should be rejected")
+	}
+}
+
+func TestOidcIdentifyRejectsNonceMismatch(t *testing.T) {
+	issuer := setupOidc(t)
+	p := &OidcAuthProvider{}
+	ctx := context.Background()
+
+	authURL, state, err := p.AuthCodeURL(ctx, 0)
+	if err != nil {
+		t.Fatalf("AuthCodeURL: %v", err)
+	}
+	claims := jwt.MapClaims{"nonce": "forged"}
+	for k, v := range testOidcUserClaims {
+		claims[k] = v
+	}
+	issuer.authorize(t, authURL, "code-1", claims)
+
+	if _, _, err := p.identify(ctx, "code-1", state); err == nil || !strings.Contains(err.Error(), "nonce") {
+		t.Fatalf("err = %v, want nonce mismatch", err)
+	}
+}
+
+func TestOidcIdentifyRejectsWrongVerifier(t *testing.T) {
+	issuer := setupOidc(t)
+	p := &OidcAuthProvider{}
+	ctx := context.Background()
+
+	authURL, state, err := p.AuthCodeURL(ctx, 0)
+	if err != nil {
+		t.Fatalf("AuthCodeURL: %v", err)
+	}
+	issuer.authorize(t, authURL, "code-1", testOidcUserClaims)
+	// 授权码被另一次授权请求（不同的 PKCE verifier）拿去兑换
+	_, otherState, err := p.AuthCodeURL(ctx, 0)
+	if err != nil {
+		t.Fatalf("AuthCodeURL: %v", err)
+	}
+
+	if _, _, err := p.identify(ctx, "code-1", otherState); err == nil {
+		t.Fatal("code exchanged with another verifier should be rejected")
+	}
+	if _, _, err := p.identify(ctx, "code-1", state); err == nil {
+		t.Fatal("code is single use")
+	}
+}
+
+func TestClaimStrings(t *testing.T) {
+	claims := map[string]interface{}{
+		"single": "admins",
+		"list":   []interface{}{"admins", 1, "staff"},
+	}
+	if got := claimStrings(claims, "single"); !reflect.DeepEqual(got, []string{"admins"}) {
+		t.Errorf("single = %v", got)
+	}
+	if got := claimStrings(claims, "list"); !reflect.DeepEqual(got, []string{"admins", "staff"}) {
+		t.Errorf("list = %v", got)
+	}
+	if got := claimStrings(claims, "missing"); got != nil {
+		t.Errorf("missing = %v", got)
+	}
+}
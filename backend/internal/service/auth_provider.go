@@ -0,0 +1,248 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"go-lv-vue-admin/internal/config"
+	"go-lv-vue-admin/internal/global"
+	"go-lv-vue-admin/internal/model"
+	"go-lv-vue-admin/pkg/utils"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// 账号来源
+const (
+	SourceLocal = "local"
+	SourceLdap  = "ldap"
+	SourceOidc  = "oidc"
+)
+
+var (
+	ErrInvalidCredentials = errors.New("用户名或密码错误")
+	ErrProviderDisabled   = errors.New("认证源未启用")
+	ErrNoRoleMapped       = errors.New("未匹配到可用角色，禁止登录")
+)
+
+// AuthProvider 账号密码认证源，认证成功返回本地用户（外部账号首次登录时自动创建）
+// ctx 携带登录的租户，本地用户的查找和外部账号的创建都限定在该租户内
+type AuthProvider interface {
+	Name() string
+	Authenticate(ctx context.Context, username, password string) (*model.LvUser, error)
+}
+
+// ExternalIdentity 外部认证源返回的用户信息
+type ExternalIdentity struct {
+	Source   string
+	Username string
+	Nickname string
+	Email    string
+	Phone    string
+	Groups   []string
+}
+
+// authProviders 返回已启用的账号密码认证源，本地认证源始终位于首位
+func authProviders() []AuthProvider {
+	providers := []AuthProvider{&LocalAuthProvider{}}
+	if global.LV_CONFIG.Auth.Ldap.Enable {
+		providers = append(providers, &LdapAuthProvider{})
+	}
+	return providers
+}
+
+// findAuthProvider 按名称查找已启用的认证源
+func findAuthProvider(name string) AuthProvider {
+	for _, provider := range authProviders() {
+		if provider.Name() == name {
+			return provider
+		}
+	}
+	return nil
+}
+
+// LocalAuthProvider 本地账号（lv_users 中的 bcrypt 密码）
+type LocalAuthProvider struct{}
+
+func (p *LocalAuthProvider) Name() string {
+	return SourceLocal
+}
+
+func (p *LocalAuthProvider) Authenticate(ctx context.Context, username, password string) (*model.LvUser, error) {
+	var user model.LvUser
+	if err := global.LV_DB.WithContext(ctx).Where("username = ?", username).Preload("Role").First(&user).Error; err != nil {
+		return nil, ErrInvalidCredentials
+	}
+	if userSource(&user) != SourceLocal || !utils.CheckPassword(password, user.Password) {
+		return nil, ErrInvalidCredentials
+	}
+	return &user, nil
+}
+
+// userSource 账号来源，历史数据为空时视为本地账号
+func userSource(user *model.LvUser) string {
+	if user.Source == "" {
+		return SourceLocal
+	}
+	return user.Source
+}
+
+// provisionUser 外部账号登录时同步本地用户：首次登录自动创建，之后更新资料与映射的角色
+// 再次登录时只替换由映射管理的角色（role_mappings 和 default_role 中的角色），管理员在本地额外分配的角色保留
+func provisionUser(ctx context.Context, identity *ExternalIdentity, mappings []config.RoleMapping, defaultRole string) (*model.LvUser, error) {
+	if identity.Username == "" {
+		return nil, errors.New("认证源未返回用户名")
+	}
+	db := global.LV_DB.WithContext(ctx)
+
+	var user model.LvUser
+	err := db.Where("username = ?", identity.Username).First(&user).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	exists := err == nil
+	if exists && userSource(&user) != identity.Source {
+		return nil, errors.New("用户名已被其他来源的账号占用")
+	}
+
+	role, err := mapRole(ctx, identity.Groups, mappings, defaultRole)
+	if err != nil && !exists {
+		return nil, err
+	}
+
+	if exists {
+		updates := map[string]interface{}{
+			"nickname": identity.Nickname,
+			"email":    identity.Email,
+			"phone":    identity.Phone,
+		}
+		if err := db.Model(&model.LvUser{}).Where("id = ?", user.ID).Updates(updates).Error; err != nil {
+			return nil, err
+		}
+		// 目录为准：匹配到角色时同步映射的角色，否则保留现有角色
+		if role != nil {
+			managed, err := mappedRoleIds(ctx, mappings, defaultRole)
+			if err != nil {
+				return nil, err
+			}
+			if managed[user.RoleId] {
+				user.RoleId = role.ID
+			}
+			if err := SetUserRoles(db, &user, mergeMappedRoles(UserRoleIds(user.ID), managed, role.ID)); err != nil {
+				return nil, err
+			}
+		}
+	} else {
+		// 外部账号不使用本地密码，写入随机密码摘要占位
+		placeholder, err := utils.RandomToken(32)
+		if err != nil {
+			return nil, err
+		}
+		hashedPassword, err := utils.HashPassword(placeholder)
+		if err != nil {
+			return nil, err
+		}
+		now := time.Now()
+		user = model.LvUser{
+			Username:          identity.Username,
+			Password:          hashedPassword,
+			Nickname:          identity.Nickname,
+			Email:             identity.Email,
+			Phone:             identity.Phone,
+			Status:            1,
+			RoleId:            role.ID,
+			Source:            identity.Source,
+			PasswordChangedAt: &now,
+		}
+		if user.Nickname == "" {
+			user.Nickname = identity.Username
+		}
+		if err := db.Create(&user).Error; err != nil {
+			return nil, err
+		}
+		if err := SetUserRoles(db, &user, []uint{role.ID}); err != nil {
+			return nil, err
+		}
+	}
+	if err := SyncUserRoles(user.ID); err != nil {
+		return nil, err
+	}
+
+	if err := db.Preload("Role").First(&user, user.ID).Error; err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// mapRole 按配置顺序匹配目录组，未匹配时使用默认角色
+func mapRole(ctx context.Context, groups []string, mappings []config.RoleMapping, defaultRole string) (*model.LvRole, error) {
+	keyword := defaultRole
+	for _, mapping := range mappings {
+		if groupMatches(groups, mapping.Group) {
+			keyword = mapping.Role
+			break
+		}
+	}
+	if keyword == "" {
+		return nil, ErrNoRoleMapped
+	}
+
+	var role model.LvRole
+	if err := global.LV_DB.WithContext(ctx).Where("keyword = ?", keyword).First(&role).Error; err != nil {
+		return nil, errors.New("映射的角色不存在: " + keyword)
+	}
+	return &role, nil
+}
+
+// mappedRoleIds 由映射管理的角色 ID（role_mappings 与 default_role 引用的角色）
+func mappedRoleIds(ctx context.Context, mappings []config.RoleMapping, defaultRole string) (map[uint]bool, error) {
+	keywords := make([]string, 0, len(mappings)+1)
+	for _, mapping := range mappings {
+		keywords = append(keywords, mapping.Role)
+	}
+	if defaultRole != "" {
+		keywords = append(keywords, defaultRole)
+	}
+	var ids []uint
+	if len(keywords) > 0 {
+		if err := global.LV_DB.WithContext(ctx).Model(&model.LvRole{}).Where("keyword IN ?", keywords).Pluck("id", &ids).Error; err != nil {
+			return nil, err
+		}
+	}
+	managed := make(map[uint]bool, len(ids))
+	for _, id := range ids {
+		managed[id] = true
+	}
+	return managed, nil
+}
+
+// mergeMappedRoles 去掉用户现有角色中由映射管理的角色，加上本次映射到的角色
+func mergeMappedRoles(current []uint, managed map[uint]bool, mapped uint) []uint {
+	roleIds := []uint{mapped}
+	for _, id := range current {
+		if !managed[id] && id != mapped {
+			roleIds = append(roleIds, id)
+		}
+	}
+	return roleIds
+}
+
+// groupMatches 组名比较不区分大小写，同时支持完整 DN 与 CN
+func groupMatches(groups []string, target string) bool {
+	for _, group := range groups {
+		if strings.EqualFold(group, target) || strings.EqualFold(groupCN(group), target) {
+			return true
+		}
+	}
+	return false
+}
+
+// groupCN 从 "cn=admins,ou=groups,dc=example,dc=org" 中取出 "admins"
+func groupCN(dn string) string {
+	first := strings.SplitN(dn, ",", 2)[0]
+	if kv := strings.SplitN(first, "=", 2); len(kv) == 2 && strings.EqualFold(strings.TrimSpace(kv[0]), "cn") {
+		return strings.TrimSpace(kv[1])
+	}
+	return dn
+}
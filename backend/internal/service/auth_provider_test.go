@@ -0,0 +1,44 @@
+package service
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMergeMappedRoles(t *testing.T) {
+	// 1、2 由映射管理，10、11 为管理员在本地分配的角色
+	managed := map[uint]bool{1: true, 2: true}
+
+	cases := []struct {
+		name    string
+		current []uint
+		mapped  uint
+		want    []uint
+	}{
+		{"first login", nil, 1, []uint{1}},
+		{"keep local roles", []uint{1, 10, 11}, 1, []uint{1, 10, 11}},
+		{"replace mapped role", []uint{1, 10}, 2, []uint{2, 10}},
+		{"drop stale mapped roles", []uint{1, 2, 10}, 2, []uint{2, 10}},
+		{"mapped role also assigned locally", []uint{10, 2}, 2, []uint{2, 10}},
+	}
+	for _, c := range cases {
+		if got := mergeMappedRoles(c.current, managed, c.mapped); !reflect.DeepEqual(got, c.want) {
+			t.Errorf("%s: got %v, want %v", c.name, got, c.want)
+		}
+	}
+}
+
+func TestGroupMatches(t *testing.T) {
+	groups := []string{"cn=Admins,ou=groups,dc=example,dc=org", "staff"}
+
+	for _, target := range []string{"admins", "ADMINS", "cn=admins,ou=groups,dc=example,dc=org", "Staff"} {
+		if !groupMatches(groups, target) {
+			t.Errorf("groupMatches(%q) = false, want true", target)
+		}
+	}
+	for _, target := range []string{"ou=groups", "dc=example", "guests"} {
+		if groupMatches(groups, target) {
+			t.Errorf("groupMatches(%q) = true, want false", target)
+		}
+	}
+}
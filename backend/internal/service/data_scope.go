@@ -0,0 +1,112 @@
+package service
+
+import (
+	"go-lv-vue-admin/internal/global"
+	"go-lv-vue-admin/internal/model"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// 角色数据范围
+const (
+	DataScopeAll         = 1 // 全部数据
+	DataScopeDept        = 2 // 本部门
+	DataScopeDeptAndSub  = 3 // 本部门及以下
+	DataScopeSelf        = 4 // 仅本人
+	DataScopeCustomDepts = 5 // 自定义部门
+)
+
+// DataScopeColumns 数据范围过滤所用的列
+// Dept 为记录所属部门列；为空时通过 User 列关联 lv_users.dept_id 过滤
+// User 为记录所属用户（创建人）列，用于"仅本人"
+type DataScopeColumns struct {
+	Dept string
+	User string
+}
+
+// DataScope 按操作人当前生效角色的数据范围过滤（多角色取并集），供列表查询通过 db.Scopes 复用
+// activeRoleId 为会话切换到的角色，0 表示使用全部角色；无法确定操作人或角色时不返回任何数据
+func DataScope(operatorId, activeRoleId uint, columns DataScopeColumns) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		var operator model.LvUser
+		if err := global.LV_DB.Select("id", "dept_id", "role_id").First(&operator, operatorId).Error; err != nil {
+			return db.Where("1 = 0")
+		}
+		roles, err := EffectiveRoles(operator.ID, activeRoleId)
+		if err != nil || len(roles) == 0 {
+			return db.Where("1 = 0")
+		}
+
+		var deptIds []uint
+		self := false
+		for _, role := range roles {
+			if role.Keyword == "admin" {
+				return db
+			}
+			switch role.DataScope {
+			case DataScopeAll:
+				return db
+			case DataScopeDept:
+				// 未分配部门时与"本部门及以下"一致，不匹配任何部门
+				if operator.DeptId != 0 {
+					deptIds = append(deptIds, operator.DeptId)
+				}
+			case DataScopeDeptAndSub:
+				deptIds = append(deptIds, deptSubtreeIds(operator.DeptId)...)
+			case DataScopeCustomDepts:
+				var customIds []uint
+				global.LV_DB.Table("lv_role_depts").Where("lv_role_id = ?", role.ID).Pluck("lv_dept_id", &customIds)
+				deptIds = append(deptIds, customIds...)
+			case DataScopeSelf:
+				self = true
+			}
+		}
+
+		var conditions []string
+		var args []interface{}
+		if deptIds = uniqueIds(deptIds); len(deptIds) > 0 {
+			if columns.Dept != "" {
+				conditions = append(conditions, columns.Dept+" IN ?")
+				args = append(args, deptIds)
+			} else if columns.User != "" {
+				conditions = append(conditions, columns.User+" IN (?)")
+				args = append(args, global.LV_DB.Model(&model.LvUser{}).Select("id").Where("dept_id IN ?", deptIds))
+			}
+		}
+		if self && columns.User != "" {
+			conditions = append(conditions, columns.User+" = ?")
+			args = append(args, operator.ID)
+		}
+		if len(conditions) == 0 {
+			return db.Where("1 = 0")
+		}
+		return db.Where("("+strings.Join(conditions, " OR ")+")", args...)
+	}
+}
+
+// deptSubtreeIds 获取部门及其全部下级部门 ID
+func deptSubtreeIds(rootId uint) []uint {
+	if rootId == 0 {
+		return nil
+	}
+	var depts []model.LvDept
+	global.LV_DB.Select("id", "parent_id").Find(&depts)
+
+	children := make(map[uint][]uint, len(depts))
+	for _, dept := range depts {
+		children[dept.ParentId] = append(children[dept.ParentId], dept.ID)
+	}
+
+	ids := []uint{rootId}
+	visited := map[uint]bool{rootId: true}
+	for i := 0; i < len(ids); i++ {
+		for _, child := range children[ids[i]] {
+			if !visited[child] {
+				visited[child] = true
+				ids = append(ids, child)
+			}
+		}
+	}
+	return ids
+}
@@ -1,6 +1,7 @@
 package service
 
 import (
+	"context"
 	"go-lv-vue-admin/internal/global"
 	"go-lv-vue-admin/internal/model"
 )
@@ -53,16 +54,16 @@ func (s *DemoService) GetDemoList(page, pageSize int, filters map[string]interfa
 }
 
 // CreateDemo 创建
-func (s *DemoService) CreateDemo(demo *model.LvDemo) error {
-	return global.LV_DB.Create(demo).Error
+func (s *DemoService) CreateDemo(ctx context.Context, demo *model.LvDemo) error {
+	return global.LV_DB.WithContext(ctx).Create(demo).Error
 }
 
 // UpdateDemo 更新
-func (s *DemoService) UpdateDemo(demo *model.LvDemo) error {
-	return global.LV_DB.Model(demo).Updates(demo).Error
+func (s *DemoService) UpdateDemo(ctx context.Context, demo *model.LvDemo) error {
+	return global.LV_DB.WithContext(ctx).Model(demo).Updates(demo).Error
 }
 
 // DeleteDemo 删除
-func (s *DemoService) DeleteDemo(id uint) error {
-	return global.LV_DB.Delete(&model.LvDemo{}, id).Error
+func (s *DemoService) DeleteDemo(ctx context.Context, id uint) error {
+	return global.LV_DB.WithContext(ctx).Delete(&model.LvDemo{}, id).Error
 }
@@ -0,0 +1,195 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go-lv-vue-admin/internal/global"
+	"go-lv-vue-admin/internal/model"
+	"go-lv-vue-admin/internal/storage"
+	"go-lv-vue-admin/pkg/utils"
+	"io"
+	"os"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const (
+	exportBatchSize      = 500  // 每批从数据库读取的条数
+	exportAsyncThreshold = 5000 // 未配置 export.async_threshold 时的后台导出阈值
+)
+
+// exportSlots 限制同时执行的后台导出任务数，其余任务排队等待
+var exportSlots = make(chan struct{}, 2)
+
+// Export 一次导出：由各业务服务按列表接口的筛选条件和数据范围构造
+type Export struct {
+	Type    string // 导出类型，如 operation_log、user
+	Name    string // 文件名前缀
+	Headers []string
+	// Count 按筛选条件统计条数
+	Count func(ctx context.Context) (int64, error)
+	// Rows 按批读取数据，逐行回调
+	Rows func(ctx context.Context, write func(row []string) error) error
+}
+
+// Filename 生成导出文件名
+func (e Export) Filename(format string) string {
+	return fmt.Sprintf("%s_%s.%s", e.Name, time.Now().Format("20060102150405"), format)
+}
+
+type ExportService struct{}
+
+// AsyncThreshold 超过该条数时转为后台导出
+func (s *ExportService) AsyncThreshold() int64 {
+	if threshold := global.LV_CONFIG.Export.AsyncThreshold; threshold > 0 {
+		return int64(threshold)
+	}
+	return exportAsyncThreshold
+}
+
+// Write 将导出数据写入 w，返回写入的条数（不含表头）
+func (s *ExportService) Write(ctx context.Context, export Export, format string, w io.Writer) (int64, error) {
+	sheet, err := utils.NewSheetWriter(format, w)
+	if err != nil {
+		return 0, err
+	}
+	if err := sheet.WriteRow(export.Headers); err != nil {
+		return 0, err
+	}
+	var count int64
+	err = export.Rows(ctx, func(row []string) error {
+		count++
+		return sheet.WriteRow(row)
+	})
+	if err != nil {
+		return count, err
+	}
+	return count, sheet.Close()
+}
+
+// StartJob 创建后台导出任务，文件生成后上传到存储驱动
+// 任务沿用发起请求的租户、操作人等 context 信息，请求结束后继续执行
+func (s *ExportService) StartJob(ctx context.Context, userId uint, export Export, format string) (*model.LvExportJob, error) {
+	job := model.LvExportJob{
+		UserId:   userId,
+		Type:     export.Type,
+		Format:   format,
+		Filename: export.Filename(format),
+		Status:   model.ExportPending,
+	}
+	if err := global.LV_DB.WithContext(ctx).Create(&job).Error; err != nil {
+		return nil, err
+	}
+
+	ctx = context.WithoutCancel(ctx)
+	go func() {
+		exportSlots <- struct{}{}
+		defer func() { <-exportSlots }()
+		s.runJob(ctx, &job, export)
+	}()
+	return &job, nil
+}
+
+// SaveFile 同步生成文件并保存到存储驱动，记录为已完成的导出任务，通过导出任务下载（如导入校验报告）
+func (s *ExportService) SaveFile(ctx context.Context, userId uint, export Export, format string) (*model.LvExportJob, error) {
+	job := model.LvExportJob{
+		UserId:   userId,
+		Type:     export.Type,
+		Format:   format,
+		Filename: export.Filename(format),
+	}
+	key, count, size, err := s.exportToStorage(ctx, &job, export)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	job.Status = model.ExportSuccess
+	job.Key = key
+	job.Count = count
+	job.Size = size
+	job.FinishedAt = &now
+	if err := global.LV_DB.WithContext(ctx).Create(&job).Error; err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// runJob 执行导出任务：先写入临时文件，再上传到存储驱动
+func (s *ExportService) runJob(ctx context.Context, job *model.LvExportJob, export Export) {
+	update := func(values map[string]interface{}) {
+		if err := global.LV_DB.WithContext(ctx).Model(job).Updates(values).Error; err != nil {
+			global.LV_LOG.Error("更新导出任务失败", zap.Uint("jobId", job.ID), zap.Error(err))
+		}
+	}
+	update(map[string]interface{}{"status": model.ExportRunning})
+
+	key, count, size, err := s.exportToStorage(ctx, job, export)
+	now := time.Now()
+	if err != nil {
+		global.LV_LOG.Error("导出任务失败", zap.Uint("jobId", job.ID), zap.Error(err))
+		update(map[string]interface{}{"status": model.ExportFailed, "error": err.Error(), "finished_at": &now})
+		return
+	}
+	update(map[string]interface{}{
+		"status":      model.ExportSuccess,
+		"key":         key,
+		"count":       count,
+		"size":        size,
+		"finished_at": &now,
+	})
+}
+
+func (s *ExportService) exportToStorage(ctx context.Context, job *model.LvExportJob, export Export) (key string, count, size int64, err error) {
+	tmp, err := os.CreateTemp("", "export-*."+job.Format)
+	if err != nil {
+		return "", 0, 0, err
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	if count, err = s.Write(ctx, export, job.Format, tmp); err != nil {
+		return "", count, 0, err
+	}
+	if size, err = tmp.Seek(0, io.SeekCurrent); err != nil {
+		return "", count, 0, err
+	}
+	if _, err = tmp.Seek(0, io.SeekStart); err != nil {
+		return "", count, 0, err
+	}
+	if _, key, err = storage.GetDriver().UploadReader(tmp, job.Filename, size); err != nil {
+		return "", count, size, fmt.Errorf("上传导出文件失败: %w", err)
+	}
+	return key, count, size, nil
+}
+
+// GetJobList 获取当前用户的导出任务
+func (s *ExportService) GetJobList(ctx context.Context, userId uint, page, pageSize int) ([]model.LvExportJob, int64, error) {
+	var jobs []model.LvExportJob
+	var total int64
+
+	db := global.LV_DB.WithContext(ctx).Model(&model.LvExportJob{}).Where("user_id = ?", userId)
+	db.Count(&total)
+
+	offset := (page - 1) * pageSize
+	err := db.Order("id DESC").Offset(offset).Limit(pageSize).Find(&jobs).Error
+
+	return jobs, total, err
+}
+
+// OpenJobFile 读取导出任务的结果文件，只能下载自己发起的任务，调用方负责关闭
+func (s *ExportService) OpenJobFile(ctx context.Context, userId, id uint) (*model.LvExportJob, io.ReadCloser, error) {
+	var job model.LvExportJob
+	if err := global.LV_DB.WithContext(ctx).Where("user_id = ?", userId).First(&job, id).Error; err != nil {
+		return nil, nil, errors.New("导出任务不存在")
+	}
+	if job.Status != model.ExportSuccess {
+		return nil, nil, errors.New("导出文件尚未生成")
+	}
+	reader, err := storage.GetDriver().Download(job.Key)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &job, reader, nil
+}
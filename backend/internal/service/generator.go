@@ -2,6 +2,7 @@ package service
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"go-lv-vue-admin/internal/global"
 	"go-lv-vue-admin/internal/model"
@@ -48,6 +49,7 @@ type GenerateConfig struct {
 	PackageName  string       `json:"packageName"`  // 包名，如 blog
 	StructName   string       `json:"structName"`   // 结构体名，如 Article
 	HasDeletedAt bool         `json:"hasDeletedAt"` // 表是否有 deleted_at 字段
+	HasTenantId  bool         `json:"hasTenantId"`  // 是否按租户隔离（表有 tenant_id 字段或开启了多租户模式）
 	Columns      []ColumnInfo `json:"columns"`
 }
 
@@ -121,14 +123,31 @@ func (s *GeneratorService) GetTableColumns(tableName string) ([]ColumnInfo, erro
 
 // HasDeletedAtColumn 检查表是否有 deleted_at 字段
 func (s *GeneratorService) HasDeletedAtColumn(tableName string) bool {
+	return s.hasColumn(tableName, "deleted_at")
+}
+
+// HasTenantIdColumn 检查表是否有 tenant_id 字段
+func (s *GeneratorService) HasTenantIdColumn(tableName string) bool {
+	return s.hasColumn(tableName, "tenant_id")
+}
+
+func (s *GeneratorService) hasColumn(tableName, columnName string) bool {
 	dbName := extractDbName(global.LV_CONFIG.Database.Source)
 	var count int64
 	global.LV_DB.Raw(`SELECT COUNT(*) FROM information_schema.columns 
-		WHERE table_schema = ? AND table_name = ? AND column_name = 'deleted_at'`,
-		dbName, tableName).Scan(&count)
+		WHERE table_schema = ? AND table_name = ? AND column_name = ?`,
+		dbName, tableName, columnName).Scan(&count)
 	return count > 0
 }
 
+// addTenantIdColumn 多租户模式下为缺少 tenant_id 的表补充该字段，已有数据归属平台（0）
+func (s *GeneratorService) addTenantIdColumn(tableName string) error {
+	if s.HasTenantIdColumn(tableName) {
+		return nil
+	}
+	return global.LV_DB.Exec(fmt.Sprintf("ALTER TABLE `%s` ADD COLUMN `tenant_id` bigint unsigned NOT NULL DEFAULT 0 COMMENT '租户ID', ADD INDEX `idx_%s_tenant_id` (`tenant_id`)", tableName, tableName)).Error
+}
+
 // GenerateCode 生成代码
 func (s *GeneratorService) GenerateCode(config GenerateConfig) (map[string]string, error) {
 	result := make(map[string]string)
@@ -191,6 +210,9 @@ import "gorm.io/gorm"
 // {{.StructName}} {{.TableComment}}
 type {{.StructName}} struct {
 	gorm.Model
+{{- if .HasTenantId}}
+	TenantId uint ` + "`" + `json:"tenantId" gorm:"default:0;index;comment:租户ID"` + "`" + `
+{{- end}}
 {{- range .Columns}}
 {{- if not (isAutoField .ColumnName)}}
 	{{.GoField}} {{.GoType}} ` + "`" + `json:"{{.JsonField}}" gorm:"{{gormTag .}}"` + "`" + `{{if .ColumnComment}} // {{.ColumnComment}}{{end}}
@@ -201,6 +223,10 @@ type {{.StructName}} struct {
 func ({{.StructName}}) TableName() string {
 	return "{{.TableName}}"
 }
+
+func ({{.StructName}}) AuditEntity() string {
+	return "{{.ModuleName}}"
+}
 `
 	} else {
 		// 不使用 gorm.Model，手动定义字段
@@ -211,6 +237,9 @@ import "time"
 // {{.StructName}} {{.TableComment}}
 type {{.StructName}} struct {
 	ID        uint      ` + "`" + `json:"id" gorm:"primaryKey;autoIncrement"` + "`" + `
+{{- if .HasTenantId}}
+	TenantId  uint      ` + "`" + `json:"tenantId" gorm:"column:tenant_id;default:0;index;comment:租户ID"` + "`" + `
+{{- end}}
 {{- range .Columns}}
 {{- if not (isAutoField .ColumnName)}}
 	{{.GoField}} {{.GoType}} ` + "`" + `json:"{{.JsonField}}" gorm:"column:{{.ColumnName}}{{if .ColumnComment}};comment:{{.ColumnComment}}{{end}}"` + "`" + `{{if .ColumnComment}} // {{.ColumnComment}}{{end}}
@@ -223,6 +252,10 @@ type {{.StructName}} struct {
 func ({{.StructName}}) TableName() string {
 	return "{{.TableName}}"
 }
+
+func ({{.StructName}}) AuditEntity() string {
+	return "{{.ModuleName}}"
+}
 `
 	}
 	return s.executeTemplate(tmpl, config)
@@ -233,18 +266,19 @@ func (s *GeneratorService) generateService(config GenerateConfig) (string, error
 	tmpl := `package service
 
 import (
+	"context"
 	"go-lv-vue-admin/internal/global"
 	"go-lv-vue-admin/internal/model"
 )
 
 type {{.StructName}}Service struct{}
 
-// GetList 获取{{.TableComment}}列表
-func (s *{{.StructName}}Service) GetList(page, pageSize int{{range .Columns}}{{if .IsQuery}}, {{.JsonField}} string{{end}}{{end}}) ([]model.{{.StructName}}, int64, error) {
+// GetList 获取{{.TableComment}}列表{{if hasDataScope .Columns}}，按操作人的数据范围过滤{{end}}
+func (s *{{.StructName}}Service) GetList(ctx context.Context, operatorId, activeRoleId uint, page, pageSize int{{range .Columns}}{{if .IsQuery}}, {{.JsonField}} string{{end}}{{end}}) ([]model.{{.StructName}}, int64, error) {
 	var list []model.{{.StructName}}
 	var total int64
 
-	db := global.LV_DB.Model(&model.{{.StructName}}{})
+	db := global.LV_DB.WithContext(ctx).Model(&model.{{.StructName}}{}){{if hasDataScope .Columns}}.Scopes(DataScope(operatorId, activeRoleId, DataScopeColumns{Dept: "{{dataScopeDept .Columns}}", User: "{{dataScopeUser .Columns}}"})){{end}}
 {{range .Columns}}{{if .IsQuery}}
 	if {{.JsonField}} != "" {
 		db = db.Where("{{.ColumnName}} {{queryOp .QueryType}} ?", {{queryValue .}})
@@ -259,28 +293,28 @@ func (s *{{.StructName}}Service) GetList(page, pageSize int{{range .Columns}}{{i
 }
 
 // GetById 根据ID获取{{.TableComment}}
-func (s *{{.StructName}}Service) GetById(id uint) (*model.{{.StructName}}, error) {
+func (s *{{.StructName}}Service) GetById(ctx context.Context, id uint) (*model.{{.StructName}}, error) {
 	var item model.{{.StructName}}
-	err := global.LV_DB.First(&item, id).Error
+	err := global.LV_DB.WithContext(ctx).First(&item, id).Error
 	return &item, err
 }
 
 // Create 创建{{.TableComment}}
-func (s *{{.StructName}}Service) Create(item *model.{{.StructName}}) error {
-	return global.LV_DB.Create(item).Error
+func (s *{{.StructName}}Service) Create(ctx context.Context, item *model.{{.StructName}}) error {
+	return global.LV_DB.WithContext(ctx).Create(item).Error
 }
 
 // Update 更新{{.TableComment}}
-func (s *{{.StructName}}Service) Update(item *model.{{.StructName}}) error {
-	return global.LV_DB.Model(item).Updates(item).Error
+func (s *{{.StructName}}Service) Update(ctx context.Context, item *model.{{.StructName}}) error {
+	return global.LV_DB.WithContext(ctx).Model(item).Updates(item).Error
 }
 
 // Delete 删除{{.TableComment}}
-func (s *{{.StructName}}Service) Delete(id uint) error {
+func (s *{{.StructName}}Service) Delete(ctx context.Context, id uint) error {
 {{- if .HasDeletedAt}}
-	return global.LV_DB.Delete(&model.{{.StructName}}{}, id).Error
+	return global.LV_DB.WithContext(ctx).Delete(&model.{{.StructName}}{}, id).Error
 {{- else}}
-	return global.LV_DB.Unscoped().Delete(&model.{{.StructName}}{}, id).Error
+	return global.LV_DB.WithContext(ctx).Unscoped().Delete(&model.{{.StructName}}{}, id).Error
 {{- end}}
 }
 `
@@ -295,6 +329,7 @@ import (
 	"go-lv-vue-admin/internal/global"
 	"go-lv-vue-admin/internal/model"
 	"go-lv-vue-admin/internal/service"
+	"go-lv-vue-admin/pkg/utils"
 	"strconv"
 
 	"github.com/gin-gonic/gin"
@@ -313,7 +348,7 @@ func (a *{{.StructName}}Api) GetList(c *gin.Context) {
 	{{.JsonField}} := c.Query("{{.JsonField}}")
 {{end}}{{end}}
 
-	list, total, err := {{.ModuleName}}Service.GetList(page, pageSize{{range .Columns}}{{if .IsQuery}}, {{convertQueryParam .}}{{end}}{{end}})
+	list, total, err := {{.ModuleName}}Service.GetList(c.Request.Context(), utils.GetClaims(c).UserId, utils.GetClaims(c).ActiveRoleId, page, pageSize{{range .Columns}}{{if .IsQuery}}, {{convertQueryParam .}}{{end}}{{end}})
 	if err != nil {
 		global.LV_LOG.Error("获取{{.TableComment}}列表失败", zap.Error(err))
 		c.JSON(500, gin.H{"code": 7, "msg": "获取失败"})
@@ -330,7 +365,7 @@ func (a *{{.StructName}}Api) GetList(c *gin.Context) {
 // GetById 获取{{.TableComment}}详情
 func (a *{{.StructName}}Api) GetById(c *gin.Context) {
 	id, _ := strconv.Atoi(c.Param("id"))
-	item, err := {{.ModuleName}}Service.GetById(uint(id))
+	item, err := {{.ModuleName}}Service.GetById(c.Request.Context(), uint(id))
 	if err != nil {
 		c.JSON(500, gin.H{"code": 7, "msg": "获取失败"})
 		return
@@ -346,7 +381,7 @@ func (a *{{.StructName}}Api) Create(c *gin.Context) {
 		return
 	}
 
-	if err := {{.ModuleName}}Service.Create(&item); err != nil {
+	if err := {{.ModuleName}}Service.Create(c.Request.Context(), &item); err != nil {
 		global.LV_LOG.Error("创建{{.TableComment}}失败", zap.Error(err))
 		c.JSON(500, gin.H{"code": 7, "msg": "创建失败"})
 		return
@@ -365,7 +400,7 @@ func (a *{{.StructName}}Api) Update(c *gin.Context) {
 	}
 	item.ID = uint(id)
 
-	if err := {{.ModuleName}}Service.Update(&item); err != nil {
+	if err := {{.ModuleName}}Service.Update(c.Request.Context(), &item); err != nil {
 		global.LV_LOG.Error("更新{{.TableComment}}失败", zap.Error(err))
 		c.JSON(500, gin.H{"code": 7, "msg": "更新失败"})
 		return
@@ -377,7 +412,7 @@ func (a *{{.StructName}}Api) Update(c *gin.Context) {
 // Delete 删除{{.TableComment}}
 func (a *{{.StructName}}Api) Delete(c *gin.Context) {
 	id, _ := strconv.Atoi(c.Param("id"))
-	if err := {{.ModuleName}}Service.Delete(uint(id)); err != nil {
+	if err := {{.ModuleName}}Service.Delete(c.Request.Context(), uint(id)); err != nil {
 		global.LV_LOG.Error("删除{{.TableComment}}失败", zap.Error(err))
 		c.JSON(500, gin.H{"code": 7, "msg": "删除失败"})
 		return
@@ -392,13 +427,13 @@ func (a *{{.StructName}}Api) Delete(c *gin.Context) {
 func (s *GeneratorService) generateRouter(config GenerateConfig) (string, error) {
 	tmpl := `// {{.TableComment}}路由 - 添加到 router.go 的 privateGroup 中
 {{.ModuleName}}Api := v1.{{.StructName}}Api{}
-{{.ModuleName}}Group := privateGroup.Group("{{.PackageName}}/{{.ModuleName}}")
+{{.ModuleName}}Group := withModule(privateGroup.Group("{{.PackageName}}/{{.ModuleName}}"), {{printf "%q" (routeModule .)}})
 {
-	{{.ModuleName}}Group.GET("list", {{.ModuleName}}Api.GetList)
-	{{.ModuleName}}Group.GET(":id", {{.ModuleName}}Api.GetById)
-	{{.ModuleName}}Group.POST("", {{.ModuleName}}Api.Create)
-	{{.ModuleName}}Group.PUT(":id", {{.ModuleName}}Api.Update)
-	{{.ModuleName}}Group.DELETE(":id", {{.ModuleName}}Api.Delete)
+	{{.ModuleName}}Group.GET("list", "查询", {{.ModuleName}}Api.GetList)
+	{{.ModuleName}}Group.GET(":id", "查询", {{.ModuleName}}Api.GetById)
+	{{.ModuleName}}Group.POST("", "新增", {{.ModuleName}}Api.Create)
+	{{.ModuleName}}Group.PUT(":id", "修改", {{.ModuleName}}Api.Update)
+	{{.ModuleName}}Group.DELETE(":id", "删除", {{.ModuleName}}Api.Delete)
 }
 `
 	return s.executeTemplate(tmpl, config)
@@ -630,6 +665,10 @@ func (s *GeneratorService) executeTemplate(tmplStr string, config GenerateConfig
 		"queryValue":        queryValue,
 		"convertQueryParam": convertQueryParam,
 		"defaultValue":      defaultValue,
+		"hasDataScope":      hasDataScope,
+		"dataScopeDept":     dataScopeDept,
+		"dataScopeUser":     dataScopeUser,
+		"routeModule":       routeModule,
 	}
 
 	tmpl, err := template.New("gen").Funcs(funcMap).Parse(tmplStr)
@@ -695,8 +734,40 @@ func isQueryField(columnName string) bool {
 	return columnName == "name" || columnName == "title" || columnName == "status" || strings.Contains(columnName, "name")
 }
 
+// 数据范围列：表中含部门列或创建人列时，生成的列表查询自动应用 DataScope
+func dataScopeDept(columns []ColumnInfo) string {
+	return findColumn(columns, "dept_id")
+}
+
+func dataScopeUser(columns []ColumnInfo) string {
+	return findColumn(columns, "created_by", "create_by", "user_id")
+}
+
+func hasDataScope(columns []ColumnInfo) bool {
+	return dataScopeDept(columns) != "" || dataScopeUser(columns) != ""
+}
+
+func findColumn(columns []ColumnInfo, names ...string) string {
+	for _, name := range names {
+		for _, c := range columns {
+			if c.ColumnName == name {
+				return name
+			}
+		}
+	}
+	return ""
+}
+
+// routeModule 生成模块在操作日志中的模块名，默认取表注释
+func routeModule(config GenerateConfig) string {
+	if config.TableComment != "" {
+		return config.TableComment
+	}
+	return config.StructName
+}
+
 func isAutoField(columnName string) bool {
-	auto := []string{"id", "created_at", "updated_at", "deleted_at"}
+	auto := []string{"id", "tenant_id", "created_at", "updated_at", "deleted_at"}
 	for _, a := range auto {
 		if columnName == a {
 			return true
@@ -805,7 +876,7 @@ type GenerateResult struct {
 }
 
 // WriteGeneratedFiles 写入所有生成的文件
-func (s *GeneratorService) WriteGeneratedFiles(req GenerateRequest, backendPath, frontendPath string) (*GenerateResult, error) {
+func (s *GeneratorService) WriteGeneratedFiles(ctx context.Context, req GenerateRequest, backendPath, frontendPath string) (*GenerateResult, error) {
 	result := &GenerateResult{
 		Files:   []string{},
 		Success: false,
@@ -817,6 +888,13 @@ func (s *GeneratorService) WriteGeneratedFiles(req GenerateRequest, backendPath,
 		return result, fmt.Errorf("生成代码失败: %w", err)
 	}
 
+	// 多租户模式下生成的模型带 tenant_id，表中缺少时先补充
+	if req.HasTenantId && global.LV_CONFIG.Tenant.Enable {
+		if err := s.addTenantIdColumn(req.TableName); err != nil {
+			return result, fmt.Errorf("添加 tenant_id 字段失败: %w", err)
+		}
+	}
+
 	// 2. 写入后端文件
 	backendFiles, err := s.writeBackendFiles(req.GenerateConfig, codes, backendPath, req.Overwrite)
 	if err != nil {
@@ -839,7 +917,7 @@ func (s *GeneratorService) WriteGeneratedFiles(req GenerateRequest, backendPath,
 	result.Files = append(result.Files, frontendFiles...)
 
 	// 5. 创建菜单记录
-	menuId, err := s.createMenuRecord(req)
+	menuId, err := s.createMenuRecord(ctx, req)
 	if err != nil {
 		return result, fmt.Errorf("创建菜单失败: %w", err)
 	}
@@ -945,7 +1023,7 @@ func (s *GeneratorService) appendRouterCode(config GenerateConfig, routerCode, r
 	contentStr := string(content)
 
 	// 检查是否已经存在该路由（避免重复添加）
-	routeCheck := fmt.Sprintf(`%sGroup := privateGroup.Group`, config.ModuleName)
+	routeCheck := fmt.Sprintf(`%sGroup := `, config.ModuleName)
 	if strings.Contains(contentStr, routeCheck) {
 		return nil // 路由已存在，跳过
 	}
@@ -961,20 +1039,20 @@ func (s *GeneratorService) appendRouterCode(config GenerateConfig, routerCode, r
 	routerInsert := fmt.Sprintf(`
 		// %s Router (自动生成)
 		%sApi := v1.%sApi{}
-		%sGroup := privateGroup.Group("%s/%s")
+		%sGroup := withModule(privateGroup.Group("%s/%s"), %q)
 		{
-			%sGroup.GET("list", %sApi.GetList)
-			%sGroup.GET(":id", %sApi.GetById)
-			%sGroup.POST("", %sApi.Create)
-			%sGroup.PUT(":id", %sApi.Update)
-			%sGroup.DELETE(":id", %sApi.Delete)
+			%sGroup.GET("list", "查询", %sApi.GetList)
+			%sGroup.GET(":id", "查询", %sApi.GetById)
+			%sGroup.POST("", "新增", %sApi.Create)
+			%sGroup.PUT(":id", "修改", %sApi.Update)
+			%sGroup.DELETE(":id", "删除", %sApi.Delete)
 		}
 	}
 
 	global.LV_LOG.Info`,
 		config.TableComment,
 		config.ModuleName, config.StructName,
-		config.ModuleName, config.PackageName, config.ModuleName,
+		config.ModuleName, config.PackageName, config.ModuleName, routeModule(config),
 		config.ModuleName, config.ModuleName,
 		config.ModuleName, config.ModuleName,
 		config.ModuleName, config.ModuleName,
@@ -993,7 +1071,7 @@ func (s *GeneratorService) appendRouterCode(config GenerateConfig, routerCode, r
 }
 
 // createMenuRecord 创建菜单记录
-func (s *GeneratorService) createMenuRecord(req GenerateRequest) (uint, error) {
+func (s *GeneratorService) createMenuRecord(ctx context.Context, req GenerateRequest) (uint, error) {
 	menu := &model.LvMenu{
 		ParentId:  req.ParentMenuId,
 		Title:     req.TableComment,
@@ -1008,7 +1086,7 @@ func (s *GeneratorService) createMenuRecord(req GenerateRequest) (uint, error) {
 	}
 
 	menuService := SystemMenuService{}
-	if err := menuService.CreateMenu(menu); err != nil {
+	if err := menuService.CreateMenu(ctx, menu); err != nil {
 		return 0, err
 	}
 
@@ -0,0 +1,281 @@
+package service
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go-lv-vue-admin/internal/global"
+	"go-lv-vue-admin/internal/model"
+	"go-lv-vue-admin/internal/storage"
+	"go-lv-vue-admin/pkg/utils"
+	"io"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	logArchiveFetchSize = 1000   // 每次从数据库读取、删除、恢复的条数
+	logArchiveMaxRows   = 100000 // 单个归档文件最多包含的条数
+)
+
+// ErrLogArchiveRunning 已有归档任务在执行
+var ErrLogArchiveRunning = errors.New("归档任务正在执行，请稍后再试")
+
+// logArchiveMu 同一时间只执行一个归档任务（定时任务与手动触发共用）
+var logArchiveMu sync.Mutex
+
+type LogArchiveService struct{}
+
+// GetArchiveList 获取归档列表
+func (s *LogArchiveService) GetArchiveList(ctx context.Context, page, pageSize int) ([]model.LvLogArchive, int64, error) {
+	var archives []model.LvLogArchive
+	var total int64
+
+	db := global.LV_DB.WithContext(ctx).Model(&model.LvLogArchive{})
+	db.Count(&total)
+
+	offset := (page - 1) * pageSize
+	err := db.Order("id DESC").Offset(offset).Limit(pageSize).Find(&archives).Error
+
+	return archives, total, err
+}
+
+// ArchiveExpiredLogs 按 operation_log.retention_days 归档过期日志，未配置保留天数时不处理
+func (s *LogArchiveService) ArchiveExpiredLogs(ctx context.Context) ([]model.LvLogArchive, error) {
+	days := global.LV_CONFIG.OperationLog.RetentionDays
+	if days <= 0 {
+		return nil, nil
+	}
+	return s.ArchiveLogs(ctx, days)
+}
+
+// ArchiveLogs 将 days 天前的日志（所有租户）压缩上传到存储驱动后从数据库删除，返回生成的归档
+// 已删除（软删除）的过期日志直接清理，不再归档；已恢复的归档中的日志保留到清除恢复为止
+func (s *LogArchiveService) ArchiveLogs(ctx context.Context, days int) ([]model.LvLogArchive, error) {
+	if days <= 0 {
+		return nil, errors.New("保留天数必须大于0")
+	}
+	if !logArchiveMu.TryLock() {
+		return nil, ErrLogArchiveRunning
+	}
+	defer logArchiveMu.Unlock()
+
+	ctx = utils.WithoutTenant(ctx)
+	before := time.Now().AddDate(0, 0, -days)
+
+	if err := global.LV_DB.WithContext(ctx).Unscoped().
+		Where("deleted_at IS NOT NULL AND created_at < ?", before).
+		Delete(&model.LvOperationLog{}).Error; err != nil {
+		return nil, err
+	}
+
+	var restored []model.LvLogArchive
+	if err := global.LV_DB.WithContext(ctx).Where("restored_at IS NOT NULL").Find(&restored).Error; err != nil {
+		return nil, err
+	}
+
+	var archives []model.LvLogArchive
+	for {
+		archive, err := s.archiveChunk(ctx, before, restored)
+		if err != nil {
+			return archives, err
+		}
+		if archive == nil {
+			return archives, nil
+		}
+		archives = append(archives, *archive)
+	}
+}
+
+// archiveChunk 按 ID 顺序归档最多 logArchiveMaxRows 条日志，没有可归档的日志时返回 nil
+// 先上传文件、记录归档，再删除已写入文件的日志，上传失败时日志保持不变
+func (s *LogArchiveService) archiveChunk(ctx context.Context, before time.Time, restored []model.LvLogArchive) (*model.LvLogArchive, error) {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	encoder := json.NewEncoder(zw)
+
+	archive := model.LvLogArchive{}
+	var ids []uint
+	var lastId uint
+	for len(ids) < logArchiveMaxRows {
+		db := global.LV_DB.WithContext(ctx).Where("id > ? AND created_at < ?", lastId, before)
+		for _, r := range restored {
+			db = db.Where("id NOT BETWEEN ? AND ?", r.MinId, r.MaxId)
+		}
+		var logs []model.LvOperationLog
+		if err := db.Order("id").Limit(min(logArchiveFetchSize, logArchiveMaxRows-len(ids))).Find(&logs).Error; err != nil {
+			return nil, err
+		}
+		for _, log := range logs {
+			if err := encoder.Encode(log); err != nil {
+				return nil, err
+			}
+			if archive.StartTime.IsZero() || log.CreatedAt.Before(archive.StartTime) {
+				archive.StartTime = log.CreatedAt
+			}
+			if log.CreatedAt.After(archive.EndTime) {
+				archive.EndTime = log.CreatedAt
+			}
+			ids = append(ids, log.ID)
+			lastId = log.ID
+		}
+		if len(logs) < logArchiveFetchSize {
+			break
+		}
+	}
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+
+	archive.Count = int64(len(ids))
+	archive.MinId = ids[0]
+	archive.MaxId = ids[len(ids)-1]
+	archive.Size = int64(buf.Len())
+	archive.Filename = fmt.Sprintf("operation-logs-%d-%d.jsonl.gz", archive.MinId, archive.MaxId)
+
+	url, key, err := storage.GetDriver().UploadReader(&buf, archive.Filename, archive.Size)
+	if err != nil {
+		return nil, fmt.Errorf("上传归档文件失败: %w", err)
+	}
+	archive.Url = url
+	archive.Key = key
+
+	err = global.LV_DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&archive).Error; err != nil {
+			return err
+		}
+		for start := 0; start < len(ids); start += logArchiveFetchSize {
+			end := min(start+logArchiveFetchSize, len(ids))
+			if err := tx.Unscoped().Delete(&model.LvOperationLog{}, ids[start:end]).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		if delErr := storage.GetDriver().Delete(key); delErr != nil {
+			global.LV_LOG.Warn("删除未使用的归档文件失败", zap.String("key", key), zap.Error(delErr))
+		}
+		return nil, err
+	}
+	global.LV_LOG.Info("操作日志已归档", zap.String("key", key), zap.Int64("count", archive.Count))
+	return &archive, nil
+}
+
+// RestoreArchive 将归档中的日志重新导入日志表用于排查，已存在的日志（按 ID）跳过，返回导入的条数
+func (s *LogArchiveService) RestoreArchive(ctx context.Context, id uint) (int64, error) {
+	ctx = utils.WithoutTenant(ctx)
+	var archive model.LvLogArchive
+	if err := global.LV_DB.WithContext(ctx).First(&archive, id).Error; err != nil {
+		return 0, err
+	}
+
+	var restored int64
+	err := readArchive(archive.Key, func(logs []model.LvOperationLog) error {
+		result := global.LV_DB.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(&logs)
+		restored += result.RowsAffected
+		return result.Error
+	})
+	if err != nil {
+		return restored, err
+	}
+
+	now := time.Now()
+	err = global.LV_DB.WithContext(ctx).Model(&archive).Update("restored_at", &now).Error
+	return restored, err
+}
+
+// ClearRestoredArchive 排查完成后删除从归档恢复的日志，归档文件保留
+func (s *LogArchiveService) ClearRestoredArchive(ctx context.Context, id uint) error {
+	ctx = utils.WithoutTenant(ctx)
+	var archive model.LvLogArchive
+	if err := global.LV_DB.WithContext(ctx).First(&archive, id).Error; err != nil {
+		return err
+	}
+
+	err := readArchive(archive.Key, func(logs []model.LvOperationLog) error {
+		ids := make([]uint, 0, len(logs))
+		for _, log := range logs {
+			ids = append(ids, log.ID)
+		}
+		return global.LV_DB.WithContext(ctx).Unscoped().Delete(&model.LvOperationLog{}, ids).Error
+	})
+	if err != nil {
+		return err
+	}
+	return global.LV_DB.WithContext(ctx).Model(&archive).Update("restored_at", nil).Error
+}
+
+// readArchive 从存储驱动读取归档文件，按 logArchiveFetchSize 条一批回调
+func readArchive(key string, fn func(logs []model.LvOperationLog) error) error {
+	reader, err := storage.GetDriver().Download(key)
+	if err != nil {
+		return fmt.Errorf("读取归档文件失败: %w", err)
+	}
+	defer reader.Close()
+
+	zr, err := gzip.NewReader(reader)
+	if err != nil {
+		return fmt.Errorf("解压归档文件失败: %w", err)
+	}
+	defer zr.Close()
+
+	decoder := json.NewDecoder(bufio.NewReader(zr))
+	logs := make([]model.LvOperationLog, 0, logArchiveFetchSize)
+	for {
+		var log model.LvOperationLog
+		if err := decoder.Decode(&log); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return fmt.Errorf("解析归档文件失败: %w", err)
+		}
+		logs = append(logs, log)
+		if len(logs) == logArchiveFetchSize {
+			if err := fn(logs); err != nil {
+				return err
+			}
+			logs = logs[:0]
+		}
+	}
+	if len(logs) > 0 {
+		return fn(logs)
+	}
+	return nil
+}
+
+// RunLogArchiveScheduler 按 operation_log.archive_interval 定时归档过期日志，ctx 取消时退出
+func RunLogArchiveScheduler(ctx context.Context) {
+	if global.LV_CONFIG.OperationLog.RetentionDays <= 0 {
+		return
+	}
+	interval, err := utils.ParseDuration(global.LV_CONFIG.OperationLog.ArchiveInterval)
+	if err != nil || interval <= 0 {
+		interval = 24 * time.Hour
+	}
+
+	archiveService := LogArchiveService{}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if _, err := archiveService.ArchiveExpiredLogs(ctx); err != nil && !errors.Is(err, ErrLogArchiveRunning) {
+			global.LV_LOG.Error("归档操作日志失败", zap.Error(err))
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,175 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"go-lv-vue-admin/internal/global"
+	"go-lv-vue-admin/internal/model"
+	"go-lv-vue-admin/pkg/utils"
+	"sync"
+	"time"
+
+	"github.com/mojocn/base64Captcha"
+)
+
+// LoginGuardService 登录防暴力破解：失败计数、验证码和账号锁定
+type LoginGuardService struct{}
+
+// failureRecord 失败计数（按用户名或 IP）
+type failureRecord struct {
+	count   int
+	firstAt time.Time
+}
+
+// maxFailureRecords 触发过期清理的失败记录数量
+const maxFailureRecords = 10000
+
+var (
+	failureMu      sync.Mutex
+	failureRecords = make(map[string]*failureRecord)
+
+	// captchaStore 内存验证码存储
+	captchaStore = base64Captcha.DefaultMemStore
+)
+
+// GenerateCaptcha 生成图片验证码，返回验证码ID和 base64 图片
+func (s *LoginGuardService) GenerateCaptcha() (id, b64s string, err error) {
+	length := global.LV_CONFIG.Login.CaptchaLength
+	if length <= 0 {
+		length = 4
+	}
+	driver := base64Captcha.NewDriverDigit(80, 240, length, 0.7, 80)
+	id, b64s, _, err = base64Captcha.NewCaptcha(driver, captchaStore).Generate()
+	return id, b64s, err
+}
+
+// VerifyCaptcha 校验验证码（校验后即失效）
+func (s *LoginGuardService) VerifyCaptcha(id, answer string) bool {
+	if id == "" || answer == "" {
+		return false
+	}
+	return captchaStore.Verify(id, answer, true)
+}
+
+// CaptchaRequired 用户名或 IP 的失败次数达到阈值后需要验证码
+func (s *LoginGuardService) CaptchaRequired(ctx context.Context, username, ip string) bool {
+	threshold := global.LV_CONFIG.Login.CaptchaThreshold
+	if threshold <= 0 {
+		return true
+	}
+	return s.failures(userKey(ctx, username)) >= threshold || s.failures("ip:"+ip) >= threshold
+}
+
+// LockedUntil 返回账号锁定截止时间，未锁定或用户不存在时返回 nil
+func (s *LoginGuardService) LockedUntil(ctx context.Context, username string) *time.Time {
+	var user model.LvUser
+	if err := global.LV_DB.WithContext(ctx).Select("id", "locked_until").Where("username = ?", username).First(&user).Error; err != nil {
+		return nil
+	}
+	if user.LockedUntil != nil && user.LockedUntil.After(time.Now()) {
+		return user.LockedUntil
+	}
+	return nil
+}
+
+// RecordFailure 记录一次登录失败，达到锁定阈值时锁定账号并返回锁定截止时间
+func (s *LoginGuardService) RecordFailure(ctx context.Context, username, ip string) *time.Time {
+	userFailures := s.increase(userKey(ctx, username))
+	s.increase("ip:" + ip)
+
+	threshold := global.LV_CONFIG.Login.LockThreshold
+	if threshold <= 0 || userFailures < threshold {
+		return nil
+	}
+
+	duration, _ := utils.ParseDuration(global.LV_CONFIG.Login.LockDuration)
+	if duration <= 0 {
+		duration = 15 * time.Minute
+	}
+	lockedUntil := time.Now().Add(duration)
+	result := global.LV_DB.WithContext(ctx).Model(&model.LvUser{}).Where("username = ?", username).Update("locked_until", lockedUntil)
+	if result.Error != nil || result.RowsAffected == 0 {
+		// 用户名不存在时不记录锁定
+		return nil
+	}
+
+	s.reset(userKey(ctx, username))
+	return &lockedUntil
+}
+
+// RecordSuccess 登录成功后清除该用户名的失败计数
+func (s *LoginGuardService) RecordSuccess(ctx context.Context, username string) {
+	s.reset(userKey(ctx, username))
+}
+
+// Unlock 解锁账号并清除失败计数
+func (s *LoginGuardService) Unlock(ctx context.Context, userId uint) error {
+	var user model.LvUser
+	if err := global.LV_DB.WithContext(ctx).First(&user, userId).Error; err != nil {
+		return err
+	}
+	if global.LV_CONFIG.Tenant.Enable {
+		s.reset(userKey(utils.WithTenant(ctx, user.TenantId), user.Username))
+	} else {
+		s.reset(userKey(ctx, user.Username))
+	}
+	return global.LV_DB.WithContext(ctx).Model(&user).Update("locked_until", nil).Error
+}
+
+// userKey 用户名的失败计数键，多租户模式下不同租户的同名用户分开计数
+func userKey(ctx context.Context, username string) string {
+	if tenantId, ok := utils.TenantFromContext(ctx); ok {
+		return fmt.Sprintf("user:%d:%s", tenantId, username)
+	}
+	return "user:" + username
+}
+
+// failures 获取窗口期内的失败次数
+func (s *LoginGuardService) failures(key string) int {
+	failureMu.Lock()
+	defer failureMu.Unlock()
+
+	record, ok := failureRecords[key]
+	if !ok || s.expired(record) {
+		return 0
+	}
+	return record.count
+}
+
+// increase 失败次数加一，返回累计次数
+func (s *LoginGuardService) increase(key string) int {
+	failureMu.Lock()
+	defer failureMu.Unlock()
+
+	// 记录过多时清理已过期的条目，避免大量 IP 撑爆内存
+	if len(failureRecords) > maxFailureRecords {
+		for k, r := range failureRecords {
+			if s.expired(r) {
+				delete(failureRecords, k)
+			}
+		}
+	}
+
+	record, ok := failureRecords[key]
+	if !ok || s.expired(record) {
+		record = &failureRecord{firstAt: time.Now()}
+		failureRecords[key] = record
+	}
+	record.count++
+	return record.count
+}
+
+func (s *LoginGuardService) reset(key string) {
+	failureMu.Lock()
+	defer failureMu.Unlock()
+	delete(failureRecords, key)
+}
+
+// expired 失败记录是否已超出统计窗口
+func (s *LoginGuardService) expired(record *failureRecord) bool {
+	window, _ := utils.ParseDuration(global.LV_CONFIG.Login.FailureWindow)
+	if window <= 0 {
+		window = 30 * time.Minute
+	}
+	return time.Since(record.firstAt) > window
+}
@@ -1,43 +1,235 @@
 package service
 
 import (
+	"context"
 	"go-lv-vue-admin/internal/global"
 	"go-lv-vue-admin/internal/model"
+	"go-lv-vue-admin/pkg/utils"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type OperationLogService struct{}
 
-// GetOperationLogList 获取操作日志列表
-func (s *OperationLogService) GetOperationLogList(page, pageSize int, username, module, action string) ([]model.LvOperationLog, int64, error) {
+// OperationLogFilter 操作日志查询条件，列表和导出共用
+type OperationLogFilter struct {
+	Username   string // 用户名，模糊匹配
+	Module     string // 操作模块
+	Action     string // 操作类型
+	Ip         string // 单个 IP、CIDR（如 10.0.0.0/8、2001:db8::/32）或 IP 前缀
+	Path       string // 请求路径前缀
+	StatusFrom int    // 状态码范围，如 4xx 为 400-499，0 表示不限
+	StatusTo   int
+	MinLatency int64      // 耗时不低于（ms），0 表示不限
+	StartTime  *time.Time // 创建时间范围
+	EndTime    *time.Time
+	Keyword    string // 在请求参数和响应内容中全文搜索
+	SortBy     string // 排序字段，见 operationLogSortColumns，默认按时间
+	SortOrder  string // asc / desc，默认 desc
+}
+
+// operationLogSortColumns 允许排序的字段（均建有索引），键为接口返回的字段名
+var operationLogSortColumns = map[string]string{
+	"ID":        "id",
+	"CreatedAt": "created_at",
+	"username":  "username",
+	"ip":        "ip",
+	"path":      "path",
+	"status":    "status",
+	"latency":   "latency",
+	"module":    "module",
+}
+
+// GetOperationLogList 获取操作日志列表，按操作人的数据范围过滤
+func (s *OperationLogService) GetOperationLogList(ctx context.Context, operatorId, activeRoleId uint, page, pageSize int, filter OperationLogFilter) ([]model.LvOperationLog, int64, error) {
 	var logs []model.LvOperationLog
 	var total int64
 
-	db := global.LV_DB.Model(&model.LvOperationLog{})
+	db := s.listQuery(ctx, operatorId, activeRoleId, filter)
+	db.Count(&total)
 
-	if username != "" {
-		db = db.Where("username LIKE ?", "%"+username+"%")
-	}
-	if module != "" {
-		db = db.Where("module = ?", module)
-	}
-	if action != "" {
-		db = db.Where("action = ?", action)
+	column, ok := operationLogSortColumns[filter.SortBy]
+	if !ok {
+		column = "created_at"
 	}
+	desc := !strings.EqualFold(filter.SortOrder, "asc")
 
-	db.Count(&total)
+	db = db.Order(clause.OrderByColumn{Column: clause.Column{Name: column}, Desc: desc})
+	if column != "id" {
+		// 排序字段相同时按 ID 排序，保证分页结果稳定
+		db = db.Order(clause.OrderByColumn{Column: clause.Column{Name: "id"}, Desc: desc})
+	}
 
 	offset := (page - 1) * pageSize
-	err := db.Order("created_at DESC").Offset(offset).Limit(pageSize).Find(&logs).Error
+	err := db.Offset(offset).Limit(pageSize).Find(&logs).Error
 
 	return logs, total, err
 }
 
-// DeleteOperationLogs 批量删除操作日志
-func (s *OperationLogService) DeleteOperationLogs(ids []uint) error {
-	return global.LV_DB.Delete(&model.LvOperationLog{}, ids).Error
+// listQuery 按列表筛选条件和操作人的数据范围构造查询，列表和导出共用
+func (s *OperationLogService) listQuery(ctx context.Context, operatorId, activeRoleId uint, filter OperationLogFilter) *gorm.DB {
+	db := global.LV_DB.WithContext(ctx).Model(&model.LvOperationLog{}).Scopes(DataScope(operatorId, activeRoleId, DataScopeColumns{User: "user_id"}))
+
+	if filter.Username != "" {
+		db = db.Where("username LIKE ?", "%"+filter.Username+"%")
+	}
+	if filter.Module != "" {
+		db = db.Where("module = ?", filter.Module)
+	}
+	if filter.Action != "" {
+		db = db.Where("action = ?", filter.Action)
+	}
+	if filter.Ip != "" {
+		db = db.Scopes(ipFilter(filter.Ip))
+	}
+	if filter.Path != "" {
+		db = db.Where("path LIKE ?", filter.Path+"%")
+	}
+	if filter.StatusFrom > 0 {
+		db = db.Where("status >= ?", filter.StatusFrom)
+	}
+	if filter.StatusTo > 0 {
+		db = db.Where("status <= ?", filter.StatusTo)
+	}
+	if filter.MinLatency > 0 {
+		db = db.Where("latency >= ?", filter.MinLatency)
+	}
+	if filter.StartTime != nil {
+		db = db.Where("created_at >= ?", *filter.StartTime)
+	}
+	if filter.EndTime != nil {
+		db = db.Where("created_at <= ?", *filter.EndTime)
+	}
+	// 布尔模式下按短语匹配，去掉会被当作运算符的双引号
+	if keyword := strings.TrimSpace(strings.ReplaceAll(filter.Keyword, `"`, " ")); keyword != "" {
+		db = db.Where("MATCH(body, response) AGAINST (? IN BOOLEAN MODE)", `"`+keyword+`"`)
+	}
+	return db
+}
+
+// ipFilter 按 IP 过滤：CIDR 按地址范围比较（MySQL INET6_ATON 同时支持 IPv4 和 IPv6），单个 IP 精确匹配，其他按前缀匹配
+func ipFilter(ip string) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if _, network, err := net.ParseCIDR(ip); err == nil {
+			start := network.IP
+			end := make(net.IP, len(start))
+			for i := range start {
+				end[i] = start[i] | ^network.Mask[i]
+			}
+			// INET6_ATON 对 IPv4 返回 4 字节、IPv6 返回 16 字节，长度不同的地址不参与比较
+			return db.Where("LENGTH(INET6_ATON(ip)) = ? AND INET6_ATON(ip) BETWEEN ? AND ?", len(start), []byte(start), []byte(end))
+		}
+		if net.ParseIP(ip) != nil {
+			return db.Where("ip = ?", ip)
+		}
+		return db.Where("ip LIKE ?", ip+"%")
+	}
+}
+
+// ExportOperationLogs 按列表的筛选条件导出操作日志（不含响应内容）
+func (s *OperationLogService) ExportOperationLogs(operatorId, activeRoleId uint, filter OperationLogFilter) Export {
+	return Export{
+		Type:    "operation_log",
+		Name:    "操作日志",
+		Headers: []string{"ID", "用户", "模拟登录管理员", "访问令牌", "IP", "模块", "操作", "请求方式", "请求路径", "状态码", "耗时(ms)", "请求参数", "User-Agent", "时间"},
+		Count: func(ctx context.Context) (int64, error) {
+			var total int64
+			err := s.listQuery(ctx, operatorId, activeRoleId, filter).Count(&total).Error
+			return total, err
+		},
+		Rows: func(ctx context.Context, write func(row []string) error) error {
+			var logs []model.LvOperationLog
+			return s.listQuery(ctx, operatorId, activeRoleId, filter).Omit("response").
+				FindInBatches(&logs, exportBatchSize, func(tx *gorm.DB, batch int) error {
+					for _, log := range logs {
+						err := write([]string{
+							strconv.FormatUint(uint64(log.ID), 10),
+							log.Username,
+							log.ImpersonatorName,
+							log.AccessToken,
+							log.Ip,
+							log.Module,
+							log.Action,
+							log.Method,
+							log.Path,
+							strconv.Itoa(log.Status),
+							strconv.FormatInt(log.Latency, 10),
+							log.Body,
+							log.UserAgent,
+							log.CreatedAt.Format("2006-01-02 15:04:05"),
+						})
+						if err != nil {
+							return err
+						}
+					}
+					return nil
+				}).Error
+		},
+	}
+}
+
+// DeleteOperationLogs 批量删除操作日志，不在操作人数据范围内的日志会被忽略
+func (s *OperationLogService) DeleteOperationLogs(ctx context.Context, operatorId, activeRoleId uint, ids []uint) error {
+	return s.listQuery(ctx, operatorId, activeRoleId, OperationLogFilter{}).Where("id IN ?", ids).Delete(&model.LvOperationLog{}).Error
 }
 
-// ClearOperationLogs 清空操作日志
-func (s *OperationLogService) ClearOperationLogs() error {
-	return global.LV_DB.Where("1=1").Delete(&model.LvOperationLog{}).Error
+// ClearOperationLogs 清空操作人可见的操作日志（多租户模式下只清空当前租户的日志），范围与列表一致
+func (s *OperationLogService) ClearOperationLogs(ctx context.Context, operatorId, activeRoleId uint) error {
+	return s.listQuery(ctx, operatorId, activeRoleId, OperationLogFilter{}).Where("1=1").Delete(&model.LvOperationLog{}).Error
+}
+
+// defaultRedactKeys 未配置 operation_log.redact_keys 时使用的脱敏规则
+var defaultRedactKeys = []string{"*password*", "*token*", "*secret*", "*key", "*phone*"}
+
+var (
+	logRedactor     *utils.Redactor
+	logRedactorOnce sync.Once
+)
+
+// RedactLogContent 按配置对日志中的请求、响应内容脱敏，配置为跳过脱敏的接口原样返回
+func RedactLogContent(path, content string) string {
+	for _, prefix := range global.LV_CONFIG.OperationLog.RedactSkipPaths {
+		if prefix != "" && strings.HasPrefix(path, prefix) {
+			return content
+		}
+	}
+	logRedactorOnce.Do(func() {
+		keys := global.LV_CONFIG.OperationLog.RedactKeys
+		if len(keys) == 0 {
+			keys = defaultRedactKeys
+		}
+		logRedactor = utils.NewRedactor(keys)
+	})
+	return logRedactor.Redact(content)
+}
+
+// ScrubOperationLogs 按当前脱敏规则清洗已保存的日志（用于规则调整或升级前写入的明文），返回被修改的条数
+func (s *OperationLogService) ScrubOperationLogs(ctx context.Context) (int64, error) {
+	db := global.LV_DB.WithContext(ctx)
+	var scrubbed int64
+	var logs []model.LvOperationLog
+	err := db.Select("id", "path", "body", "response").FindInBatches(&logs, 200, func(tx *gorm.DB, batch int) error {
+		for _, log := range logs {
+			body := RedactLogContent(log.Path, log.Body)
+			response := RedactLogContent(log.Path, log.Response)
+			if body == log.Body && response == log.Response {
+				continue
+			}
+			if err := db.Model(&model.LvOperationLog{}).Where("id = ?", log.ID).UpdateColumns(map[string]interface{}{
+				"body":     body,
+				"response": response,
+			}).Error; err != nil {
+				return err
+			}
+			scrubbed++
+		}
+		return nil
+	}).Error
+	return scrubbed, err
 }
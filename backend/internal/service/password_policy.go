@@ -0,0 +1,270 @@
+package service
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"go-lv-vue-admin/internal/global"
+	"go-lv-vue-admin/internal/model"
+	"go-lv-vue-admin/pkg/utils"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"gorm.io/gorm"
+)
+
+// PasswordPolicyService 密码策略：复杂度、弱密码、历史密码和有效期
+// 策略保存在 LvSetting 中，管理员可在系统设置里调整
+type PasswordPolicyService struct{}
+
+// PasswordPolicy 密码策略
+type PasswordPolicy struct {
+	MinLength     int      `json:"minLength"`
+	RequireUpper  bool     `json:"requireUpper"`
+	RequireLower  bool     `json:"requireLower"`
+	RequireDigit  bool     `json:"requireDigit"`
+	RequireSymbol bool     `json:"requireSymbol"`
+	BannedList    []string `json:"bannedList"`
+	HistoryCount  int      `json:"historyCount"`
+	MaxAgeDays    int      `json:"maxAgeDays"`
+}
+
+// maxPasswordHistory 每个用户最多保留的历史密码条数
+const maxPasswordHistory = 24
+
+// generatedPasswordLength 随机生成密码的最小长度，策略要求更长时按策略
+const generatedPasswordLength = 12
+
+// 随机生成密码所用的字符，去掉了容易混淆的 0/O、1/l/I
+const (
+	passwordUpperChars  = "ABCDEFGHJKLMNPQRSTUVWXYZ"
+	passwordLowerChars  = "abcdefghijkmnpqrstuvwxyz"
+	passwordDigitChars  = "23456789"
+	passwordSymbolChars = "!@#$%^&*-_=+?"
+)
+
+// passwordMustChangeAt 管理员重置密码后写入的密码修改时间，用户下次登录必须修改密码
+var passwordMustChangeAt = time.Unix(0, 0)
+
+// commonPasswords 内置常见弱密码
+var commonPasswords = []string{
+	"123456", "1234567", "12345678", "123456789", "1234567890", "111111", "000000", "123123",
+	"654321", "666666", "888888", "112233", "abc123", "abcd1234", "a123456", "qwerty",
+	"qwerty123", "qwe123", "1qaz2wsx", "password", "password1", "passw0rd", "p@ssw0rd",
+	"admin", "admin123", "admin@123", "root", "root123", "iloveyou", "welcome", "letmein",
+}
+
+// GetPolicy 读取当前密码策略（平台统一设置，租户不可覆盖）
+func (s *PasswordPolicyService) GetPolicy() PasswordPolicy {
+	settings := make(map[string]string)
+	var rows []model.LvSetting
+	global.LV_DB.Where("tenant_id = ? AND `key` LIKE ?", 0, "password_%").Find(&rows)
+	for _, row := range rows {
+		settings[row.Key] = strings.TrimSpace(row.Value)
+	}
+
+	policy := PasswordPolicy{
+		MinLength:     settingInt(settings["password_min_length"], 6),
+		RequireUpper:  settings["password_require_upper"] == "true",
+		RequireLower:  settings["password_require_lower"] == "true",
+		RequireDigit:  settings["password_require_digit"] == "true",
+		RequireSymbol: settings["password_require_symbol"] == "true",
+		HistoryCount:  settingInt(settings["password_history_count"], 0),
+		MaxAgeDays:    settingInt(settings["password_max_age_days"], 0),
+	}
+	for _, banned := range strings.Split(settings["password_banned_list"], ",") {
+		if banned = strings.TrimSpace(banned); banned != "" {
+			policy.BannedList = append(policy.BannedList, banned)
+		}
+	}
+	return policy
+}
+
+// Validate 校验新密码是否符合策略，userId 为 0 表示新建用户（不检查历史密码）
+func (s *PasswordPolicyService) Validate(userId uint, username, password string) error {
+	policy := s.GetPolicy()
+
+	if len([]rune(password)) < policy.MinLength {
+		return fmt.Errorf("密码长度不能少于 %d 位", policy.MinLength)
+	}
+
+	var hasUpper, hasLower, hasDigit, hasSymbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			hasUpper = true
+		case unicode.IsLower(r):
+			hasLower = true
+		case unicode.IsDigit(r):
+			hasDigit = true
+		case unicode.IsPunct(r) || unicode.IsSymbol(r):
+			hasSymbol = true
+		}
+	}
+	if policy.RequireUpper && !hasUpper {
+		return errors.New("密码必须包含大写字母")
+	}
+	if policy.RequireLower && !hasLower {
+		return errors.New("密码必须包含小写字母")
+	}
+	if policy.RequireDigit && !hasDigit {
+		return errors.New("密码必须包含数字")
+	}
+	if policy.RequireSymbol && !hasSymbol {
+		return errors.New("密码必须包含特殊字符")
+	}
+
+	lower := strings.ToLower(password)
+	if username != "" && lower == strings.ToLower(username) {
+		return errors.New("密码不能与用户名相同")
+	}
+	for _, banned := range append(commonPasswords, policy.BannedList...) {
+		if lower == strings.ToLower(banned) {
+			return errors.New("密码过于简单，请更换")
+		}
+	}
+
+	if userId != 0 && policy.HistoryCount > 0 {
+		for _, hash := range s.recentHashes(userId, policy.HistoryCount) {
+			if utils.CheckPassword(password, hash) {
+				return fmt.Errorf("不能使用最近 %d 次用过的密码", policy.HistoryCount)
+			}
+		}
+	}
+	return nil
+}
+
+// GeneratePassword 生成符合当前策略的随机密码（大小写字母、数字和特殊字符各至少一个）
+func (s *PasswordPolicyService) GeneratePassword(userId uint, username string) (string, error) {
+	length := s.GetPolicy().MinLength
+	if length < generatedPasswordLength {
+		length = generatedPasswordLength
+	}
+	all := passwordUpperChars + passwordLowerChars + passwordDigitChars + passwordSymbolChars
+
+	var err error
+	for attempt := 0; attempt < 5; attempt++ {
+		chars := []string{passwordUpperChars, passwordLowerChars, passwordDigitChars, passwordSymbolChars}
+		for len(chars) < length {
+			chars = append(chars, all)
+		}
+		password := make([]byte, length)
+		for i, set := range chars {
+			n, randErr := randomIndex(len(set))
+			if randErr != nil {
+				return "", randErr
+			}
+			password[i] = set[n]
+		}
+		// 打乱顺序，避免前四位的字符类型固定
+		for i := len(password) - 1; i > 0; i-- {
+			j, randErr := randomIndex(i + 1)
+			if randErr != nil {
+				return "", randErr
+			}
+			password[i], password[j] = password[j], password[i]
+		}
+		if err = s.Validate(userId, username, string(password)); err == nil {
+			return string(password), nil
+		}
+	}
+	return "", err
+}
+
+// randomIndex 返回 [0, n) 内的随机数
+func randomIndex(n int) (int, error) {
+	b := make([]byte, 1)
+	limit := 256 - 256%n
+	for {
+		if _, err := rand.Read(b); err != nil {
+			return 0, err
+		}
+		// 丢弃超出 n 整数倍的值，保证分布均匀
+		if int(b[0]) < limit {
+			return int(b[0]) % n, nil
+		}
+	}
+}
+
+// RequireChange 要求用户下次登录时修改密码，用于管理员重置密码后
+func (s *PasswordPolicyService) RequireChange(tx *gorm.DB, userId uint) error {
+	return tx.Model(&model.LvUser{}).Where("id = ?", userId).Update("password_changed_at", passwordMustChangeAt).Error
+}
+
+// SetPassword 加密并保存用户新密码，同时记录历史密码和修改时间
+// 调用前应先通过 Validate 校验
+func (s *PasswordPolicyService) SetPassword(tx *gorm.DB, userId uint, password string) error {
+	hashedPassword, err := utils.HashPassword(password)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	if err := tx.Model(&model.LvUser{}).Where("id = ?", userId).Updates(map[string]interface{}{
+		"password":            hashedPassword,
+		"password_changed_at": now,
+	}).Error; err != nil {
+		return err
+	}
+	return s.recordHistory(tx, userId, hashedPassword)
+}
+
+// IsExpired 判断用户密码是否已超过有效期
+func (s *PasswordPolicyService) IsExpired(user *model.LvUser) bool {
+	// 外部账号的密码有效期由身份源管理
+	if userSource(user) != SourceLocal {
+		return false
+	}
+	// 管理员重置的密码，无论是否设置有效期都需要先修改
+	if user.PasswordChangedAt != nil && !user.PasswordChangedAt.After(passwordMustChangeAt) {
+		return true
+	}
+	policy := s.GetPolicy()
+	if policy.MaxAgeDays <= 0 {
+		return false
+	}
+	changedAt := user.CreatedAt
+	if user.PasswordChangedAt != nil {
+		changedAt = *user.PasswordChangedAt
+	}
+	return time.Since(changedAt) > time.Duration(policy.MaxAgeDays)*24*time.Hour
+}
+
+// recordHistory 记录历史密码，并只保留最近 maxPasswordHistory 条
+func (s *PasswordPolicyService) recordHistory(tx *gorm.DB, userId uint, hash string) error {
+	if err := tx.Create(&model.LvPasswordHistory{UserId: userId, PasswordHash: hash}).Error; err != nil {
+		return err
+	}
+
+	var staleIds []uint
+	tx.Model(&model.LvPasswordHistory{}).Where("user_id = ?", userId).
+		Order("id DESC").Offset(maxPasswordHistory).Pluck("id", &staleIds)
+	if len(staleIds) > 0 {
+		return tx.Unscoped().Delete(&model.LvPasswordHistory{}, staleIds).Error
+	}
+	return nil
+}
+
+// recentHashes 获取用户当前密码及最近的历史密码摘要
+func (s *PasswordPolicyService) recentHashes(userId uint, count int) []string {
+	var hashes []string
+	global.LV_DB.Model(&model.LvPasswordHistory{}).Where("user_id = ?", userId).
+		Order("id DESC").Limit(count).Pluck("password_hash", &hashes)
+
+	// 兼容策略启用前设置的密码：当前密码始终视为最近一次
+	var user model.LvUser
+	if global.LV_DB.Select("password").First(&user, userId).Error == nil && user.Password != "" {
+		hashes = append(hashes, user.Password)
+	}
+	return hashes
+}
+
+// settingInt 解析整数设置，失败时返回默认值
+func settingInt(value string, def int) int {
+	if n, err := strconv.Atoi(value); err == nil && n >= 0 {
+		return n
+	}
+	return def
+}
@@ -1,16 +1,19 @@
 package service
 
 import (
+	"context"
+	"errors"
 	"go-lv-vue-admin/internal/global"
 	"go-lv-vue-admin/internal/model"
+	"sort"
 )
 
 type PermissionService struct{}
 
 // GetRoleMenus 获取角色已分配的菜单ID列表
-func (s *PermissionService) GetRoleMenus(roleId uint) ([]uint, error) {
+func (s *PermissionService) GetRoleMenus(ctx context.Context, roleId uint) ([]uint, error) {
 	var role model.LvRole
-	err := global.LV_DB.Preload("Menus").First(&role, roleId).Error
+	err := global.LV_DB.WithContext(ctx).Preload("Menus").First(&role, roleId).Error
 	if err != nil {
 		return nil, err
 	}
@@ -23,94 +26,194 @@ func (s *PermissionService) GetRoleMenus(roleId uint) ([]uint, error) {
 }
 
 // SetRoleMenus 设置角色的菜单权限
-func (s *PermissionService) SetRoleMenus(roleId uint, menuIds []uint) error {
+func (s *PermissionService) SetRoleMenus(ctx context.Context, roleId uint, menuIds []uint) error {
+	db := global.LV_DB.WithContext(ctx)
 	var role model.LvRole
-	if err := global.LV_DB.First(&role, roleId).Error; err != nil {
+	if err := db.First(&role, roleId).Error; err != nil {
 		return err
 	}
 
 	// 获取菜单列表
 	var menus []model.LvMenu
 	if len(menuIds) > 0 {
-		global.LV_DB.Find(&menus, menuIds)
+		if err := db.Find(&menus, menuIds).Error; err != nil {
+			return err
+		}
+		if len(menus) != len(uniqueIds(menuIds)) {
+			return errors.New("菜单不存在")
+		}
 	}
 
-	// 更新角色的菜单关联
-	err := global.LV_DB.Model(&role).Association("Menus").Replace(menus)
+	// 更新角色的菜单关联（菜单只控制前端展示，接口鉴权由 SetRoleApis 负责）
+	return db.Model(&role).Association("Menus").Replace(menus)
+}
+
+// GetRoleApis 获取角色已分配的接口ID列表
+func (s *PermissionService) GetRoleApis(ctx context.Context, roleId uint) ([]uint, error) {
+	var role model.LvRole
+	err := global.LV_DB.WithContext(ctx).Preload("Apis").First(&role, roleId).Error
 	if err != nil {
+		return nil, err
+	}
+
+	var apiIds []uint
+	for _, api := range role.Apis {
+		apiIds = append(apiIds, api.ID)
+	}
+	return apiIds, nil
+}
+
+// SetRoleApis 设置角色的接口权限，并同步 Casbin 策略
+func (s *PermissionService) SetRoleApis(ctx context.Context, roleId uint, apiIds []uint) error {
+	db := global.LV_DB.WithContext(ctx)
+	var role model.LvRole
+	if err := db.First(&role, roleId).Error; err != nil {
 		return err
 	}
 
-	// 更新 Casbin 策略
-	s.updateCasbinPolicy(role.Keyword, menus)
+	var apis []model.LvApi
+	if len(apiIds) > 0 {
+		if err := db.Find(&apis, apiIds).Error; err != nil {
+			return err
+		}
+		if len(apis) != len(uniqueIds(apiIds)) {
+			return errors.New("接口不存在")
+		}
+	}
 
-	return nil
+	if err := db.Model(&role).Association("Apis").Replace(apis); err != nil {
+		return err
+	}
+
+	return s.updateCasbinPolicy(role, apis)
+}
+
+// SyncRolePolicy 根据角色当前绑定的接口重建其 Casbin 策略
+func (s *PermissionService) SyncRolePolicy(roleId uint) error {
+	var role model.LvRole
+	if err := global.LV_DB.Preload("Apis").First(&role, roleId).Error; err != nil {
+		return err
+	}
+	return s.updateCasbinPolicy(role, role.Apis)
 }
 
 // updateCasbinPolicy 更新 Casbin 权限策略
-func (s *PermissionService) updateCasbinPolicy(roleKeyword string, menus []model.LvMenu) {
+func (s *PermissionService) updateCasbinPolicy(role model.LvRole, apis []model.LvApi) error {
 	if global.LV_ENFORCER == nil {
-		return
+		return nil
 	}
 
 	// 删除旧策略
-	global.LV_ENFORCER.DeletePermissionsForUser(roleKeyword)
-
-	// 添加新策略
-	for _, menu := range menus {
-		if menu.Path != "" {
-			// 添加路由权限
-			global.LV_ENFORCER.AddPolicy(roleKeyword, menu.Path, "GET")
-			global.LV_ENFORCER.AddPolicy(roleKeyword, menu.Path, "POST")
-			global.LV_ENFORCER.AddPolicy(roleKeyword, menu.Path, "PUT")
-			global.LV_ENFORCER.AddPolicy(roleKeyword, menu.Path, "DELETE")
-		}
-		if menu.Permission != "" {
-			// 添加按钮权限
-			global.LV_ENFORCER.AddPolicy(roleKeyword, menu.Permission, "btn")
+	subject := RoleSubject(role)
+	if _, err := global.LV_ENFORCER.DeletePermissionsForUser(subject); err != nil {
+		return err
+	}
+
+	// 添加新策略（admin 始终保留通配策略，租户 admin 的平台接口由 PlatformAuth 拦截）
+	var rules [][]string
+	if role.Keyword == "admin" {
+		rules = append(rules, []string{subject, "/*", "*"})
+	}
+	for _, api := range apis {
+		rules = append(rules, []string{subject, api.Path, api.Method})
+	}
+	if len(rules) > 0 {
+		if _, err := global.LV_ENFORCER.AddPolicies(rules); err != nil {
+			return err
 		}
 	}
 
-	// 保存策略
-	global.LV_ENFORCER.SavePolicy()
+	return nil
 }
 
-// GetUserPermissions 获取用户的按钮权限列表
-func (s *PermissionService) GetUserPermissions(roleId uint) ([]string, error) {
-	var role model.LvRole
-	err := global.LV_DB.Preload("Menus").First(&role, roleId).Error
+// GetUserPermissions 获取用户的按钮权限列表（当前生效角色的并集）
+func (s *PermissionService) GetUserPermissions(userId, activeRoleId uint) ([]string, error) {
+	roles, err := EffectiveRoles(userId, activeRoleId, "Menus")
 	if err != nil {
 		return nil, err
 	}
 
 	// admin 角色拥有所有权限
-	if role.Keyword == "admin" {
+	if hasAdminRole(roles) {
 		return []string{"*"}, nil
 	}
 
 	var permissions []string
-	for _, menu := range role.Menus {
-		if menu.Permission != "" {
+	seen := make(map[string]bool)
+	for _, menu := range s.inheritedMenus(roles) {
+		if menu.Permission != "" && !seen[menu.Permission] {
+			seen[menu.Permission] = true
 			permissions = append(permissions, menu.Permission)
 		}
 	}
 	return permissions, nil
 }
 
-// GetUserMenus 获取用户可访问的菜单（根据角色）
-func (s *PermissionService) GetUserMenus(roleId uint) ([]model.LvMenu, error) {
-	var role model.LvRole
-	err := global.LV_DB.Preload("Menus").First(&role, roleId).Error
+// GetUserMenus 获取用户可访问的菜单（当前生效角色的并集）
+func (s *PermissionService) GetUserMenus(userId, activeRoleId uint) ([]model.LvMenu, error) {
+	roles, err := EffectiveRoles(userId, activeRoleId, "Menus")
 	if err != nil {
 		return nil, err
 	}
 
 	// admin 角色返回所有菜单
-	if role.Keyword == "admin" {
-		var allMenus []model.LvMenu
-		global.LV_DB.Order("sort ASC").Find(&allMenus)
-		return allMenus, nil
+	var menus []model.LvMenu
+	if hasAdminRole(roles) {
+		global.LV_DB.Order("sort ASC").Find(&menus)
+	} else {
+		menus = s.inheritedMenus(roles)
+		sort.SliceStable(menus, func(i, j int) bool {
+			return menus[i].Sort < menus[j].Sort
+		})
+	}
+
+	// 平台级菜单仅对平台用户可见
+	if len(roles) > 0 && roles[0].TenantId != 0 {
+		visible := menus[:0]
+		for _, menu := range menus {
+			if !IsPlatformPath(menu.Path) {
+				visible = append(visible, menu)
+			}
+		}
+		menus = visible
+	}
+	return menus, nil
+}
+
+// inheritedMenus 获取各角色自身及其父角色链上的全部菜单（去重）
+func (s *PermissionService) inheritedMenus(roles []model.LvRole) []model.LvMenu {
+	var menus []model.LvMenu
+	seenMenus := make(map[uint]bool)
+	seenRoles := make(map[uint]bool)
+
+	for _, role := range roles {
+		for !seenRoles[role.ID] {
+			seenRoles[role.ID] = true
+			for _, menu := range role.Menus {
+				if !seenMenus[menu.ID] {
+					seenMenus[menu.ID] = true
+					menus = append(menus, menu)
+				}
+			}
+			if role.ParentId == 0 {
+				break
+			}
+			var parent model.LvRole
+			if err := global.LV_DB.Preload("Menus").First(&parent, role.ParentId).Error; err != nil {
+				break
+			}
+			role = parent
+		}
 	}
+	return menus
+}
 
-	return role.Menus, nil
+// hasAdminRole 是否包含超级管理员角色
+func hasAdminRole(roles []model.LvRole) bool {
+	for _, role := range roles {
+		if role.Keyword == "admin" {
+			return true
+		}
+	}
+	return false
 }
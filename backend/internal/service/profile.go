@@ -0,0 +1,60 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"go-lv-vue-admin/internal/global"
+	"go-lv-vue-admin/internal/model"
+	"go-lv-vue-admin/pkg/utils"
+
+	"gorm.io/gorm"
+)
+
+type ProfileService struct{}
+
+// GetProfile 获取个人信息
+func (s *ProfileService) GetProfile(userId uint) (*model.LvUser, error) {
+	var user model.LvUser
+	err := global.LV_DB.Preload("Role").First(&user, userId).Error
+	return &user, err
+}
+
+// UpdateProfile 更新个人资料
+func (s *ProfileService) UpdateProfile(ctx context.Context, userId uint, nickname, email, phone, avatar string) error {
+	return global.LV_DB.WithContext(ctx).Model(&model.LvUser{}).Where("id = ?", userId).Updates(map[string]interface{}{
+		"nickname": nickname,
+		"email":    email,
+		"phone":    phone,
+		"avatar":   avatar,
+	}).Error
+}
+
+// ChangePassword 修改密码，成功后吊销该用户除当前会话外的全部会话
+func (s *ProfileService) ChangePassword(ctx context.Context, userId uint, oldPassword, newPassword, currentSessionId string) error {
+	var user model.LvUser
+	if err := global.LV_DB.First(&user, userId).Error; err != nil {
+		return errors.New("用户不存在")
+	}
+	if userSource(&user) != SourceLocal {
+		return errors.New("外部账号请在身份源中修改密码")
+	}
+
+	// 使用 bcrypt 验证原密码
+	if !utils.CheckPassword(oldPassword, user.Password) {
+		return errors.New("原密码错误")
+	}
+
+	// 校验密码策略并保存
+	policyService := PasswordPolicyService{}
+	if err := policyService.Validate(userId, user.Username, newPassword); err != nil {
+		return err
+	}
+	if err := global.LV_DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return policyService.SetPassword(tx, userId, newPassword)
+	}); err != nil {
+		return err
+	}
+
+	sessionService := UserSessionService{}
+	return sessionService.RevokeUserSessions(userId, currentSessionId)
+}
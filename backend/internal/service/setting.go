@@ -1,44 +1,73 @@
 package service
 
 import (
+	"context"
+	"errors"
 	"go-lv-vue-admin/internal/global"
 	"go-lv-vue-admin/internal/model"
+	"go-lv-vue-admin/pkg/utils"
+	"strings"
+
+	"gorm.io/gorm"
 )
 
+// 设置不走租户回调：TenantId 为 0 的是全局默认值，tenantId 对应的租户设置覆盖同名的全局设置
 type SettingService struct{}
 
 // GetAllSettings 获取所有设置
-func (s *SettingService) GetAllSettings() (map[string]interface{}, error) {
-	var settings []model.LvSetting
-	if err := global.LV_DB.Find(&settings).Error; err != nil {
-		return nil, err
-	}
-
-	result := make(map[string]interface{})
-	for _, setting := range settings {
-		result[setting.Key] = setting.Value
-	}
-	return result, nil
+func (s *SettingService) GetAllSettings(tenantId uint) (map[string]interface{}, error) {
+	return s.loadSettings(tenantId, nil)
 }
 
-// GetSetting 获取单个设置
-func (s *SettingService) GetSetting(key string) (string, error) {
+// GetSetting 获取单个设置，租户未设置时使用全局值
+func (s *SettingService) GetSetting(tenantId uint, key string) (string, error) {
 	var setting model.LvSetting
-	if err := global.LV_DB.Where("`key` = ?", key).First(&setting).Error; err != nil {
+	err := global.LV_DB.Where("`key` = ? AND tenant_id IN ?", key, []uint{0, tenantId}).
+		Order("tenant_id DESC").First(&setting).Error
+	if err != nil {
 		return "", err
 	}
 	return setting.Value, nil
 }
 
-// UpdateSetting 更新设置
-func (s *SettingService) UpdateSetting(key, value string) error {
-	return global.LV_DB.Model(&model.LvSetting{}).Where("`key` = ?", key).Update("value", value).Error
+// UpdateSetting 更新设置，租户第一次修改某项设置时创建该租户的覆盖值
+func (s *SettingService) UpdateSetting(ctx context.Context, tenantId uint, key, value string) error {
+	// 携带操作人用于审计，但不按租户过滤（需要读取全局默认值）
+	db := global.LV_DB.WithContext(utils.WithoutTenant(ctx))
+	if tenantId == 0 {
+		return db.Model(&model.LvSetting{}).Where("tenant_id = ? AND `key` = ?", 0, key).Update("value", value).Error
+	}
+	// 密码策略作用于整个平台，租户不能单独修改
+	if strings.HasPrefix(key, "password_") {
+		return errors.New("密码策略只能由平台管理员设置")
+	}
+
+	var defaults model.LvSetting
+	if err := db.Where("tenant_id = ? AND `key` = ?", 0, key).First(&defaults).Error; err != nil {
+		return errors.New("设置项不存在: " + key)
+	}
+
+	var setting model.LvSetting
+	err := db.Where("tenant_id = ? AND `key` = ?", tenantId, key).First(&setting).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return db.Create(&model.LvSetting{
+			TenantId:    tenantId,
+			Key:         key,
+			Value:       value,
+			Name:        defaults.Name,
+			Description: defaults.Description,
+		}).Error
+	}
+	if err != nil {
+		return err
+	}
+	return db.Model(&setting).Update("value", value).Error
 }
 
 // BatchUpdateSettings 批量更新设置
-func (s *SettingService) BatchUpdateSettings(settings map[string]string) error {
+func (s *SettingService) BatchUpdateSettings(ctx context.Context, tenantId uint, settings map[string]string) error {
 	for key, value := range settings {
-		if err := s.UpdateSetting(key, value); err != nil {
+		if err := s.UpdateSetting(ctx, tenantId, key, value); err != nil {
 			return err
 		}
 	}
@@ -46,10 +75,18 @@ func (s *SettingService) BatchUpdateSettings(settings map[string]string) error {
 }
 
 // GetPublicSettings 获取公开设置（无需登录即可获取）
-func (s *SettingService) GetPublicSettings() (map[string]interface{}, error) {
-	publicKeys := []string{"site_name", "site_logo", "site_footer"}
+func (s *SettingService) GetPublicSettings(tenantId uint) (map[string]interface{}, error) {
+	return s.loadSettings(tenantId, []string{"site_name", "site_logo", "site_footer"})
+}
+
+// loadSettings 读取全局设置并用租户设置覆盖，keys 为空时读取全部
+func (s *SettingService) loadSettings(tenantId uint, keys []string) (map[string]interface{}, error) {
+	db := global.LV_DB.Where("tenant_id IN ?", []uint{0, tenantId})
+	if len(keys) > 0 {
+		db = db.Where("`key` IN ?", keys)
+	}
 	var settings []model.LvSetting
-	if err := global.LV_DB.Where("`key` IN ?", publicKeys).Find(&settings).Error; err != nil {
+	if err := db.Order("tenant_id ASC").Find(&settings).Error; err != nil {
 		return nil, err
 	}
 
@@ -0,0 +1,104 @@
+package service
+
+import (
+	"errors"
+	"go-lv-vue-admin/internal/global"
+	"go-lv-vue-admin/internal/model"
+)
+
+type SystemApiService struct{}
+
+// GetApiList 获取接口列表
+func (s *SystemApiService) GetApiList(page, pageSize int, path, method, apiGroup string) ([]model.LvApi, int64, error) {
+	var apis []model.LvApi
+	var total int64
+
+	db := global.LV_DB.Model(&model.LvApi{})
+
+	if path != "" {
+		db = db.Where("path LIKE ?", "%"+path+"%")
+	}
+	if method != "" {
+		db = db.Where("method = ?", method)
+	}
+	if apiGroup != "" {
+		db = db.Where("api_group = ?", apiGroup)
+	}
+
+	db.Count(&total)
+
+	offset := (page - 1) * pageSize
+	err := db.Order("api_group ASC, path ASC").Offset(offset).Limit(pageSize).Find(&apis).Error
+
+	return apis, total, err
+}
+
+// GetAllApis 获取全部接口（用于角色授权）
+func (s *SystemApiService) GetAllApis() ([]model.LvApi, error) {
+	var apis []model.LvApi
+	err := global.LV_DB.Order("api_group ASC, path ASC").Find(&apis).Error
+	return apis, err
+}
+
+// CreateApi 创建接口
+func (s *SystemApiService) CreateApi(api *model.LvApi) error {
+	var count int64
+	global.LV_DB.Model(&model.LvApi{}).Where("path = ? AND method = ?", api.Path, api.Method).Count(&count)
+	if count > 0 {
+		return errors.New("该接口已存在")
+	}
+	return global.LV_DB.Create(api).Error
+}
+
+// UpdateApi 更新接口，并同步已绑定角色的 Casbin 策略
+func (s *SystemApiService) UpdateApi(api *model.LvApi) error {
+	var count int64
+	global.LV_DB.Model(&model.LvApi{}).Where("path = ? AND method = ? AND id <> ?", api.Path, api.Method, api.ID).Count(&count)
+	if count > 0 {
+		return errors.New("该接口已存在")
+	}
+
+	err := global.LV_DB.Model(&model.LvApi{}).Where("id = ?", api.ID).Updates(map[string]interface{}{
+		"path":        api.Path,
+		"method":      api.Method,
+		"api_group":   api.ApiGroup,
+		"description": api.Description,
+	}).Error
+	if err != nil {
+		return err
+	}
+	return s.syncRolePolicies(s.boundRoleIds(api.ID))
+}
+
+// DeleteApi 删除接口，并解除角色绑定
+// 接口按 path + method 唯一，直接物理删除，以便之后重新创建或由路由重新同步
+func (s *SystemApiService) DeleteApi(id uint) error {
+	roleIds := s.boundRoleIds(id)
+
+	if err := global.LV_DB.Exec("DELETE FROM lv_role_apis WHERE lv_api_id = ?", id).Error; err != nil {
+		return err
+	}
+	if err := global.LV_DB.Unscoped().Delete(&model.LvApi{}, id).Error; err != nil {
+		return err
+	}
+
+	return s.syncRolePolicies(roleIds)
+}
+
+// syncRolePolicies 重建指定角色的 Casbin 策略
+func (s *SystemApiService) syncRolePolicies(roleIds []uint) error {
+	permissionService := PermissionService{}
+	for _, roleId := range roleIds {
+		if err := permissionService.SyncRolePolicy(roleId); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// boundRoleIds 获取绑定了该接口的角色ID
+func (s *SystemApiService) boundRoleIds(apiId uint) []uint {
+	var roleIds []uint
+	global.LV_DB.Table("lv_role_apis").Where("lv_api_id = ?", apiId).Pluck("lv_role_id", &roleIds)
+	return roleIds
+}
@@ -0,0 +1,122 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"go-lv-vue-admin/internal/global"
+	"go-lv-vue-admin/internal/model"
+
+	"gorm.io/gorm"
+)
+
+type SystemDeptService struct{}
+
+// GetDeptTree 获取部门树
+func (s *SystemDeptService) GetDeptTree(ctx context.Context) ([]model.LvDept, error) {
+	var depts []model.LvDept
+	if err := global.LV_DB.WithContext(ctx).Order("sort ASC, id ASC").Find(&depts).Error; err != nil {
+		return nil, err
+	}
+	return buildDeptTree(depts, 0), nil
+}
+
+// buildDeptTree 构建部门树
+func buildDeptTree(depts []model.LvDept, parentId uint) []model.LvDept {
+	var tree []model.LvDept
+	for _, dept := range depts {
+		if dept.ParentId == parentId {
+			children := buildDeptTree(depts, dept.ID)
+			if len(children) > 0 {
+				dept.Children = children
+			}
+			tree = append(tree, dept)
+		}
+	}
+	return tree
+}
+
+// CreateDept 创建部门
+func (s *SystemDeptService) CreateDept(ctx context.Context, dept *model.LvDept) error {
+	if err := s.checkParent(ctx, 0, dept.ParentId); err != nil {
+		return err
+	}
+	return global.LV_DB.WithContext(ctx).Create(dept).Error
+}
+
+// UpdateDept 更新部门（不修改上级部门，调整层级请使用 MoveDept）
+func (s *SystemDeptService) UpdateDept(ctx context.Context, dept *model.LvDept) error {
+	return global.LV_DB.WithContext(ctx).Model(&model.LvDept{}).Where("id = ?", dept.ID).Updates(map[string]interface{}{
+		"name":   dept.Name,
+		"leader": dept.Leader,
+		"phone":  dept.Phone,
+		"sort":   dept.Sort,
+		"status": dept.Status,
+	}).Error
+}
+
+// MoveDept 移动部门到新的上级部门下
+func (s *SystemDeptService) MoveDept(ctx context.Context, id, parentId uint, sort int) error {
+	var dept model.LvDept
+	if err := global.LV_DB.WithContext(ctx).First(&dept, id).Error; err != nil {
+		return errors.New("部门不存在")
+	}
+	if err := s.checkParent(ctx, id, parentId); err != nil {
+		return err
+	}
+	return global.LV_DB.WithContext(ctx).Model(&dept).Updates(map[string]interface{}{
+		"parent_id": parentId,
+		"sort":      sort,
+	}).Error
+}
+
+// DeleteDept 删除部门，存在下级部门或用户时不允许删除
+func (s *SystemDeptService) DeleteDept(ctx context.Context, id uint) error {
+	db := global.LV_DB.WithContext(ctx)
+	var dept model.LvDept
+	if err := db.Select("id").First(&dept, id).Error; err != nil {
+		return errors.New("部门不存在")
+	}
+
+	var count int64
+	db.Model(&model.LvDept{}).Where("parent_id = ?", id).Count(&count)
+	if count > 0 {
+		return errors.New("请先删除下级部门")
+	}
+
+	db.Model(&model.LvUser{}).Where("dept_id = ?", id).Count(&count)
+	if count > 0 {
+		return errors.New("该部门下还有用户，无法删除")
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Table("lv_role_depts").Where("lv_dept_id = ?", id).Delete(nil).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&model.LvDept{}, id).Error
+	})
+}
+
+// checkParent 校验上级部门存在，且不能是自身或自身的下级部门
+func (s *SystemDeptService) checkParent(ctx context.Context, deptId, parentId uint) error {
+	if parentId == 0 {
+		return nil
+	}
+	if parentId == deptId {
+		return errors.New("上级部门不能是自身")
+	}
+
+	var count int64
+	global.LV_DB.WithContext(ctx).Model(&model.LvDept{}).Where("id = ?", parentId).Count(&count)
+	if count == 0 {
+		return errors.New("上级部门不存在")
+	}
+
+	if deptId != 0 {
+		for _, id := range deptSubtreeIds(deptId) {
+			if id == parentId {
+				return errors.New("不能移动到自身的下级部门")
+			}
+		}
+	}
+	return nil
+}
@@ -1,6 +1,7 @@
 package service
 
 import (
+	"context"
 	"errors"
 	"go-lv-vue-admin/internal/global"
 	"go-lv-vue-admin/internal/model"
@@ -34,13 +35,13 @@ func buildMenuTree(menus []model.LvMenu, parentId uint) []model.LvMenu {
 }
 
 // CreateMenu 创建菜单
-func (s *SystemMenuService) CreateMenu(menu *model.LvMenu) error {
-	return global.LV_DB.Create(menu).Error
+func (s *SystemMenuService) CreateMenu(ctx context.Context, menu *model.LvMenu) error {
+	return global.LV_DB.WithContext(ctx).Create(menu).Error
 }
 
 // UpdateMenu 更新菜单
-func (s *SystemMenuService) UpdateMenu(menu *model.LvMenu) error {
-	return global.LV_DB.Model(&model.LvMenu{}).Where("id = ?", menu.ID).Updates(map[string]interface{}{
+func (s *SystemMenuService) UpdateMenu(ctx context.Context, menu *model.LvMenu) error {
+	return global.LV_DB.WithContext(ctx).Model(&model.LvMenu{}).Where("id = ?", menu.ID).Updates(map[string]interface{}{
 		"parent_id":  menu.ParentId,
 		"title":      menu.Title,
 		"path":       menu.Path,
@@ -56,12 +57,12 @@ func (s *SystemMenuService) UpdateMenu(menu *model.LvMenu) error {
 }
 
 // DeleteMenu 删除菜单
-func (s *SystemMenuService) DeleteMenu(id uint) error {
+func (s *SystemMenuService) DeleteMenu(ctx context.Context, id uint) error {
 	// 检查是否有子菜单
 	var count int64
 	global.LV_DB.Model(&model.LvMenu{}).Where("parent_id = ?", id).Count(&count)
 	if count > 0 {
 		return errors.New("请先删除子菜单")
 	}
-	return global.LV_DB.Delete(&model.LvMenu{}, id).Error
+	return global.LV_DB.WithContext(ctx).Delete(&model.LvMenu{}, id).Error
 }
@@ -1,54 +1,204 @@
 package service
 
 import (
+	"context"
 	"errors"
 	"go-lv-vue-admin/internal/global"
 	"go-lv-vue-admin/internal/model"
+
+	"gorm.io/gorm"
 )
 
 type SystemRoleService struct{}
 
 // GetRoleList 获取角色列表
-func (s *SystemRoleService) GetRoleList() ([]model.LvRole, error) {
+func (s *SystemRoleService) GetRoleList(ctx context.Context) ([]model.LvRole, error) {
 	var roles []model.LvRole
-	err := global.LV_DB.Order("sort ASC").Find(&roles).Error
+	err := global.LV_DB.WithContext(ctx).Order("sort ASC").Find(&roles).Error
 	return roles, err
 }
 
 // CreateRole 创建角色
-func (s *SystemRoleService) CreateRole(role *model.LvRole) error {
+func (s *SystemRoleService) CreateRole(ctx context.Context, role *model.LvRole) error {
 	// 检查角色标识是否已存在
 	var count int64
-	global.LV_DB.Model(&model.LvRole{}).Where("keyword = ?", role.Keyword).Count(&count)
+	global.LV_DB.WithContext(ctx).Model(&model.LvRole{}).Where("keyword = ?", role.Keyword).Count(&count)
 	if count > 0 {
 		return errors.New("角色标识已存在")
 	}
-	return global.LV_DB.Create(role).Error
+	if err := s.checkParent(ctx, 0, role.ParentId); err != nil {
+		return err
+	}
+	if err := global.LV_DB.WithContext(ctx).Create(role).Error; err != nil {
+		return err
+	}
+	return s.syncRoleInheritance(*role, role.ParentId)
 }
 
 // UpdateRole 更新角色
-func (s *SystemRoleService) UpdateRole(role *model.LvRole) error {
-	return global.LV_DB.Model(&model.LvRole{}).Where("id = ?", role.ID).Updates(map[string]interface{}{
-		"name":   role.Name,
-		"desc":   role.Desc,
-		"status": role.Status,
-		"sort":   role.Sort,
+func (s *SystemRoleService) UpdateRole(ctx context.Context, role *model.LvRole) error {
+	if err := s.checkParent(ctx, role.ID, role.ParentId); err != nil {
+		return err
+	}
+
+	var current model.LvRole
+	if err := global.LV_DB.WithContext(ctx).First(&current, role.ID).Error; err != nil {
+		return err
+	}
+
+	err := global.LV_DB.WithContext(ctx).Model(&model.LvRole{}).Where("id = ?", role.ID).Updates(map[string]interface{}{
+		"parent_id":   role.ParentId,
+		"name":        role.Name,
+		"desc":        role.Desc,
+		"status":      role.Status,
+		"sort":        role.Sort,
+		"require_mfa": role.RequireMfa,
 	}).Error
+	if err != nil {
+		return err
+	}
+	if err := s.syncRoleInheritance(current, role.ParentId); err != nil {
+		return err
+	}
+	// 停用或启用角色后，重建拥有该角色的用户的 g 规则
+	if current.Status != role.Status {
+		return SyncRoleUsers(role.ID)
+	}
+	return nil
 }
 
 // DeleteRole 删除角色
-func (s *SystemRoleService) DeleteRole(id uint) error {
-	// 不允许删除 ID 为 1 的管理员角色
-	if id == 1 {
+func (s *SystemRoleService) DeleteRole(ctx context.Context, id uint) error {
+	var role model.LvRole
+	if err := global.LV_DB.WithContext(ctx).First(&role, id).Error; err != nil {
+		return err
+	}
+	// 不允许删除管理员角色（ID 为 1 的平台管理员及各租户的 admin）
+	if id == 1 || role.Keyword == "admin" {
 		return errors.New("不能删除管理员角色")
 	}
 
 	// 检查是否有用户使用该角色
 	var count int64
 	global.LV_DB.Model(&model.LvUser{}).Where("role_id = ?", id).Count(&count)
+	if count == 0 {
+		global.LV_DB.Table("lv_user_roles").
+			Joins("JOIN lv_users ON lv_users.id = lv_user_roles.lv_user_id AND lv_users.deleted_at IS NULL").
+			Where("lv_user_roles.lv_role_id = ?", id).Count(&count)
+	}
 	if count > 0 {
 		return errors.New("该角色下还有用户，无法删除")
 	}
 
-	return global.LV_DB.Delete(&model.LvRole{}, id).Error
+	// 检查是否有子角色继承该角色
+	global.LV_DB.Model(&model.LvRole{}).Where("parent_id = ?", id).Count(&count)
+	if count > 0 {
+		return errors.New("该角色被其他角色继承，无法删除")
+	}
+
+	if err := global.LV_DB.Delete(&role).Error; err != nil {
+		return err
+	}
+
+	// 清理 Casbin 中该角色的策略和继承关系
+	if global.LV_ENFORCER != nil {
+		subject := RoleSubject(role)
+		if _, err := global.LV_ENFORCER.DeletePermissionsForUser(subject); err != nil {
+			return err
+		}
+		if _, err := global.LV_ENFORCER.DeleteRolesForUser(subject); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetRoleDataScope 获取角色数据范围及自定义部门
+func (s *SystemRoleService) GetRoleDataScope(ctx context.Context, id uint) (int, []uint, error) {
+	var role model.LvRole
+	if err := global.LV_DB.WithContext(ctx).Preload("Depts").First(&role, id).Error; err != nil {
+		return 0, nil, err
+	}
+	deptIds := make([]uint, 0, len(role.Depts))
+	for _, dept := range role.Depts {
+		deptIds = append(deptIds, dept.ID)
+	}
+	return role.DataScope, deptIds, nil
+}
+
+// SetRoleDataScope 设置角色数据范围，仅自定义范围保留部门列表
+func (s *SystemRoleService) SetRoleDataScope(ctx context.Context, id uint, dataScope int, deptIds []uint) error {
+	if dataScope < DataScopeAll || dataScope > DataScopeCustomDepts {
+		return errors.New("无效的数据范围")
+	}
+
+	var role model.LvRole
+	if err := global.LV_DB.WithContext(ctx).First(&role, id).Error; err != nil {
+		return err
+	}
+
+	var depts []model.LvDept
+	if dataScope == DataScopeCustomDepts && len(deptIds) > 0 {
+		if err := global.LV_DB.WithContext(ctx).Where("id IN ?", deptIds).Find(&depts).Error; err != nil {
+			return err
+		}
+	}
+
+	return global.LV_DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&role).Update("data_scope", dataScope).Error; err != nil {
+			return err
+		}
+		return tx.Model(&role).Association("Depts").Replace(depts)
+	})
+}
+
+// checkParent 校验父角色存在且不会形成循环继承
+func (s *SystemRoleService) checkParent(ctx context.Context, roleId, parentId uint) error {
+	if parentId == 0 {
+		return nil
+	}
+	if parentId == roleId {
+		return errors.New("父角色不能是自身")
+	}
+
+	// 沿父链向上查找，若遇到自身则说明形成循环；已有数据中存在的环也要能终止
+	visited := make(map[uint]bool)
+	current := parentId
+	for current != 0 {
+		if visited[current] {
+			return errors.New("父角色的继承关系存在循环")
+		}
+		visited[current] = true
+		var parent model.LvRole
+		if err := global.LV_DB.WithContext(ctx).Select("id", "parent_id").First(&parent, current).Error; err != nil {
+			return errors.New("父角色不存在")
+		}
+		if roleId != 0 && parent.ParentId == roleId {
+			return errors.New("不能继承自己的子角色")
+		}
+		current = parent.ParentId
+	}
+	return nil
+}
+
+// syncRoleInheritance 同步角色继承关系到 Casbin g 规则
+func (s *SystemRoleService) syncRoleInheritance(role model.LvRole, parentId uint) error {
+	if global.LV_ENFORCER == nil {
+		return nil
+	}
+
+	subject := RoleSubject(role)
+	if _, err := global.LV_ENFORCER.DeleteRolesForUser(subject); err != nil {
+		return err
+	}
+	if parentId == 0 {
+		return nil
+	}
+
+	var parent model.LvRole
+	if err := global.LV_DB.Select("tenant_id", "keyword").First(&parent, parentId).Error; err != nil {
+		return err
+	}
+	_, err := global.LV_ENFORCER.AddRoleForUser(subject, RoleSubject(parent))
+	return err
 }
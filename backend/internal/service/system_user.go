@@ -1,20 +1,38 @@
 package service
 
 import (
+	"context"
 	"errors"
 	"go-lv-vue-admin/internal/global"
 	"go-lv-vue-admin/internal/model"
 	"go-lv-vue-admin/pkg/utils"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
 )
 
 type SystemUserService struct{}
 
-// GetUserList 获取用户列表
-func (s *SystemUserService) GetUserList(page, pageSize int, username, phone string, status *int) ([]model.LvUser, int64, error) {
+// GetUserList 获取用户列表，按操作人的数据范围过滤
+func (s *SystemUserService) GetUserList(ctx context.Context, operatorId, activeRoleId uint, page, pageSize int, username, phone string, status *int, deptId uint) ([]model.LvUser, int64, error) {
 	var users []model.LvUser
 	var total int64
 
-	db := global.LV_DB.Model(&model.LvUser{})
+	db := s.listQuery(ctx, operatorId, activeRoleId, username, phone, status, deptId)
+	db.Count(&total)
+
+	offset := (page - 1) * pageSize
+	err := db.Preload("Role").Preload("Roles").Offset(offset).Limit(pageSize).Find(&users).Error
+
+	return users, total, err
+}
+
+// listQuery 按列表筛选条件和操作人的数据范围构造查询，列表和导出共用
+func (s *SystemUserService) listQuery(ctx context.Context, operatorId, activeRoleId uint, username, phone string, status *int, deptId uint) *gorm.DB {
+	db := global.LV_DB.WithContext(ctx).Model(&model.LvUser{}).Scopes(DataScope(operatorId, activeRoleId, DataScopeColumns{Dept: "dept_id", User: "id"}))
 
 	if username != "" {
 		db = db.Where("username LIKE ?", "%"+username+"%")
@@ -25,70 +43,321 @@ func (s *SystemUserService) GetUserList(page, pageSize int, username, phone stri
 	if status != nil {
 		db = db.Where("status = ?", *status)
 	}
+	// 按部门筛选时包含全部下级部门
+	if deptId != 0 {
+		db = db.Where("dept_id IN ?", deptSubtreeIds(deptId))
+	}
+	return db
+}
 
-	db.Count(&total)
+// ExportUsers 按列表的筛选条件导出用户
+func (s *SystemUserService) ExportUsers(operatorId, activeRoleId uint, username, phone string, status *int, deptId uint) Export {
+	return Export{
+		Type:    "user",
+		Name:    "用户列表",
+		Headers: []string{"ID", "用户名", "昵称", "邮箱", "手机号", "部门", "角色", "状态", "账号来源", "两步验证", "创建时间"},
+		Count: func(ctx context.Context) (int64, error) {
+			var total int64
+			err := s.listQuery(ctx, operatorId, activeRoleId, username, phone, status, deptId).Count(&total).Error
+			return total, err
+		},
+		Rows: func(ctx context.Context, write func(row []string) error) error {
+			var depts []model.LvDept
+			if err := global.LV_DB.WithContext(ctx).Select("id", "name").Find(&depts).Error; err != nil {
+				return err
+			}
+			deptNames := make(map[uint]string, len(depts))
+			for _, dept := range depts {
+				deptNames[dept.ID] = dept.Name
+			}
 
-	offset := (page - 1) * pageSize
-	err := db.Preload("Role").Offset(offset).Limit(pageSize).Find(&users).Error
-
-	return users, total, err
+			var users []model.LvUser
+			return s.listQuery(ctx, operatorId, activeRoleId, username, phone, status, deptId).Preload("Roles").
+				FindInBatches(&users, exportBatchSize, func(tx *gorm.DB, batch int) error {
+					for _, user := range users {
+						roleNames := make([]string, 0, len(user.Roles))
+						for _, role := range user.Roles {
+							roleNames = append(roleNames, role.Name)
+						}
+						statusText, totpText := "正常", "未启用"
+						if user.Status == 2 {
+							statusText = "冻结"
+						}
+						if user.TotpEnabled {
+							totpText = "已启用"
+						}
+						err := write([]string{
+							strconv.FormatUint(uint64(user.ID), 10),
+							user.Username,
+							user.Nickname,
+							user.Email,
+							user.Phone,
+							deptNames[user.DeptId],
+							strings.Join(roleNames, "、"),
+							statusText,
+							user.Source,
+							totpText,
+							user.CreatedAt.Format("2006-01-02 15:04:05"),
+						})
+						if err != nil {
+							return err
+						}
+					}
+					return nil
+				}).Error
+		},
+	}
 }
 
-// CreateUser 创建用户
-func (s *SystemUserService) CreateUser(user *model.LvUser) error {
-	// 检查用户名是否已存在
-	var count int64
-	global.LV_DB.Model(&model.LvUser{}).Where("username = ?", user.Username).Count(&count)
-	if count > 0 {
+// CreateUser 创建用户，只能分配操作人当前生效角色范围内的角色
+func (s *SystemUserService) CreateUser(ctx context.Context, operatorId, activeRoleId uint, user *model.LvUser) error {
+	db := global.LV_DB.WithContext(ctx)
+
+	if err := checkAssignableRoles(operatorId, activeRoleId, assignedRoleIds(user)); err != nil {
+		return err
+	}
+
+	// 检查用户名是否已存在（多租户模式下租户内唯一）
+	if usernameExists(db, user.Username) {
 		return errors.New("用户名已存在")
 	}
 
+	// 校验密码策略
+	policyService := PasswordPolicyService{}
+	if err := policyService.Validate(0, user.Username, user.Password); err != nil {
+		return err
+	}
+
+	if err := db.Transaction(func(tx *gorm.DB) error {
+		return createUser(tx, user)
+	}); err != nil {
+		return err
+	}
+	return SyncUserRoles(user.ID)
+}
+
+// usernameExists 用户名是否已存在（多租户模式下租户内唯一）
+func usernameExists(db *gorm.DB, username string) bool {
+	var count int64
+	db.Model(&model.LvUser{}).Where("username = ?", username).Count(&count)
+	return count > 0
+}
+
+// createUser 在事务中创建用户：加密密码、写入角色和密码历史，调用方负责校验和同步 Casbin
+func createUser(tx *gorm.DB, user *model.LvUser) error {
 	// 使用 bcrypt 加密密码
 	hashedPassword, err := utils.HashPassword(user.Password)
 	if err != nil {
 		return err
 	}
+	now := time.Now()
 	user.Password = hashedPassword
-	return global.LV_DB.Create(user).Error
+	user.PasswordChangedAt = &now
+	user.Roles = nil
+	if err := tx.Create(user).Error; err != nil {
+		return err
+	}
+	if err := SetUserRoles(tx, user, user.RoleIds); err != nil {
+		return err
+	}
+	policyService := PasswordPolicyService{}
+	return policyService.recordHistory(tx, user.ID, hashedPassword)
 }
 
-// UpdateUser 更新用户
-func (s *SystemUserService) UpdateUser(user *model.LvUser) error {
-	return global.LV_DB.Model(&model.LvUser{}).Where("id = ?", user.ID).Updates(map[string]interface{}{
-		"nickname": user.Nickname,
-		"email":    user.Email,
-		"phone":    user.Phone,
-		"role_id":  user.RoleId,
-		"status":   user.Status,
-	}).Error
+// UpdateUser 更新用户，roleIds 为用户的全部角色，只能更新操作人数据范围内的用户、分配操作人拥有的角色
+func (s *SystemUserService) UpdateUser(ctx context.Context, operatorId, activeRoleId uint, user *model.LvUser) error {
+	db := global.LV_DB.WithContext(ctx)
+
+	// 角色关联表不带租户，先确认用户属于当前租户且在数据范围内
+	if _, err := scopedUser(ctx, operatorId, activeRoleId, user.ID); err != nil {
+		return err
+	}
+	if err := checkAssignableRoles(operatorId, activeRoleId, assignedRoleIds(user)); err != nil {
+		return err
+	}
+
+	if err := db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&model.LvUser{}).Where("id = ?", user.ID).Updates(map[string]interface{}{
+			"nickname": user.Nickname,
+			"email":    user.Email,
+			"phone":    user.Phone,
+			"role_id":  user.RoleId,
+			"dept_id":  user.DeptId,
+			"status":   user.Status,
+		}).Error
+		if err != nil {
+			return err
+		}
+		return SetUserRoles(tx, user, user.RoleIds)
+	}); err != nil {
+		return err
+	}
+	return SyncUserRoles(user.ID)
 }
 
-// DeleteUser 删除用户
-func (s *SystemUserService) DeleteUser(id uint) error {
+// DeleteUser 删除操作人数据范围内的用户
+func (s *SystemUserService) DeleteUser(ctx context.Context, operatorId, activeRoleId, id uint) error {
 	// 不允许删除 ID 为 1 的超级管理员
 	if id == 1 {
 		return errors.New("不能删除超级管理员")
 	}
-	return global.LV_DB.Delete(&model.LvUser{}, id).Error
+	if _, err := scopedUser(ctx, operatorId, activeRoleId, id); err != nil {
+		return err
+	}
+	if err := global.LV_DB.WithContext(ctx).Delete(&model.LvUser{}, id).Error; err != nil {
+		return err
+	}
+	return SyncUserRoles(id)
 }
 
-// ResetPassword 重置密码
-func (s *SystemUserService) ResetPassword(id uint, newPassword string) error {
+// ResetPassword 重置密码，newPassword 为空时生成符合密码策略的随机密码，返回重置后的密码
+// 只能重置操作人数据范围内的用户，重置后用户下次登录必须修改密码
+func (s *SystemUserService) ResetPassword(ctx context.Context, operatorId, activeRoleId, id uint, newPassword string) (string, error) {
 	// 不允许通过此接口修改超级管理员密码
 	if id == 1 {
-		return errors.New("不能通过此接口修改超级管理员密码")
+		return "", errors.New("不能通过此接口修改超级管理员密码")
 	}
-	// 使用 bcrypt 加密密码
-	hashedPassword, err := utils.HashPassword(newPassword)
+	user, err := scopedUser(ctx, operatorId, activeRoleId, id)
 	if err != nil {
+		return "", err
+	}
+	if userSource(user) != SourceLocal {
+		return "", errors.New("外部账号的密码由身份源管理，无法重置")
+	}
+
+	policyService := PasswordPolicyService{}
+	if newPassword == "" {
+		generated, err := policyService.GeneratePassword(id, user.Username)
+		if err != nil {
+			return "", err
+		}
+		newPassword = generated
+	} else if err := policyService.Validate(id, user.Username, newPassword); err != nil {
+		// 校验密码策略
+		return "", err
+	}
+	if err := global.LV_DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := policyService.SetPassword(tx, id, newPassword); err != nil {
+			return err
+		}
+		return policyService.RequireChange(tx, id)
+	}); err != nil {
+		return "", err
+	}
+
+	// 密码被重置后，该用户已登录的会话全部失效
+	sessionService := UserSessionService{}
+	return newPassword, sessionService.RevokeUserSessions(id, "")
+}
+
+// Impersonate 管理员模拟登录目标用户，只能模拟自己数据范围内、角色不超出自己当前角色的其他正常用户，返回目标用户及新建的模拟会话
+func (s *SystemUserService) Impersonate(ctx context.Context, operatorId, activeRoleId, targetId uint, ip, userAgent string) (*model.LvUser, *model.LvUserSession, error) {
+	if targetId == operatorId {
+		return nil, nil, errors.New("不能模拟自己")
+	}
+	if targetId == 1 {
+		return nil, nil, errors.New("不能模拟超级管理员")
+	}
+
+	var operator model.LvUser
+	if err := global.LV_DB.WithContext(ctx).First(&operator, operatorId).Error; err != nil {
+		return nil, nil, errors.New("用户不存在")
+	}
+	target, err := scopedUser(ctx, operatorId, activeRoleId, targetId)
+	if err != nil {
+		return nil, nil, err
+	}
+	if target.Status != 1 {
+		return nil, nil, errors.New("用户已被冻结")
+	}
+	if err := checkImpersonateRoles(operatorId, activeRoleId, target.ID); err != nil {
+		return nil, nil, err
+	}
+
+	sessionService := UserSessionService{}
+	session, err := sessionService.CreateImpersonationSession(&operator, target.ID, ip, userAgent)
+	if err != nil {
+		return nil, nil, err
+	}
+	global.LV_LOG.Info("impersonation started",
+		zap.String("impersonator", operator.Username), zap.String("target", target.Username), zap.String("session", session.SessionId))
+	return target, session, nil
+}
+
+// scopedUser 查找当前租户中、在操作人数据范围内的用户
+func scopedUser(ctx context.Context, operatorId, activeRoleId, id uint) (*model.LvUser, error) {
+	var user model.LvUser
+	err := global.LV_DB.WithContext(ctx).
+		Scopes(DataScope(operatorId, activeRoleId, DataScopeColumns{Dept: "dept_id", User: "id"})).
+		First(&user, id).Error
+	if err != nil {
+		return nil, errors.New("用户不存在或不在数据范围内")
+	}
+	return &user, nil
+}
+
+// checkImpersonateRoles 目标用户的角色必须都在操作人当前生效的角色中，防止通过模拟登录获得更高的权限
+// 操作人拥有 admin 角色时不限制
+func checkImpersonateRoles(operatorId, activeRoleId, targetId uint) error {
+	held, err := heldRoles(operatorId, activeRoleId)
+	if err != nil || held == nil {
+		return err
+	}
+
+	targetRoles, err := EffectiveRoles(targetId, 0)
+	if err != nil {
+		return err
+	}
+	for _, role := range targetRoles {
+		if !held[role.ID] {
+			return errors.New("目标用户拥有你没有的角色，不能模拟")
+		}
+	}
+	return nil
+}
+
+// checkAssignableRoles 只能为用户分配操作人当前生效的角色，防止通过创建、编辑用户授予更高的权限
+// 操作人拥有 admin 角色时不限制
+func checkAssignableRoles(operatorId, activeRoleId uint, roleIds []uint) error {
+	held, err := heldRoles(operatorId, activeRoleId)
+	if err != nil || held == nil {
 		return err
 	}
-	return global.LV_DB.Model(&model.LvUser{}).Where("id = ?", id).Update("password", hashedPassword).Error
+	for _, id := range roleIds {
+		if !held[id] {
+			return errors.New("不能分配你没有的角色")
+		}
+	}
+	return nil
+}
+
+// heldRoles 操作人当前生效的角色 ID 集合，拥有 admin 角色时返回 nil 表示不限制
+func heldRoles(operatorId, activeRoleId uint) (map[uint]bool, error) {
+	operatorRoles, err := EffectiveRoles(operatorId, activeRoleId)
+	if err != nil {
+		return nil, err
+	}
+	held := make(map[uint]bool, len(operatorRoles))
+	for _, role := range operatorRoles {
+		if role.Keyword == "admin" {
+			return nil, nil
+		}
+		held[role.ID] = true
+	}
+	return held, nil
+}
+
+// assignedRoleIds 与 SetUserRoles 一致：未传 roleIds 时以 role_id 作为唯一角色
+func assignedRoleIds(user *model.LvUser) []uint {
+	if len(user.RoleIds) == 0 && user.RoleId != 0 {
+		return []uint{user.RoleId}
+	}
+	return user.RoleIds
 }
 
 // GetRoleList 获取角色列表
-func (s *SystemUserService) GetRoleList() ([]model.LvRole, error) {
+func (s *SystemUserService) GetRoleList(ctx context.Context) ([]model.LvRole, error) {
 	var roles []model.LvRole
-	err := global.LV_DB.Find(&roles).Error
+	err := global.LV_DB.WithContext(ctx).Find(&roles).Error
 	return roles, err
 }
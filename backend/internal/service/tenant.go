@@ -0,0 +1,210 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"go-lv-vue-admin/internal/global"
+	"go-lv-vue-admin/internal/model"
+	"go-lv-vue-admin/pkg/utils"
+	"regexp"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// tenantCodePattern 租户编码，登录时填写
+var tenantCodePattern = regexp.MustCompile(`^[a-zA-Z0-9_-]{2,32}$`)
+
+// platformPaths 平台级资源（租户、全局菜单、接口、代码生成），仅平台用户可管理
+var platformPaths = []string{"/platform", "/system/menu", "/system/api", "/tool/generator"}
+
+type TenantService struct{}
+
+// IsPlatformPath 菜单路径是否属于平台级资源
+func IsPlatformPath(path string) bool {
+	for _, prefix := range platformPaths {
+		if path == prefix || strings.HasPrefix(path, prefix+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// RoleSubject 角色在 Casbin 中的主体：平台角色为关键字本身，租户角色加租户前缀以免同名角色共享策略
+func RoleSubject(role model.LvRole) string {
+	if role.TenantId == 0 {
+		return role.Keyword
+	}
+	return fmt.Sprintf("t%d:%s", role.TenantId, role.Keyword)
+}
+
+// ResolveTenant 根据登录时填写的租户编码获取租户 ID，未开启多租户或编码为空时为平台（0）
+func (s *TenantService) ResolveTenant(code string) (uint, error) {
+	if !global.LV_CONFIG.Tenant.Enable || code == "" {
+		return 0, nil
+	}
+	var tenant model.LvTenant
+	if err := global.LV_DB.Where("code = ?", code).First(&tenant).Error; err != nil {
+		return 0, errors.New("租户不存在")
+	}
+	if err := s.checkTenant(&tenant); err != nil {
+		return 0, err
+	}
+	return tenant.ID, nil
+}
+
+// CheckTenantActive 校验租户可用（刷新 Token 时调用），平台始终可用
+func (s *TenantService) CheckTenantActive(tenantId uint) error {
+	if !global.LV_CONFIG.Tenant.Enable || tenantId == 0 {
+		return nil
+	}
+	var tenant model.LvTenant
+	if err := global.LV_DB.First(&tenant, tenantId).Error; err != nil {
+		return errors.New("租户不存在")
+	}
+	return s.checkTenant(&tenant)
+}
+
+func (s *TenantService) checkTenant(tenant *model.LvTenant) error {
+	if tenant.Status != 1 {
+		return errors.New("租户已停用")
+	}
+	if tenant.ExpiresAt != nil && time.Now().After(*tenant.ExpiresAt) {
+		return errors.New("租户已到期")
+	}
+	return nil
+}
+
+// GetTenantList 获取租户列表
+func (s *TenantService) GetTenantList(page, pageSize int, name string) ([]model.LvTenant, int64, error) {
+	var tenants []model.LvTenant
+	var total int64
+
+	db := global.LV_DB.Model(&model.LvTenant{})
+	if name != "" {
+		db = db.Where("name LIKE ? OR code LIKE ?", "%"+name+"%", "%"+name+"%")
+	}
+
+	db.Count(&total)
+
+	offset := (page - 1) * pageSize
+	err := db.Order("id DESC").Offset(offset).Limit(pageSize).Find(&tenants).Error
+	return tenants, total, err
+}
+
+// CreateTenant 创建租户，同时初始化根部门、租户管理员角色和管理员账号
+func (s *TenantService) CreateTenant(tenant *model.LvTenant, adminUsername, adminPassword string) error {
+	if !global.LV_CONFIG.Tenant.Enable {
+		return errors.New("未开启多租户模式")
+	}
+	if !tenantCodePattern.MatchString(tenant.Code) {
+		return errors.New("租户编码只能包含字母、数字、下划线和中划线（2-32 位）")
+	}
+	if adminUsername == "" {
+		return errors.New("请填写租户管理员账号")
+	}
+
+	var count int64
+	global.LV_DB.Unscoped().Model(&model.LvTenant{}).Where("code = ?", tenant.Code).Count(&count)
+	if count > 0 {
+		return errors.New("租户编码已存在")
+	}
+
+	policyService := PasswordPolicyService{}
+	if err := policyService.Validate(0, adminUsername, adminPassword); err != nil {
+		return err
+	}
+	hashedPassword, err := utils.HashPassword(adminPassword)
+	if err != nil {
+		return err
+	}
+
+	tenant.ID = 0
+	if tenant.Status == 0 {
+		tenant.Status = 1
+	}
+
+	var role model.LvRole
+	var admin model.LvUser
+	err = global.LV_DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(tenant).Error; err != nil {
+			return err
+		}
+
+		dept := model.LvDept{TenantId: tenant.ID, Name: tenant.Name, Sort: 1, Status: 1}
+		if err := tx.Create(&dept).Error; err != nil {
+			return err
+		}
+
+		role = model.LvRole{
+			TenantId:  tenant.ID,
+			Name:      "管理员",
+			Keyword:   "admin",
+			Desc:      "租户管理员",
+			Status:    1,
+			Sort:      1,
+			DataScope: DataScopeAll,
+		}
+		if err := tx.Create(&role).Error; err != nil {
+			return err
+		}
+
+		now := time.Now()
+		admin = model.LvUser{
+			TenantId:          tenant.ID,
+			Username:          adminUsername,
+			Password:          hashedPassword,
+			Nickname:          "租户管理员",
+			Status:            1,
+			RoleId:            role.ID,
+			DeptId:            dept.ID,
+			PasswordChangedAt: &now,
+		}
+		if err := tx.Create(&admin).Error; err != nil {
+			return err
+		}
+		if err := SetUserRoles(tx, &admin, []uint{role.ID}); err != nil {
+			return err
+		}
+		return policyService.recordHistory(tx, admin.ID, hashedPassword)
+	})
+	if err != nil {
+		return err
+	}
+
+	permissionService := PermissionService{}
+	if err := permissionService.SyncRolePolicy(role.ID); err != nil {
+		return err
+	}
+	return SyncUserRoles(admin.ID)
+}
+
+// UpdateTenant 更新租户（编码不可修改），停用后该租户的在线会话全部下线
+func (s *TenantService) UpdateTenant(tenant *model.LvTenant) error {
+	err := global.LV_DB.Model(&model.LvTenant{}).Where("id = ?", tenant.ID).Updates(map[string]interface{}{
+		"name":       tenant.Name,
+		"contact":    tenant.Contact,
+		"phone":      tenant.Phone,
+		"status":     tenant.Status,
+		"expires_at": tenant.ExpiresAt,
+		"remark":     tenant.Remark,
+	}).Error
+	if err != nil {
+		return err
+	}
+	if tenant.Status != 1 {
+		sessionService := UserSessionService{}
+		return sessionService.RevokeTenantSessions(tenant.ID)
+	}
+	return nil
+}
+
+// DeleteTenant 删除租户（软删除，保留租户数据），该租户的在线会话全部下线
+func (s *TenantService) DeleteTenant(id uint) error {
+	if err := global.LV_DB.Delete(&model.LvTenant{}, id).Error; err != nil {
+		return err
+	}
+	sessionService := UserSessionService{}
+	return sessionService.RevokeTenantSessions(id)
+}
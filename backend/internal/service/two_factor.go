@@ -0,0 +1,246 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"go-lv-vue-admin/internal/global"
+	"go-lv-vue-admin/internal/model"
+	"go-lv-vue-admin/pkg/utils"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type TwoFactorService struct{}
+
+// recoveryCodeCount 每次生成的恢复码数量
+const recoveryCodeCount = 10
+
+// TwoFactorStatus 当前用户的两步验证状态
+type TwoFactorStatus struct {
+	Enabled           bool  `json:"enabled"`
+	Required          bool  `json:"required"` // 所属角色是否强制启用
+	RecoveryCodesLeft int64 `json:"recoveryCodesLeft"`
+}
+
+// GetStatus 获取两步验证状态
+func (s *TwoFactorService) GetStatus(userId uint) (*TwoFactorStatus, error) {
+	var user model.LvUser
+	if err := global.LV_DB.First(&user, userId).Error; err != nil {
+		return nil, err
+	}
+
+	status := &TwoFactorStatus{
+		Enabled:  user.TotpEnabled,
+		Required: s.roleRequiresMfa(user.ID),
+	}
+	global.LV_DB.Model(&model.LvUserRecoveryCode{}).Where("user_id = ? AND used_at IS NULL", userId).Count(&status.RecoveryCodesLeft)
+	return status, nil
+}
+
+// SetupRequired 角色要求两步验证但用户尚未绑定
+func (s *TwoFactorService) SetupRequired(user *model.LvUser) bool {
+	return !user.TotpEnabled && s.roleRequiresMfa(user.ID)
+}
+
+// Setup 生成新的 TOTP 密钥（未确认前不生效），返回密钥与 otpauth URI
+func (s *TwoFactorService) Setup(userId uint) (secret, uri string, err error) {
+	var user model.LvUser
+	if err = global.LV_DB.First(&user, userId).Error; err != nil {
+		return "", "", errors.New("用户不存在")
+	}
+	if user.TotpEnabled {
+		return "", "", errors.New("已启用两步验证，如需更换请先关闭")
+	}
+
+	secret, err = utils.GenerateTOTPSecret()
+	if err != nil {
+		return "", "", err
+	}
+	if err = global.LV_DB.Model(&model.LvUser{}).Where("id = ?", userId).Update("totp_secret", secret).Error; err != nil {
+		return "", "", err
+	}
+	return secret, utils.TOTPURI(s.issuer(user.TenantId), user.Username, secret), nil
+}
+
+// Enable 使用动态码确认绑定，返回一次性恢复码（仅此时可见明文）
+func (s *TwoFactorService) Enable(userId uint, code string) ([]string, error) {
+	var user model.LvUser
+	if err := global.LV_DB.First(&user, userId).Error; err != nil {
+		return nil, errors.New("用户不存在")
+	}
+	if user.TotpEnabled {
+		return nil, errors.New("已启用两步验证")
+	}
+	if user.TotpSecret == "" {
+		return nil, errors.New("请先生成两步验证密钥")
+	}
+
+	step, ok := utils.ValidateTOTP(user.TotpSecret, code, time.Now())
+	if !ok {
+		return nil, errors.New("验证码错误")
+	}
+
+	var codes []string
+	err := global.LV_DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&model.LvUser{}).Where("id = ?", userId).Updates(map[string]interface{}{
+			"totp_enabled":   true,
+			"totp_last_step": step,
+		}).Error; err != nil {
+			return err
+		}
+		var err error
+		codes, err = s.generateRecoveryCodes(tx, userId)
+		return err
+	})
+	return codes, err
+}
+
+// Disable 关闭两步验证，需提供动态码或恢复码；角色强制启用时不允许关闭
+func (s *TwoFactorService) Disable(userId uint, code string) error {
+	var user model.LvUser
+	if err := global.LV_DB.First(&user, userId).Error; err != nil {
+		return errors.New("用户不存在")
+	}
+	if !user.TotpEnabled {
+		return errors.New("未启用两步验证")
+	}
+	if s.roleRequiresMfa(user.ID) {
+		return errors.New("当前角色要求启用两步验证，无法关闭")
+	}
+	if !s.Verify(&user, code) {
+		return errors.New("验证码错误")
+	}
+	return s.Reset(context.Background(), userId)
+}
+
+// RegenerateRecoveryCodes 重新生成恢复码（旧恢复码全部作废），需提供动态码
+func (s *TwoFactorService) RegenerateRecoveryCodes(userId uint, code string) ([]string, error) {
+	var user model.LvUser
+	if err := global.LV_DB.First(&user, userId).Error; err != nil {
+		return nil, errors.New("用户不存在")
+	}
+	if !user.TotpEnabled {
+		return nil, errors.New("未启用两步验证")
+	}
+	if !s.verifyTOTP(&user, code) {
+		return nil, errors.New("验证码错误")
+	}
+
+	var codes []string
+	err := global.LV_DB.Transaction(func(tx *gorm.DB) error {
+		var err error
+		codes, err = s.generateRecoveryCodes(tx, userId)
+		return err
+	})
+	return codes, err
+}
+
+// Reset 清除用户的两步验证（管理员为丢失设备的用户重置时使用）
+func (s *TwoFactorService) Reset(ctx context.Context, userId uint) error {
+	// 只能重置当前租户内的用户
+	var user model.LvUser
+	if err := global.LV_DB.WithContext(ctx).Select("id").First(&user, userId).Error; err != nil {
+		return err
+	}
+	return global.LV_DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&model.LvUser{}).Where("id = ?", userId).Updates(map[string]interface{}{
+			"totp_secret":    "",
+			"totp_enabled":   false,
+			"totp_last_step": 0,
+		}).Error; err != nil {
+			return err
+		}
+		return tx.Unscoped().Where("user_id = ?", userId).Delete(&model.LvUserRecoveryCode{}).Error
+	})
+}
+
+// Verify 校验动态码或恢复码，成功后该动态码/恢复码不可再次使用
+func (s *TwoFactorService) Verify(user *model.LvUser, code string) bool {
+	if !user.TotpEnabled {
+		return false
+	}
+	if s.verifyTOTP(user, code) {
+		return true
+	}
+	return s.useRecoveryCode(user.ID, code)
+}
+
+// verifyTOTP 校验动态码，同一时间步的动态码只能使用一次（防重放）
+func (s *TwoFactorService) verifyTOTP(user *model.LvUser, code string) bool {
+	step, ok := utils.ValidateTOTP(user.TotpSecret, code, time.Now())
+	if !ok {
+		return false
+	}
+	result := global.LV_DB.Model(&model.LvUser{}).
+		Where("id = ? AND totp_last_step < ?", user.ID, step).
+		Update("totp_last_step", step)
+	return result.Error == nil && result.RowsAffected == 1
+}
+
+// useRecoveryCode 消耗一个未使用的恢复码
+func (s *TwoFactorService) useRecoveryCode(userId uint, code string) bool {
+	code = normalizeRecoveryCode(code)
+	if code == "" {
+		return false
+	}
+	result := global.LV_DB.Model(&model.LvUserRecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userId, utils.HashToken(code)).
+		Update("used_at", time.Now())
+	return result.Error == nil && result.RowsAffected == 1
+}
+
+// generateRecoveryCodes 生成新的恢复码并替换旧恢复码
+func (s *TwoFactorService) generateRecoveryCodes(tx *gorm.DB, userId uint) ([]string, error) {
+	if err := tx.Unscoped().Where("user_id = ?", userId).Delete(&model.LvUserRecoveryCode{}).Error; err != nil {
+		return nil, err
+	}
+
+	codes := make([]string, recoveryCodeCount)
+	records := make([]model.LvUserRecoveryCode, recoveryCodeCount)
+	for i := range codes {
+		b := make([]byte, 5)
+		if _, err := rand.Read(b); err != nil {
+			return nil, err
+		}
+		raw := hex.EncodeToString(b)
+		codes[i] = raw[:5] + "-" + raw[5:]
+		records[i] = model.LvUserRecoveryCode{UserId: userId, CodeHash: utils.HashToken(raw)}
+	}
+	if err := tx.Create(&records).Error; err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// roleRequiresMfa 用户的任一有效角色是否强制两步验证
+func (s *TwoFactorService) roleRequiresMfa(userId uint) bool {
+	roles, err := EffectiveRoles(userId, 0)
+	if err != nil {
+		return false
+	}
+	for _, role := range roles {
+		if role.RequireMfa {
+			return true
+		}
+	}
+	return false
+}
+
+// issuer 认证器应用中显示的发行方，取用户所在租户的系统名称
+func (s *TwoFactorService) issuer(tenantId uint) string {
+	settingService := SettingService{}
+	if name, err := settingService.GetSetting(tenantId, "site_name"); err == nil && name != "" {
+		return name
+	}
+	return "Go Lv Admin"
+}
+
+// normalizeRecoveryCode 忽略大小写与分隔符
+func normalizeRecoveryCode(code string) string {
+	code = strings.ToLower(strings.TrimSpace(code))
+	return strings.ReplaceAll(code, "-", "")
+}
@@ -1,36 +1,90 @@
 package service
 
 import (
+	"context"
 	"errors"
 	"go-lv-vue-admin/internal/global"
 	"go-lv-vue-admin/internal/model"
 	"go-lv-vue-admin/pkg/utils"
+	"time"
 
+	"github.com/golang-jwt/jwt/v5"
 	"gorm.io/gorm"
 )
 
 type UserService struct{}
 
-func (s *UserService) Login(u *model.LvUser) (userInter *model.LvUser, err error) {
+// Login 账号密码登录：已存在的用户只通过其来源对应的认证源校验，新用户依次尝试外部认证源并自动创建
+func (s *UserService) Login(ctx context.Context, u *model.LvUser) (userInter *model.LvUser, err error) {
 	if global.LV_DB == nil {
 		return nil, errors.New("db not initialized")
 	}
 
 	var user model.LvUser
-	err = global.LV_DB.Where("username = ?", u.Username).Preload("Role").First(&user).Error
+	err = global.LV_DB.WithContext(ctx).Select("id", "source").Where("username = ?", u.Username).First(&user).Error
 	if err == nil {
-		// 使用 bcrypt 验证密码
-		if !utils.CheckPassword(u.Password, user.Password) {
-			return nil, errors.New("password incorrect")
+		provider := findAuthProvider(userSource(&user))
+		if provider == nil {
+			return nil, ErrProviderDisabled
 		}
-		return &user, nil
+		return provider.Authenticate(ctx, u.Username, u.Password)
 	}
-	return nil, err
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	for _, provider := range authProviders() {
+		if provider.Name() == SourceLocal {
+			continue
+		}
+		userInter, err = provider.Authenticate(ctx, u.Username, u.Password)
+		if err == nil || !errors.Is(err, ErrInvalidCredentials) {
+			return userInter, err
+		}
+	}
+	return nil, ErrInvalidCredentials
 }
 
-func (s *UserService) CreateToken(user model.LvUser) (string, int64, error) {
+// CreateToken 为指定会话签发 Access Token（携带会话的生效角色），返回 Token 及其过期时间戳
+func (s *UserService) CreateToken(user model.LvUser, sessionId string) (string, int64, error) {
+	policyService := PasswordPolicyService{}
+	twoFactorService := TwoFactorService{}
+	sessionService := UserSessionService{}
+	session := sessionService.tokenState(sessionId)
 	j := utils.NewJWT()
 	claims := j.CreateClaims(utils.BaseClaims{
+		TenantId:         user.TenantId,
+		UserId:           user.ID,
+		Username:         user.Username,
+		RoleId:           user.RoleId,
+		ActiveRoleId:     session.ActiveRoleId,
+		PasswordExpired:  policyService.IsExpired(&user),
+		MfaSetupRequired: twoFactorService.SetupRequired(&user),
+	}, sessionId)
+
+	// 模拟会话：不受目标用户密码过期、未绑定两步验证的限制，Token 不超过会话有效期
+	if session.ImpersonatorId != 0 {
+		claims.ImpersonatorId = session.ImpersonatorId
+		claims.ImpersonatorName = session.ImpersonatorName
+		claims.PasswordExpired = false
+		claims.MfaSetupRequired = false
+		if session.ExpiresAt.Before(claims.ExpiresAt.Time) {
+			claims.ExpiresAt = jwt.NewNumericDate(session.ExpiresAt)
+		}
+	}
+
+	token, err := j.CreateToken(claims)
+	if err != nil {
+		return "", 0, err
+	}
+	return token, claims.ExpiresAt.Unix(), nil
+}
+
+// CreateMfaToken 密码校验通过后签发两步验证待完成 Token，需在 /base/login/2fa 换取正式 Token
+func (s *UserService) CreateMfaToken(user model.LvUser) (string, int64, error) {
+	j := utils.NewJWT()
+	claims := j.CreateMfaClaims(utils.BaseClaims{
+		TenantId: user.TenantId,
 		UserId:   user.ID,
 		Username: user.Username,
 		RoleId:   user.RoleId,
@@ -39,10 +93,7 @@ func (s *UserService) CreateToken(user model.LvUser) (string, int64, error) {
 	if err != nil {
 		return "", 0, err
 	}
-	// Calculate actual expiration timestamp
-	exp, _ := utils.ParseDuration(global.LV_CONFIG.JWT.ExpiresTime)
-	expiresAt := int64(exp.Seconds())
-	return token, expiresAt, nil
+	return token, claims.ExpiresAt.Unix(), nil
 }
 
 // Register (Optional MVP)
@@ -52,12 +103,24 @@ func (s *UserService) Register(u model.LvUser) (userInter model.LvUser, err erro
 	if !errors.Is(global.LV_DB.Where("username = ?", u.Username).First(&user).Error, gorm.ErrRecordNotFound) {
 		return userInter, errors.New("username already exists")
 	}
+	// 校验密码策略
+	policyService := PasswordPolicyService{}
+	if err := policyService.Validate(0, u.Username, u.Password); err != nil {
+		return userInter, err
+	}
 	// 使用 bcrypt 加密密码
 	hashedPassword, err := utils.HashPassword(u.Password)
 	if err != nil {
 		return userInter, err
 	}
+	now := time.Now()
 	u.Password = hashedPassword
-	err = global.LV_DB.Create(&u).Error
+	u.PasswordChangedAt = &now
+	err = global.LV_DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&u).Error; err != nil {
+			return err
+		}
+		return policyService.recordHistory(tx, u.ID, hashedPassword)
+	})
 	return u, err
 }
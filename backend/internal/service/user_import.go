@@ -0,0 +1,309 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go-lv-vue-admin/internal/global"
+	"go-lv-vue-admin/internal/model"
+	"go-lv-vue-admin/pkg/utils"
+	"io"
+	"net/mail"
+	"regexp"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// userImportMaxRows 单次导入的最大行数（不含表头）
+const userImportMaxRows = 2000
+
+// userImportHeaders 导入模板的列，带 * 的为必填；读取时按列名匹配，列的顺序不限
+var userImportHeaders = []string{"用户名*", "昵称", "密码*", "邮箱", "手机号", "角色*", "部门", "状态"}
+
+// phonePattern 中国大陆手机号
+var phonePattern = regexp.MustCompile(`^1[3-9]\d{9}$`)
+
+// UserImportError 一行数据的校验错误，Row 为文件中的行号（表头为第 1 行）
+type UserImportError struct {
+	Row      int    `json:"row"`
+	Username string `json:"username"`
+	Message  string `json:"message"`
+}
+
+// UserImportResult 导入结果，存在校验错误时不导入任何数据，并生成带错误信息的报告文件
+type UserImportResult struct {
+	Total   int                `json:"total"`
+	Created int                `json:"created"`
+	DryRun  bool               `json:"dryRun"`
+	Errors  []UserImportError  `json:"errors"`
+	Report  *model.LvExportJob `json:"report,omitempty"`
+}
+
+// UserImportTemplate 导入模板：表头和一行示例
+func (s *SystemUserService) UserImportTemplate() Export {
+	return Export{
+		Type:    "user_import_template",
+		Name:    "用户导入模板",
+		Headers: userImportHeaders,
+		Rows: func(ctx context.Context, write func(row []string) error) error {
+			return write([]string{"zhangsan", "张三", "Zhangsan@123", "zhangsan@example.com", "13800000000", "user", "研发部", "正常"})
+		},
+	}
+}
+
+// ImportUsers 从 CSV / XLSX 批量导入用户，逐行校验（用户名重复、角色关键字及权限、部门及数据范围、邮箱和手机号格式、密码策略）
+// 全部通过时在一个事务中创建；dryRun 只校验不导入
+func (s *SystemUserService) ImportUsers(ctx context.Context, operatorId, activeRoleId uint, format string, r io.Reader, dryRun bool) (*UserImportResult, error) {
+	rows, err := utils.ReadSheet(format, r)
+	if err != nil {
+		return nil, fmt.Errorf("读取文件失败: %w", err)
+	}
+	if len(rows) < 2 {
+		return nil, errors.New("文件中没有数据")
+	}
+	if len(rows)-1 > userImportMaxRows {
+		return nil, fmt.Errorf("单次最多导入 %d 条", userImportMaxRows)
+	}
+
+	columns := make(map[string]int, len(rows[0]))
+	for i, name := range rows[0] {
+		columns[strings.TrimSuffix(strings.TrimSpace(name), "*")] = i
+	}
+	for _, name := range userImportHeaders {
+		if !strings.HasSuffix(name, "*") {
+			continue
+		}
+		if _, ok := columns[strings.TrimSuffix(name, "*")]; !ok {
+			return nil, fmt.Errorf("缺少列: %s", name)
+		}
+	}
+
+	db := global.LV_DB.WithContext(ctx)
+	importer, err := newUserImporter(db, operatorId, activeRoleId)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &UserImportResult{DryRun: dryRun}
+	var users []model.LvUser
+	rowErrors := make(map[int]string)
+	for i, row := range rows[1:] {
+		cell := func(name string) string {
+			if index, ok := columns[name]; ok && index < len(row) {
+				return strings.TrimSpace(row[index])
+			}
+			return ""
+		}
+		if strings.TrimSpace(strings.Join(row, "")) == "" {
+			continue
+		}
+		result.Total++
+
+		user, problems := importer.parse(db, cell)
+		if len(problems) > 0 {
+			message := strings.Join(problems, "；")
+			rowErrors[i] = message
+			result.Errors = append(result.Errors, UserImportError{Row: i + 2, Username: user.Username, Message: message})
+			continue
+		}
+		users = append(users, user)
+	}
+
+	if len(result.Errors) > 0 {
+		exportService := ExportService{}
+		result.Report, err = exportService.SaveFile(ctx, operatorId, userImportReport(rows, columns["密码"], rowErrors), format)
+		if err != nil {
+			return nil, fmt.Errorf("生成校验报告失败: %w", err)
+		}
+		return result, nil
+	}
+	if dryRun {
+		return result, nil
+	}
+
+	if err := db.Transaction(func(tx *gorm.DB) error {
+		for i := range users {
+			if err := createUser(tx, &users[i]); err != nil {
+				return fmt.Errorf("导入用户 %s 失败: %w", users[i].Username, err)
+			}
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	for _, user := range users {
+		if err := SyncUserRoles(user.ID); err != nil {
+			return nil, err
+		}
+	}
+	result.Created = len(users)
+	return result, nil
+}
+
+// userImporter 导入时用到的角色、部门数据和已出现的用户名
+// heldRoles 为操作人可分配的角色（nil 表示不限制），scopedDepts 为操作人数据范围内的部门
+type userImporter struct {
+	roles       map[string]uint
+	depts       map[string][]uint
+	heldRoles   map[uint]bool
+	scopedDepts map[uint]bool
+	usernames   map[string]bool
+	policy      PasswordPolicyService
+}
+
+func newUserImporter(db *gorm.DB, operatorId, activeRoleId uint) (*userImporter, error) {
+	var roles []model.LvRole
+	if err := db.Select("id", "keyword").Find(&roles).Error; err != nil {
+		return nil, err
+	}
+	var depts []model.LvDept
+	if err := db.Select("id", "name").Find(&depts).Error; err != nil {
+		return nil, err
+	}
+	held, err := heldRoles(operatorId, activeRoleId)
+	if err != nil {
+		return nil, err
+	}
+	var scopedIds []uint
+	err = db.Model(&model.LvDept{}).
+		Scopes(DataScope(operatorId, activeRoleId, DataScopeColumns{Dept: "id"})).
+		Pluck("id", &scopedIds).Error
+	if err != nil {
+		return nil, err
+	}
+
+	importer := &userImporter{
+		roles:       make(map[string]uint, len(roles)),
+		depts:       make(map[string][]uint, len(depts)),
+		heldRoles:   held,
+		scopedDepts: make(map[uint]bool, len(scopedIds)),
+		usernames:   make(map[string]bool),
+	}
+	for _, role := range roles {
+		importer.roles[role.Keyword] = role.ID
+	}
+	for _, dept := range depts {
+		importer.depts[dept.Name] = append(importer.depts[dept.Name], dept.ID)
+	}
+	for _, id := range scopedIds {
+		importer.scopedDepts[id] = true
+	}
+	return importer, nil
+}
+
+// parse 校验一行数据并转换为用户，返回全部校验问题
+func (im *userImporter) parse(db *gorm.DB, cell func(name string) string) (model.LvUser, []string) {
+	user := model.LvUser{
+		Username: cell("用户名"),
+		Nickname: cell("昵称"),
+		Password: cell("密码"),
+		Email:    cell("邮箱"),
+		Phone:    cell("手机号"),
+		Status:   1,
+		Source:   SourceLocal,
+	}
+	var problems []string
+
+	switch {
+	case user.Username == "":
+		problems = append(problems, "用户名不能为空")
+	case im.usernames[user.Username]:
+		problems = append(problems, "用户名在文件中重复")
+	case usernameExists(db, user.Username):
+		problems = append(problems, "用户名已存在")
+	}
+	im.usernames[user.Username] = true
+
+	if user.Password == "" {
+		problems = append(problems, "密码不能为空")
+	} else if err := im.policy.Validate(0, user.Username, user.Password); err != nil {
+		problems = append(problems, err.Error())
+	}
+	if user.Nickname == "" {
+		user.Nickname = user.Username
+	}
+	if user.Email != "" {
+		if addr, err := mail.ParseAddress(user.Email); err != nil || addr.Address != user.Email {
+			problems = append(problems, "邮箱格式不正确")
+		}
+	}
+	if user.Phone != "" && !phonePattern.MatchString(user.Phone) {
+		problems = append(problems, "手机号格式不正确")
+	}
+
+	keywords := strings.FieldsFunc(cell("角色"), func(r rune) bool { return r == ',' || r == '，' || r == '、' })
+	if len(keywords) == 0 {
+		problems = append(problems, "角色不能为空")
+	}
+	for _, keyword := range keywords {
+		roleId, ok := im.roles[strings.TrimSpace(keyword)]
+		if !ok {
+			problems = append(problems, fmt.Sprintf("角色 %s 不存在", strings.TrimSpace(keyword)))
+			continue
+		}
+		if im.heldRoles != nil && !im.heldRoles[roleId] {
+			problems = append(problems, fmt.Sprintf("不能分配你没有的角色 %s", strings.TrimSpace(keyword)))
+			continue
+		}
+		user.RoleIds = append(user.RoleIds, roleId)
+	}
+
+	if name := cell("部门"); name != "" {
+		switch ids := im.depts[name]; len(ids) {
+		case 0:
+			problems = append(problems, fmt.Sprintf("部门 %s 不存在", name))
+		case 1:
+			if !im.scopedDepts[ids[0]] {
+				problems = append(problems, fmt.Sprintf("部门 %s 不在数据范围内", name))
+				break
+			}
+			user.DeptId = ids[0]
+		default:
+			problems = append(problems, fmt.Sprintf("部门 %s 存在重名", name))
+		}
+	}
+
+	switch status := cell("状态"); status {
+	case "", "正常", "1":
+		user.Status = 1
+	case "冻结", "2":
+		user.Status = 2
+	default:
+		problems = append(problems, "状态只能为正常或冻结")
+	}
+	return user, problems
+}
+
+// userImportReport 校验报告：原始数据加上错误信息列，报告保存到存储驱动，密码列不写入
+func userImportReport(rows [][]string, passwordColumn int, rowErrors map[int]string) Export {
+	width := 0
+	for _, row := range rows {
+		width = max(width, len(row))
+	}
+	headers := append(padRow(rows[0], width), "错误信息")
+	return Export{
+		Type:    "user_import_report",
+		Name:    "用户导入校验报告",
+		Headers: headers,
+		Rows: func(ctx context.Context, write func(row []string) error) error {
+			for i, row := range rows[1:] {
+				row = padRow(row, width)
+				if row[passwordColumn] != "" {
+					row[passwordColumn] = utils.RedactedValue
+				}
+				if err := write(append(row, rowErrors[i])); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	}
+}
+
+// padRow 补齐到指定列数，返回新的切片
+func padRow(row []string, width int) []string {
+	padded := make([]string, width, width+1)
+	copy(padded, row)
+	return padded
+}
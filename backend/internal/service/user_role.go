@@ -0,0 +1,221 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"go-lv-vue-admin/internal/global"
+	"go-lv-vue-admin/internal/model"
+	"strconv"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// UserSubject 用户在 Casbin 中的主体，通过 g 规则关联其全部有效角色
+func UserSubject(userId uint) string {
+	return fmt.Sprintf("user:%d", userId)
+}
+
+// UserRoleIds 获取用户拥有的全部角色 ID（含主角色）
+func UserRoleIds(userId uint) []uint {
+	var roleIds []uint
+	global.LV_DB.Table("lv_user_roles").Where("lv_user_id = ?", userId).Pluck("lv_role_id", &roleIds)
+
+	var user model.LvUser
+	if err := global.LV_DB.Select("id", "role_id").First(&user, userId).Error; err == nil && user.RoleId != 0 {
+		for _, id := range roleIds {
+			if id == user.RoleId {
+				return roleIds
+			}
+		}
+		roleIds = append(roleIds, user.RoleId)
+	}
+	return roleIds
+}
+
+// EffectiveRoles 获取用户当前生效的角色（仅启用状态）
+// activeRoleId 不为 0 时只返回该角色，用户已不再拥有该角色时返回空
+func EffectiveRoles(userId, activeRoleId uint, preloads ...string) ([]model.LvRole, error) {
+	roleIds := UserRoleIds(userId)
+	if activeRoleId != 0 {
+		held := false
+		for _, id := range roleIds {
+			if id == activeRoleId {
+				held = true
+				break
+			}
+		}
+		if !held {
+			return nil, nil
+		}
+		roleIds = []uint{activeRoleId}
+	}
+	if len(roleIds) == 0 {
+		return nil, nil
+	}
+
+	db := global.LV_DB.Where("id IN ? AND status = ?", roleIds, 1)
+	for _, preload := range preloads {
+		db = db.Preload(preload)
+	}
+	var roles []model.LvRole
+	err := db.Order("sort ASC").Find(&roles).Error
+	return roles, err
+}
+
+// SetUserRoles 设置用户的角色，roleIds 为空时使用主角色；主角色不在列表中时改为列表中的第一个
+func SetUserRoles(tx *gorm.DB, user *model.LvUser, roleIds []uint) error {
+	if len(roleIds) == 0 && user.RoleId != 0 {
+		roleIds = []uint{user.RoleId}
+	}
+	if len(roleIds) == 0 {
+		return errors.New("请至少选择一个角色")
+	}
+
+	var roles []model.LvRole
+	if err := tx.Where("id IN ?", roleIds).Find(&roles).Error; err != nil {
+		return err
+	}
+	if len(roles) != len(uniqueIds(roleIds)) {
+		return errors.New("角色不存在")
+	}
+
+	primary := roles[0].ID
+	for _, role := range roles {
+		if role.ID == user.RoleId {
+			primary = role.ID
+			break
+		}
+	}
+	user.RoleId = primary
+	if err := tx.Model(&model.LvUser{}).Where("id = ?", user.ID).Update("role_id", primary).Error; err != nil {
+		return err
+	}
+
+	var oldIds []uint
+	tx.Table("lv_user_roles").Where("lv_user_id = ?", user.ID).Pluck("lv_role_id", &oldIds)
+	if err := tx.Model(&model.LvUser{Model: gorm.Model{ID: user.ID}}).Association("Roles").Replace(roles); err != nil {
+		return err
+	}
+	auditUserRoles(tx, user, oldIds, roles)
+	return nil
+}
+
+// auditUserRoles 角色关联表不经过模型的审计回调，角色发生变化时单独记录一条审计日志
+func auditUserRoles(tx *gorm.DB, user *model.LvUser, oldIds []uint, roles []model.LvRole) {
+	changed := len(uniqueIds(oldIds)) != len(roles)
+	held := make(map[uint]bool, len(oldIds))
+	for _, id := range oldIds {
+		held[id] = true
+	}
+	for _, role := range roles {
+		if !held[role.ID] {
+			changed = true
+		}
+	}
+	if !changed {
+		return
+	}
+
+	var oldRoles []model.LvRole
+	if len(oldIds) > 0 {
+		tx.Unscoped().Select("id", "name").Where("id IN ?", oldIds).Order("id ASC").Find(&oldRoles)
+	}
+	WriteAuditLogs(tx, []model.LvAuditLog{{
+		TenantId: user.TenantId,
+		Entity:   user.AuditEntity(),
+		EntityId: strconv.FormatUint(uint64(user.ID), 10),
+		Action:   model.AuditUpdate,
+		Changes:  model.AuditChanges{{Field: "roles", Before: roleNames(oldRoles), After: roleNames(roles)}},
+	}})
+}
+
+// roleNames 角色名称列表，用于审计日志展示
+func roleNames(roles []model.LvRole) string {
+	names := make([]string, 0, len(roles))
+	for _, role := range roles {
+		names = append(names, role.Name)
+	}
+	return strings.Join(names, ", ")
+}
+
+// SyncUserRoles 根据用户当前的有效角色重建其 Casbin g 规则
+func SyncUserRoles(userId uint) error {
+	if global.LV_ENFORCER == nil {
+		return nil
+	}
+
+	subject := UserSubject(userId)
+	if _, err := global.LV_ENFORCER.DeleteRolesForUser(subject); err != nil {
+		return err
+	}
+
+	// 用户已删除时只清理
+	var user model.LvUser
+	if err := global.LV_DB.Select("id").First(&user, userId).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return err
+	}
+
+	roles, err := EffectiveRoles(userId, 0)
+	if err != nil {
+		return err
+	}
+	for _, role := range roles {
+		if _, err := global.LV_ENFORCER.AddRoleForUser(subject, RoleSubject(role)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SyncRoleUsers 重建拥有该角色的全部用户的 g 规则（角色状态变化或删除时调用）
+func SyncRoleUsers(roleId uint) error {
+	var userIds []uint
+	global.LV_DB.Table("lv_user_roles").Where("lv_role_id = ?", roleId).Pluck("lv_user_id", &userIds)
+
+	var primaryIds []uint
+	global.LV_DB.Model(&model.LvUser{}).Where("role_id = ?", roleId).Pluck("id", &primaryIds)
+
+	for _, userId := range uniqueIds(append(userIds, primaryIds...)) {
+		if err := SyncUserRoles(userId); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SyncAllUserRoles 启动时补齐 lv_user_roles（历史数据只有主角色）并重建全部用户的 g 规则
+func SyncAllUserRoles() error {
+	err := global.LV_DB.Exec(`INSERT INTO lv_user_roles (lv_user_id, lv_role_id)
+		SELECT id, role_id FROM lv_users
+		WHERE role_id > 0 AND deleted_at IS NULL
+		AND NOT EXISTS (SELECT 1 FROM lv_user_roles ur WHERE ur.lv_user_id = lv_users.id)`).Error
+	if err != nil {
+		return err
+	}
+
+	var userIds []uint
+	global.LV_DB.Model(&model.LvUser{}).Pluck("id", &userIds)
+	for _, userId := range userIds {
+		if err := SyncUserRoles(userId); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// uniqueIds ID 去重，保持原有顺序
+func uniqueIds(ids []uint) []uint {
+	seen := make(map[uint]bool, len(ids))
+	result := make([]uint, 0, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			result = append(result, id)
+		}
+	}
+	return result
+}
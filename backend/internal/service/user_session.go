@@ -0,0 +1,358 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"go-lv-vue-admin/internal/global"
+	"go-lv-vue-admin/internal/model"
+	"go-lv-vue-admin/pkg/utils"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type UserSessionService struct{}
+
+// SessionItem 会话列表项
+type SessionItem struct {
+	model.LvUserSession
+	Username string `json:"username"`
+	Nickname string `json:"nickname"`
+	Current  bool   `json:"current"` // 是否为当前请求所用的会话
+}
+
+// lastSeenInterval 最后活跃时间的写库间隔，避免每个请求都更新数据库
+const lastSeenInterval = time.Minute
+
+// lastSeenCache 记录每个会话最近一次写入最后活跃时间的时刻
+// 超过 lastSeenInterval 的条目已不影响节流，由 sweepLastSeen 定期清除（过期、轮换或批量吊销的会话不会一直留在内存中）
+var (
+	lastSeenCache   sync.Map
+	lastSeenSweptAt time.Time
+	lastSeenSweepMu sync.Mutex
+)
+
+var (
+	ErrRefreshTokenInvalid = errors.New("refresh token 无效或已过期")
+	ErrRefreshTokenReused  = errors.New("refresh token 已被使用，会话已吊销")
+)
+
+// CreateSession 登录成功后创建会话，返回会话及明文 Refresh Token
+func (s *UserSessionService) CreateSession(userId uint, device, ip, userAgent string) (*model.LvUserSession, string, error) {
+	refreshToken, err := utils.RandomToken(32)
+	if err != nil {
+		return nil, "", err
+	}
+	ep, _ := utils.ParseDuration(global.LV_CONFIG.JWT.RefreshExpiresTime)
+
+	if device == "" {
+		device = parseDevice(userAgent)
+	}
+	session := &model.LvUserSession{
+		SessionId:        uuid.NewString(),
+		UserId:           userId,
+		RefreshTokenHash: utils.HashToken(refreshToken),
+		Device:           device,
+		Ip:               ip,
+		UserAgent:        userAgent,
+		LastSeenAt:       time.Now(),
+		ExpiresAt:        time.Now().Add(ep),
+	}
+	if err := global.LV_DB.Create(session).Error; err != nil {
+		return nil, "", err
+	}
+	return session, refreshToken, nil
+}
+
+// CreateImpersonationSession 管理员模拟登录时为目标用户创建会话
+// 会话有效期固定且不返回 Refresh Token，到期后需重新发起模拟
+func (s *UserSessionService) CreateImpersonationSession(impersonator *model.LvUser, targetId uint, ip, userAgent string) (*model.LvUserSession, error) {
+	// 随机生成但不下发，模拟会话无法刷新
+	refreshToken, err := utils.RandomToken(32)
+	if err != nil {
+		return nil, err
+	}
+	ep, err := utils.ParseDuration(global.LV_CONFIG.JWT.ImpersonateExpires)
+	if err != nil || ep <= 0 {
+		ep = 30 * time.Minute
+	}
+
+	session := &model.LvUserSession{
+		SessionId:        uuid.NewString(),
+		UserId:           targetId,
+		RefreshTokenHash: utils.HashToken(refreshToken),
+		Device:           "模拟登录",
+		Ip:               ip,
+		UserAgent:        userAgent,
+		LastSeenAt:       time.Now(),
+		ExpiresAt:        time.Now().Add(ep),
+		ImpersonatorId:   impersonator.ID,
+		ImpersonatorName: impersonator.Username,
+	}
+	if err := global.LV_DB.Create(session).Error; err != nil {
+		return nil, err
+	}
+	return session, nil
+}
+
+// RotateRefreshToken 校验 Refresh Token 并轮换为新的 Refresh Token
+// 若提交的是已被轮换掉的旧 Token，视为泄露，直接吊销整个会话
+func (s *UserSessionService) RotateRefreshToken(refreshToken, ip, userAgent string) (*model.LvUserSession, string, error) {
+	hash := utils.HashToken(refreshToken)
+
+	var session model.LvUserSession
+	err := global.LV_DB.Where("refresh_token_hash = ?", hash).First(&session).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		var reused model.LvUserSession
+		if global.LV_DB.Where("prev_token_hash = ?", hash).First(&reused).Error == nil {
+			s.RevokeSession(reused.SessionId)
+			return nil, "", ErrRefreshTokenReused
+		}
+		return nil, "", ErrRefreshTokenInvalid
+	}
+	if err != nil {
+		return nil, "", err
+	}
+	if session.RevokedAt != nil || time.Now().After(session.ExpiresAt) {
+		return nil, "", ErrRefreshTokenInvalid
+	}
+
+	newToken, err := utils.RandomToken(32)
+	if err != nil {
+		return nil, "", err
+	}
+	ep, _ := utils.ParseDuration(global.LV_CONFIG.JWT.RefreshExpiresTime)
+	expiresAt := time.Now().Add(ep)
+
+	// 以旧摘要为条件更新，防止并发刷新时同一 Token 被使用两次
+	result := global.LV_DB.Model(&model.LvUserSession{}).
+		Where("id = ? AND refresh_token_hash = ?", session.ID, hash).
+		Updates(map[string]interface{}{
+			"refresh_token_hash": utils.HashToken(newToken),
+			"prev_token_hash":    hash,
+			"ip":                 ip,
+			"user_agent":         userAgent,
+			"last_seen_at":       time.Now(),
+			"expires_at":         expiresAt,
+		})
+	if result.Error != nil {
+		return nil, "", result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, "", ErrRefreshTokenInvalid
+	}
+
+	session.ExpiresAt = expiresAt
+	return &session, newToken, nil
+}
+
+// IsSessionActive 判断会话是否有效（未吊销且 Refresh Token 未过期）
+func (s *UserSessionService) IsSessionActive(sessionId string) bool {
+	if sessionId == "" {
+		return false
+	}
+	var count int64
+	global.LV_DB.Model(&model.LvUserSession{}).
+		Where("session_id = ? AND revoked_at IS NULL AND expires_at > ?", sessionId, time.Now()).
+		Count(&count)
+	return count > 0
+}
+
+// TouchSession 更新会话最后活跃时间和 IP（按 lastSeenInterval 节流）
+func (s *UserSessionService) TouchSession(sessionId, ip string) {
+	now := time.Now()
+	if last, ok := lastSeenCache.Load(sessionId); ok && now.Sub(last.(time.Time)) < lastSeenInterval {
+		return
+	}
+	lastSeenCache.Store(sessionId, now)
+	sweepLastSeen(now)
+
+	global.LV_DB.Model(&model.LvUserSession{}).Where("session_id = ?", sessionId).Updates(map[string]interface{}{
+		"last_seen_at": now,
+		"ip":           ip,
+	})
+}
+
+// sweepLastSeen 每隔 lastSeenInterval 清除一次已超过节流间隔的条目
+func sweepLastSeen(now time.Time) {
+	lastSeenSweepMu.Lock()
+	if now.Sub(lastSeenSweptAt) < lastSeenInterval {
+		lastSeenSweepMu.Unlock()
+		return
+	}
+	lastSeenSweptAt = now
+	lastSeenSweepMu.Unlock()
+
+	lastSeenCache.Range(func(key, value interface{}) bool {
+		if now.Sub(value.(time.Time)) >= lastSeenInterval {
+			lastSeenCache.CompareAndDelete(key, value)
+		}
+		return true
+	})
+}
+
+// GetUserSessions 获取用户的在线会话
+func (s *UserSessionService) GetUserSessions(userId uint, currentSessionId string) ([]SessionItem, error) {
+	var sessions []SessionItem
+	err := s.activeSessionQuery().
+		Select(sessionItemColumns).
+		Where("lv_user_sessions.user_id = ?", userId).
+		Order("lv_user_sessions.last_seen_at DESC").
+		Scan(&sessions).Error
+	for i := range sessions {
+		sessions[i].Current = sessions[i].SessionId == currentSessionId
+	}
+	return sessions, err
+}
+
+// GetOnlineSessions 获取全部在线会话（管理员），多租户模式下只返回 tenantId 租户的会话
+func (s *UserSessionService) GetOnlineSessions(tenantId uint, page, pageSize int, username, currentSessionId string) ([]SessionItem, int64, error) {
+	var sessions []SessionItem
+	var total int64
+
+	db := s.activeSessionQuery()
+	if global.LV_CONFIG.Tenant.Enable {
+		db = db.Where("lv_users.tenant_id = ?", tenantId)
+	}
+	if username != "" {
+		db = db.Where("lv_users.username LIKE ?", "%"+username+"%")
+	}
+
+	db.Count(&total)
+
+	offset := (page - 1) * pageSize
+	err := db.Select(sessionItemColumns).Order("lv_user_sessions.last_seen_at DESC").Offset(offset).Limit(pageSize).Scan(&sessions).Error
+	for i := range sessions {
+		sessions[i].Current = sessions[i].SessionId == currentSessionId
+	}
+	return sessions, total, err
+}
+
+// GetSession 根据会话ID获取会话
+func (s *UserSessionService) GetSession(sessionId string) (*model.LvUserSession, error) {
+	var session model.LvUserSession
+	err := global.LV_DB.Where("session_id = ?", sessionId).First(&session).Error
+	return &session, err
+}
+
+// SessionInTenant 会话是否属于 tenantId 租户的用户，未开启多租户时始终为真
+func (s *UserSessionService) SessionInTenant(sessionId string, tenantId uint) bool {
+	if !global.LV_CONFIG.Tenant.Enable {
+		return true
+	}
+	var count int64
+	global.LV_DB.Model(&model.LvUserSession{}).
+		Joins("JOIN lv_users ON lv_users.id = lv_user_sessions.user_id").
+		Where("lv_user_sessions.session_id = ? AND lv_users.tenant_id = ?", sessionId, tenantId).
+		Count(&count)
+	return count > 0
+}
+
+// UserInScope 用户是否属于当前租户且在操作人的数据范围内
+func (s *UserSessionService) UserInScope(ctx context.Context, operatorId, activeRoleId, userId uint) bool {
+	var count int64
+	global.LV_DB.WithContext(ctx).Model(&model.LvUser{}).
+		Scopes(DataScope(operatorId, activeRoleId, DataScopeColumns{Dept: "dept_id", User: "id"})).
+		Where("id = ?", userId).
+		Count(&count)
+	return count > 0
+}
+
+// SetActiveRole 设置会话的生效角色，roleId 为 0 时恢复为全部角色
+func (s *UserSessionService) SetActiveRole(sessionId string, userId, roleId uint) error {
+	if sessionId == "" {
+		return errors.New("当前登录方式不支持切换角色")
+	}
+	if roleId != 0 {
+		roles, err := EffectiveRoles(userId, roleId)
+		if err != nil {
+			return err
+		}
+		if len(roles) == 0 {
+			return errors.New("角色不存在或已停用")
+		}
+	}
+	return global.LV_DB.Model(&model.LvUserSession{}).
+		Where("session_id = ? AND user_id = ?", sessionId, userId).
+		Update("active_role_id", roleId).Error
+}
+
+// tokenState 获取签发 Token 时需要的会话状态（生效角色、模拟登录信息）
+func (s *UserSessionService) tokenState(sessionId string) model.LvUserSession {
+	var session model.LvUserSession
+	global.LV_DB.Select("id", "active_role_id", "impersonator_id", "impersonator_name", "expires_at").
+		Where("session_id = ?", sessionId).First(&session)
+	return session
+}
+
+// sessionItemColumns 会话列表查询字段
+const sessionItemColumns = "lv_user_sessions.*, lv_users.username, lv_users.nickname"
+
+// activeSessionQuery 未吊销且未过期的会话查询（关联用户信息）
+func (s *UserSessionService) activeSessionQuery() *gorm.DB {
+	return global.LV_DB.Model(&model.LvUserSession{}).
+		Joins("LEFT JOIN lv_users ON lv_users.id = lv_user_sessions.user_id").
+		Where("lv_user_sessions.revoked_at IS NULL AND lv_user_sessions.expires_at > ?", time.Now())
+}
+
+// RevokeSession 吊销单个会话
+func (s *UserSessionService) RevokeSession(sessionId string) error {
+	lastSeenCache.Delete(sessionId)
+	return global.LV_DB.Model(&model.LvUserSession{}).
+		Where("session_id = ? AND revoked_at IS NULL", sessionId).
+		Update("revoked_at", time.Now()).Error
+}
+
+// RevokeUserSessions 吊销用户的全部会话，exceptSessionId 不为空时保留该会话
+func (s *UserSessionService) RevokeUserSessions(userId uint, exceptSessionId string) error {
+	db := global.LV_DB.Model(&model.LvUserSession{}).Where("user_id = ? AND revoked_at IS NULL", userId)
+	if exceptSessionId != "" {
+		db = db.Where("session_id <> ?", exceptSessionId)
+	}
+	return db.Update("revoked_at", time.Now()).Error
+}
+
+// RevokeTenantSessions 吊销租户下全部用户的会话（租户停用或删除时）
+func (s *UserSessionService) RevokeTenantSessions(tenantId uint) error {
+	return global.LV_DB.Model(&model.LvUserSession{}).
+		Where("user_id IN (?) AND revoked_at IS NULL", global.LV_DB.Model(&model.LvUser{}).Select("id").Where("tenant_id = ?", tenantId)).
+		Update("revoked_at", time.Now()).Error
+}
+
+// parseDevice 从 User-Agent 粗略解析设备描述
+func parseDevice(userAgent string) string {
+	ua := strings.ToLower(userAgent)
+
+	browser := "Unknown"
+	switch {
+	case strings.Contains(ua, "edg/"):
+		browser = "Edge"
+	case strings.Contains(ua, "chrome/"):
+		browser = "Chrome"
+	case strings.Contains(ua, "firefox/"):
+		browser = "Firefox"
+	case strings.Contains(ua, "safari/"):
+		browser = "Safari"
+	case strings.Contains(ua, "curl/"), strings.Contains(ua, "postman"):
+		browser = "API Client"
+	}
+
+	platform := "Unknown"
+	switch {
+	case strings.Contains(ua, "windows"):
+		platform = "Windows"
+	case strings.Contains(ua, "iphone"), strings.Contains(ua, "ipad"):
+		platform = "iOS"
+	case strings.Contains(ua, "mac os"):
+		platform = "macOS"
+	case strings.Contains(ua, "android"):
+		platform = "Android"
+	case strings.Contains(ua, "linux"):
+		platform = "Linux"
+	}
+
+	return browser + " on " + platform
+}
@@ -0,0 +1,37 @@
+package service
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSweepLastSeen(t *testing.T) {
+	now := time.Now()
+	lastSeenSweptAt = time.Time{}
+	lastSeenCache.Store("stale", now.Add(-2*lastSeenInterval))
+	lastSeenCache.Store("fresh", now.Add(-lastSeenInterval/2))
+	t.Cleanup(func() {
+		lastSeenCache.Delete("stale")
+		lastSeenCache.Delete("fresh")
+		lastSeenCache.Delete("later")
+	})
+
+	sweepLastSeen(now)
+	if _, ok := lastSeenCache.Load("stale"); ok {
+		t.Error("stale entry should be evicted")
+	}
+	if _, ok := lastSeenCache.Load("fresh"); !ok {
+		t.Error("entry within lastSeenInterval is still needed for throttling")
+	}
+
+	// 距上次清理不足 lastSeenInterval 时不再遍历
+	lastSeenCache.Store("later", now.Add(-2*lastSeenInterval))
+	sweepLastSeen(now.Add(time.Second))
+	if _, ok := lastSeenCache.Load("later"); !ok {
+		t.Error("sweep should run at most once per lastSeenInterval")
+	}
+	sweepLastSeen(now.Add(lastSeenInterval))
+	if _, ok := lastSeenCache.Load("later"); ok {
+		t.Error("stale entry should be evicted on the next sweep")
+	}
+}
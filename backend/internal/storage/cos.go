@@ -60,6 +60,15 @@ func (d *COSDriver) UploadReader(reader io.Reader, filename string, size int64)
 	return url, key, nil
 }
 
+// Download 从COS读取文件内容
+func (d *COSDriver) Download(key string) (io.ReadCloser, error) {
+	resp, err := d.client.Object.Get(context.Background(), key, nil)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
 // Delete 从COS删除文件
 func (d *COSDriver) Delete(key string) error {
 	_, err := d.client.Object.Delete(context.Background(), key)
@@ -25,6 +25,9 @@ func InitStorage() error {
 		driver = d
 		global.LV_LOG.Info("使用阿里云OSS存储驱动")
 	case "cos":
+		if cfg.COS.Bucket == "" || cfg.COS.Region == "" {
+			return fmt.Errorf("COS 未配置 bucket 或 region")
+		}
 		d, err := NewCOSDriver(cfg.COS)
 		if err != nil {
 			return fmt.Errorf("初始化COS驱动失败: %w", err)
@@ -32,6 +35,9 @@ func InitStorage() error {
 		driver = d
 		global.LV_LOG.Info("使用腾讯云COS存储驱动")
 	case "r2":
+		if cfg.R2.AccountID == "" || cfg.R2.Bucket == "" {
+			return fmt.Errorf("R2 未配置 account_id 或 bucket")
+		}
 		d, err := NewR2Driver(cfg.R2)
 		if err != nil {
 			return fmt.Errorf("初始化R2驱动失败: %w", err)
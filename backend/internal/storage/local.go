@@ -70,6 +70,11 @@ func (d *LocalDriver) UploadReader(reader io.Reader, filename string, size int64
 	return url, relativePath, nil
 }
 
+// Download 读取文件内容
+func (d *LocalDriver) Download(key string) (io.ReadCloser, error) {
+	return os.Open(filepath.Join(d.config.Path, key))
+}
+
 // Delete 删除文件
 func (d *LocalDriver) Delete(key string) error {
 	fullPath := filepath.Join(d.config.Path, key)
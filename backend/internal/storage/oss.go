@@ -59,6 +59,11 @@ func (d *OSSDriver) UploadReader(reader io.Reader, filename string, size int64)
 	return url, key, nil
 }
 
+// Download 从OSS读取文件内容
+func (d *OSSDriver) Download(key string) (io.ReadCloser, error) {
+	return d.bucket.GetObject(key)
+}
+
 // Delete 从OSS删除文件
 func (d *OSSDriver) Delete(key string) error {
 	return d.bucket.DeleteObject(key)
@@ -74,6 +74,18 @@ func (d *R2Driver) UploadReader(reader io.Reader, filename string, size int64) (
 	return url, key, nil
 }
 
+// Download 从R2读取文件内容
+func (d *R2Driver) Download(key string) (io.ReadCloser, error) {
+	out, err := d.client.GetObject(&s3.GetObjectInput{
+		Bucket: aws.String(d.config.Bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return out.Body, nil
+}
+
 // Delete 从R2删除文件
 func (d *R2Driver) Delete(key string) error {
 	_, err := d.client.DeleteObject(&s3.DeleteObjectInput{
@@ -16,6 +16,9 @@ type StorageDriver interface {
 	// UploadReader 从 Reader 上传文件
 	UploadReader(reader io.Reader, filename string, size int64) (url string, key string, err error)
 
+	// Download 读取文件内容，调用方负责关闭
+	Download(key string) (io.ReadCloser, error)
+
 	// Delete 删除文件
 	Delete(key string) error
 
@@ -0,0 +1,13 @@
+package utils
+
+import "github.com/gin-gonic/gin"
+
+// GetClaims 获取 JWTAuth 中间件写入 context 的 claims，未登录时返回 nil
+func GetClaims(c *gin.Context) *CustomClaims {
+	if claims, exists := c.Get("claims"); exists {
+		if customClaims, ok := claims.(*CustomClaims); ok {
+			return customClaims
+		}
+	}
+	return nil
+}
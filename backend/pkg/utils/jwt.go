@@ -22,26 +22,32 @@ func NewJWT() *JWT {
 }
 
 // Custom Claims
+// RegisteredClaims.ID (jti) 对应 lv_user_sessions 中的会话标识，用于服务端吊销
 type CustomClaims struct {
 	BaseClaims
-	BufferTime int64
 	jwt.RegisteredClaims
 }
 
 type BaseClaims struct {
-	UserId   uint
-	Username string
-	RoleId   uint
+	TenantId         uint // 所属租户，0 为平台
+	UserId           uint
+	Username         string
+	RoleId           uint
+	ActiveRoleId     uint   // 当前生效角色，0 表示使用用户的全部角色
+	PasswordExpired  bool   // 密码已过期，仅允许修改密码
+	MfaPending       bool   // 已通过密码校验，等待两步验证（不可访问业务接口）
+	MfaSetupRequired bool   // 角色要求两步验证但尚未绑定，仅允许绑定
+	ImpersonatorId   uint   // 模拟登录的管理员，不为 0 时表示当前为模拟会话
+	ImpersonatorName string // 模拟登录的管理员用户名
 }
 
-func (j *JWT) CreateClaims(baseClaims BaseClaims) CustomClaims {
-	bf, _ := ParseDuration(global.LV_CONFIG.JWT.BufferTime)
+func (j *JWT) CreateClaims(baseClaims BaseClaims, sessionId string) CustomClaims {
 	ep, _ := ParseDuration(global.LV_CONFIG.JWT.ExpiresTime)
 
 	claims := CustomClaims{
 		BaseClaims: baseClaims,
-		BufferTime: int64(bf / time.Second), // Buffer time for renewal
 		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        sessionId,                                               // Session ID (jti)
 			NotBefore: jwt.NewNumericDate(time.Now().Add(-1000 * time.Second)), // Effective time
 			ExpiresAt: jwt.NewNumericDate(time.Now().Add(ep)),                  // Expiration time
 			Issuer:    global.LV_CONFIG.Zap.Prefix,                             // Issuer
@@ -50,6 +56,24 @@ func (j *JWT) CreateClaims(baseClaims BaseClaims) CustomClaims {
 	return claims
 }
 
+// CreateMfaClaims 创建两步验证待完成的短期 Claims，不关联会话
+func (j *JWT) CreateMfaClaims(baseClaims BaseClaims) CustomClaims {
+	ep, err := ParseDuration(global.LV_CONFIG.Login.MfaTokenExpires)
+	if err != nil || ep <= 0 {
+		ep = 5 * time.Minute
+	}
+	baseClaims.MfaPending = true
+
+	return CustomClaims{
+		BaseClaims: baseClaims,
+		RegisteredClaims: jwt.RegisteredClaims{
+			NotBefore: jwt.NewNumericDate(time.Now().Add(-1000 * time.Second)),
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(ep)),
+			Issuer:    global.LV_CONFIG.Zap.Prefix,
+		},
+	}
+}
+
 func (j *JWT) CreateToken(claims CustomClaims) (string, error) {
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 	return token.SignedString(j.SigningKey)
@@ -0,0 +1,28 @@
+package utils
+
+import "context"
+
+type operatorContextKey struct{}
+
+// Operator 当前请求的操作人，由审计回调写入数据变更记录
+type Operator struct {
+	UserId           uint
+	Username         string
+	ImpersonatorId   uint
+	ImpersonatorName string
+	Ip               string
+}
+
+// WithOperator 将操作人写入 context
+func WithOperator(ctx context.Context, operator Operator) context.Context {
+	return context.WithValue(ctx, operatorContextKey{}, operator)
+}
+
+// OperatorFromContext 获取 context 中的操作人，未设置时为系统操作
+func OperatorFromContext(ctx context.Context) (Operator, bool) {
+	if ctx == nil {
+		return Operator{}, false
+	}
+	operator, ok := ctx.Value(operatorContextKey{}).(Operator)
+	return operator, ok
+}
@@ -0,0 +1,150 @@
+package utils
+
+import (
+	"net/url"
+	"path"
+	"strings"
+)
+
+// RedactedValue 脱敏后的取值
+const RedactedValue = "******"
+
+// Redactor 按字段名脱敏 JSON 和表单内容，字段名不区分大小写，规则支持 * 通配（如 *password*）
+type Redactor struct {
+	patterns []string
+}
+
+// NewRedactor 创建脱敏器
+func NewRedactor(patterns []string) *Redactor {
+	r := &Redactor{}
+	for _, pattern := range patterns {
+		if pattern = strings.ToLower(strings.TrimSpace(pattern)); pattern != "" {
+			r.patterns = append(r.patterns, pattern)
+		}
+	}
+	return r
+}
+
+// Match 字段名是否需要脱敏
+func (r *Redactor) Match(key string) bool {
+	key = strings.ToLower(key)
+	for _, pattern := range r.patterns {
+		if ok, _ := path.Match(pattern, key); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// Redact 脱敏 JSON（以 { 或 [ 开头）或 URL 编码的表单内容，其他内容原样返回
+func (r *Redactor) Redact(content string) string {
+	if len(r.patterns) == 0 || content == "" {
+		return content
+	}
+	trimmed := strings.TrimSpace(content)
+	if strings.HasPrefix(trimmed, "{") || strings.HasPrefix(trimmed, "[") {
+		return r.RedactJSON(content)
+	}
+	if strings.Contains(content, "=") && !strings.ContainsAny(content, " \n") {
+		return r.RedactForm(content)
+	}
+	return content
+}
+
+// RedactJSON 将匹配字段的值（包括对象和数组）整体替换为 RedactedValue
+// 逐个扫描 JSON 中的键，不要求整体是合法 JSON，截断的内容同样适用，未匹配的部分保持原样
+func (r *Redactor) RedactJSON(content string) string {
+	var b strings.Builder
+	last := 0
+	for i := 0; i < len(content); {
+		if content[i] != '"' {
+			i++
+			continue
+		}
+		end := jsonStringEnd(content, i)
+		colon := skipJSONSpace(content, end)
+		// 后面不是冒号的字符串是值，跳过；对象值内的键在继续扫描时处理
+		if colon >= len(content) || content[colon] != ':' || !r.Match(content[i+1:end-1]) {
+			i = end
+			continue
+		}
+		start := skipJSONSpace(content, colon+1)
+		if start >= len(content) {
+			break
+		}
+		b.WriteString(content[last:start])
+		b.WriteString(`"` + RedactedValue + `"`)
+		last = jsonValueEnd(content, start)
+		i = last
+	}
+	if last == 0 {
+		return content
+	}
+	b.WriteString(content[last:])
+	return b.String()
+}
+
+// jsonStringEnd 返回从 start（引号）开始的字符串结束后的位置，字符串被截断时返回内容末尾
+func jsonStringEnd(s string, start int) int {
+	for i := start + 1; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '"':
+			return i + 1
+		}
+	}
+	return len(s)
+}
+
+// jsonValueEnd 返回从 start 开始的值结束后的位置，对象和数组匹配到对应的闭合括号
+func jsonValueEnd(s string, start int) int {
+	switch s[start] {
+	case '"':
+		return jsonStringEnd(s, start)
+	case '{', '[':
+		depth := 0
+		for i := start; i < len(s); {
+			switch s[i] {
+			case '"':
+				i = jsonStringEnd(s, i)
+				continue
+			case '{', '[':
+				depth++
+			case '}', ']':
+				if depth--; depth == 0 {
+					return i + 1
+				}
+			}
+			i++
+		}
+		return len(s)
+	}
+	i := start
+	for i < len(s) && !strings.ContainsRune(",}] \t\r\n", rune(s[i])) {
+		i++
+	}
+	return i
+}
+
+func skipJSONSpace(s string, i int) int {
+	for i < len(s) && strings.ContainsRune(" \t\r\n", rune(s[i])) {
+		i++
+	}
+	return i
+}
+
+// RedactForm 脱敏 URL 编码的表单，保持字段顺序
+func (r *Redactor) RedactForm(content string) string {
+	pairs := strings.Split(content, "&")
+	for i, pair := range pairs {
+		key, _, found := strings.Cut(pair, "=")
+		if !found {
+			continue
+		}
+		if name, err := url.QueryUnescape(key); err == nil && r.Match(name) {
+			pairs[i] = key + "=" + RedactedValue
+		}
+	}
+	return strings.Join(pairs, "&")
+}
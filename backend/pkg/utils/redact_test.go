@@ -0,0 +1,46 @@
+package utils
+
+import "testing"
+
+func TestRedactJSON(t *testing.T) {
+	r := NewRedactor([]string{"password", "*token*", "secrets"})
+
+	cases := []struct {
+		name, in, want string
+	}{
+		{"scalar values",
+			`{"username":"alice","password":"p@ss\"word","age":3,"token":null}`,
+			`{"username":"alice","password":"******","age":3,"token":"******"}`},
+		{"object value",
+			`{"token": {"access": "a", "refresh": "b"}, "ok": true}`,
+			`{"token": "******", "ok": true}`},
+		{"array value",
+			`{"secrets":[{"k":"v"},["x","]"]],"n":1}`,
+			`{"secrets":"******","n":1}`},
+		{"nested keys",
+			`{"data":{"user":{"name":"alice","refreshToken":"r"}},"list":[{"password":"1"}]}`,
+			`{"data":{"user":{"name":"alice","refreshToken":"******"}},"list":[{"password":"******"}]}`},
+		{"string value looks like key",
+			`{"note":"password","remark":"\"password\": x"}`,
+			`{"note":"password","remark":"\"password\": x"}`},
+		{"truncated inside secret",
+			`{"name":"alice","password":"abc`,
+			`{"name":"alice","password":"******"`},
+		{"truncated inside object value",
+			`{"token":{"access":"abc`,
+			`{"token":"******"`},
+		{"no match", `[1,2,{"a":"b"}]`, `[1,2,{"a":"b"}]`},
+	}
+	for _, c := range cases {
+		if got := r.RedactJSON(c.in); got != c.want {
+			t.Errorf("%s:\n got  %s\n want %s", c.name, got, c.want)
+		}
+	}
+}
+
+func TestRedactForm(t *testing.T) {
+	r := NewRedactor([]string{"password"})
+	if got := r.Redact("username=alice&password=secret"); got != "username=alice&password=******" {
+		t.Errorf("got %s", got)
+	}
+}
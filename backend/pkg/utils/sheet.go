@@ -0,0 +1,141 @@
+package utils
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// 支持的表格格式
+const (
+	SheetCSV  = "csv"
+	SheetXLSX = "xlsx"
+)
+
+// utf8BOM 写在 CSV 开头，Excel 打开时按 UTF-8 识别中文
+const utf8BOM = "\xEF\xBB\xBF"
+
+// SheetWriter 逐行写入表格，Close 时写完剩余内容
+type SheetWriter interface {
+	WriteRow(values []string) error
+	Close() error
+}
+
+// IsSheetFormat 是否为支持的表格格式
+func IsSheetFormat(format string) bool {
+	return format == SheetCSV || format == SheetXLSX
+}
+
+// SheetContentType 表格格式对应的 Content-Type
+func SheetContentType(format string) string {
+	if format == SheetXLSX {
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	}
+	return "text/csv; charset=utf-8"
+}
+
+// NewSheetWriter 创建表格写入器：CSV 边写边输出；XLSX 使用流式写入，超出内存阈值的行暂存到临时文件，Close 时输出
+func NewSheetWriter(format string, w io.Writer) (SheetWriter, error) {
+	switch format {
+	case SheetCSV:
+		if _, err := io.WriteString(w, utf8BOM); err != nil {
+			return nil, err
+		}
+		return &csvSheetWriter{w: csv.NewWriter(w)}, nil
+	case SheetXLSX:
+		f := excelize.NewFile()
+		sw, err := f.NewStreamWriter("Sheet1")
+		if err != nil {
+			f.Close()
+			return nil, err
+		}
+		return &xlsxSheetWriter{file: f, stream: sw, w: w}, nil
+	default:
+		return nil, fmt.Errorf("不支持的文件格式: %s", format)
+	}
+}
+
+type csvSheetWriter struct {
+	w *csv.Writer
+}
+
+// WriteRow 以 = + - @ 或制表符、回车开头的内容加上单引号，避免在 Excel 中被当作公式执行
+func (s *csvSheetWriter) WriteRow(values []string) error {
+	row := make([]string, len(values))
+	for i, value := range values {
+		if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+			value = "'" + value
+		}
+		row[i] = value
+	}
+	return s.w.Write(row)
+}
+
+func (s *csvSheetWriter) Close() error {
+	s.w.Flush()
+	return s.w.Error()
+}
+
+type xlsxSheetWriter struct {
+	file   *excelize.File
+	stream *excelize.StreamWriter
+	w      io.Writer
+	row    int
+}
+
+func (s *xlsxSheetWriter) WriteRow(values []string) error {
+	s.row++
+	cell, err := excelize.CoordinatesToCellName(1, s.row)
+	if err != nil {
+		return err
+	}
+	row := make([]interface{}, len(values))
+	for i, value := range values {
+		row[i] = value
+	}
+	return s.stream.SetRow(cell, row)
+}
+
+func (s *xlsxSheetWriter) Close() error {
+	defer s.file.Close()
+	if err := s.stream.Flush(); err != nil {
+		return err
+	}
+	return s.file.Write(s.w)
+}
+
+// ReadSheet 读取 CSV 或 XLSX（第一个工作表）的全部行，忽略 CSV 开头的 BOM
+func ReadSheet(format string, r io.Reader) ([][]string, error) {
+	switch format {
+	case SheetCSV:
+		reader := csv.NewReader(r)
+		reader.FieldsPerRecord = -1
+		rows, err := reader.ReadAll()
+		if err != nil {
+			return nil, err
+		}
+		if len(rows) > 0 && len(rows[0]) > 0 {
+			rows[0][0] = strings.TrimPrefix(rows[0][0], utf8BOM)
+		}
+		return rows, nil
+	case SheetXLSX:
+		f, err := excelize.OpenReader(r)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		return f.GetRows(f.GetSheetName(0))
+	default:
+		return nil, fmt.Errorf("不支持的文件格式: %s", format)
+	}
+}
+
+// SheetFormat 按文件扩展名识别表格格式
+func SheetFormat(filename string) (string, bool) {
+	format := strings.ToLower(strings.TrimPrefix(filepath.Ext(filename), "."))
+	return format, IsSheetFormat(format)
+}
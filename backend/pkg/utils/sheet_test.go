@@ -0,0 +1,33 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/csv"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCSVSheetWriterEscapesFormulas(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := NewSheetWriter(SheetCSV, &buf)
+	if err != nil {
+		t.Fatalf("NewSheetWriter: %v", err)
+	}
+	values := []string{"=1+1", "+1", "-1", "@SUM(A1)", "\t=1+1", "\r=1+1", "alice", ""}
+	if err := w.WriteRow(values); err != nil {
+		t.Fatalf("WriteRow: %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	rows, err := csv.NewReader(strings.NewReader(strings.TrimPrefix(buf.String(), utf8BOM))).ReadAll()
+	if err != nil {
+		t.Fatalf("read csv: %v", err)
+	}
+	want := []string{"'=1+1", "'+1", "'-1", "'@SUM(A1)", "'\t=1+1", "'\r=1+1", "alice", ""}
+	if len(rows) != 1 || !reflect.DeepEqual(rows[0], want) {
+		t.Fatalf("rows = %q, want %q", rows, want)
+	}
+}
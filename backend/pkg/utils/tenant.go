@@ -0,0 +1,24 @@
+package utils
+
+import "context"
+
+type tenantContextKey struct{}
+
+// WithTenant 将租户写入 context，携带该 context 的 GORM 查询会按租户过滤
+func WithTenant(ctx context.Context, tenantId uint) context.Context {
+	return context.WithValue(ctx, tenantContextKey{}, tenantId)
+}
+
+// TenantFromContext 获取 context 中的租户，未设置时 ok 为 false（不做租户过滤）
+func TenantFromContext(ctx context.Context) (tenantId uint, ok bool) {
+	if ctx == nil {
+		return 0, false
+	}
+	tenantId, ok = ctx.Value(tenantContextKey{}).(uint)
+	return
+}
+
+// WithoutTenant 屏蔽 context 中的租户，用于需要同时读写全局数据和租户数据的查询（如系统设置）
+func WithoutTenant(ctx context.Context) context.Context {
+	return context.WithValue(ctx, tenantContextKey{}, nil)
+}
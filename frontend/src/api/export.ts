@@ -0,0 +1,20 @@
+import request from '@/utils/request';
+
+// 获取当前用户的后台导出任务
+export const getExportJobs = (params: { page: number; pageSize: number }) => {
+    return request({
+        url: '/export/jobs',
+        method: 'get',
+        params,
+    });
+};
+
+// 下载后台导出任务生成的文件
+export const downloadExportJob = (id: number) => {
+    return request({
+        url: `/export/jobs/${id}/download`,
+        method: 'get',
+        responseType: 'blob',
+        timeout: 0,
+    });
+};
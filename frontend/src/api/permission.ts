@@ -32,3 +32,20 @@ export const getUserMenus = () => {
         method: 'get',
     });
 };
+
+// 获取当前用户拥有的角色及当前生效角色
+export const getUserRoles = () => {
+    return request({
+        url: '/user/roles',
+        method: 'get',
+    });
+};
+
+// 切换当前会话的生效角色（0 为全部角色），返回新的 Token
+export const switchActiveRole = (roleId: number) => {
+    return request({
+        url: '/user/active-role',
+        method: 'put',
+        data: { roleId },
+    });
+};
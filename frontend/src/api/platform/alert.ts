@@ -0,0 +1,61 @@
+import request from '@/utils/request';
+
+// 获取安全告警列表，handled 为 0 只看未处理，1 只看已处理
+export const getAlertList = (params: { page: number; pageSize: number; level?: string; handled?: string; keyword?: string }) => {
+    return request({
+        url: '/platform/alert/list',
+        method: 'get',
+        params,
+    });
+};
+
+// 获取未处理的告警数
+export const getUnhandledAlertCount = () => {
+    return request({
+        url: '/platform/alert/unhandled',
+        method: 'get',
+    });
+};
+
+// 将告警标记为已处理
+export const handleAlerts = (ids: number[], remark?: string) => {
+    return request({
+        url: '/platform/alert/handle',
+        method: 'put',
+        data: { ids, remark },
+    });
+};
+
+// 获取告警规则和可选的通知渠道
+export const getAlertRules = () => {
+    return request({
+        url: '/platform/alert/rules',
+        method: 'get',
+    });
+};
+
+// 创建告警规则
+export const createAlertRule = (data: any) => {
+    return request({
+        url: '/platform/alert/rules',
+        method: 'post',
+        data,
+    });
+};
+
+// 更新告警规则
+export const updateAlertRule = (id: number, data: any) => {
+    return request({
+        url: `/platform/alert/rules/${id}`,
+        method: 'put',
+        data,
+    });
+};
+
+// 删除告警规则
+export const deleteAlertRule = (id: number) => {
+    return request({
+        url: `/platform/alert/rules/${id}`,
+        method: 'delete',
+    });
+};
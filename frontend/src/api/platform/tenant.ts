@@ -0,0 +1,36 @@
+import request from '@/utils/request';
+
+// 获取租户列表
+export const getTenantList = (params: { page: number; pageSize: number; name?: string }) => {
+    return request({
+        url: '/platform/tenant/list',
+        method: 'get',
+        params,
+    });
+};
+
+// 创建租户（同时创建租户管理员账号）
+export const createTenant = (data: any) => {
+    return request({
+        url: '/platform/tenant',
+        method: 'post',
+        data,
+    });
+};
+
+// 更新租户
+export const updateTenant = (id: number, data: any) => {
+    return request({
+        url: `/platform/tenant/${id}`,
+        method: 'put',
+        data,
+    });
+};
+
+// 删除租户
+export const deleteTenant = (id: number) => {
+    return request({
+        url: `/platform/tenant/${id}`,
+        method: 'delete',
+    });
+};
@@ -25,3 +25,36 @@ export const changePassword = (data: { oldPassword: string; newPassword: string
         data,
     });
 };
+
+// 获取我的访问令牌
+export const getAccessTokens = () => {
+    return request({
+        url: '/profile/tokens',
+        method: 'get',
+    });
+};
+
+// 获取可分配给访问令牌的接口
+export const getAccessTokenApis = () => {
+    return request({
+        url: '/profile/tokens/apis',
+        method: 'get',
+    });
+};
+
+// 创建访问令牌
+export const createAccessToken = (data: { name: string; expiresInDays: number; apiIds?: number[] }) => {
+    return request({
+        url: '/profile/tokens',
+        method: 'post',
+        data,
+    });
+};
+
+// 吊销访问令牌
+export const deleteAccessToken = (id: number) => {
+    return request({
+        url: `/profile/tokens/${id}`,
+        method: 'delete',
+    });
+};
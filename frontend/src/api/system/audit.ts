@@ -0,0 +1,17 @@
+import request from '@/utils/request';
+
+// 获取审计日志列表（entity + id 查询某条数据的变更历史）
+export const getAuditList = (params: {
+    page: number;
+    pageSize: number;
+    entity?: string;
+    id?: string;
+    username?: string;
+    action?: string;
+}) => {
+    return request({
+        url: '/system/audit',
+        method: 'get',
+        params,
+    });
+};
@@ -0,0 +1,44 @@
+import request from '@/utils/request';
+
+// 获取部门树
+export const getDeptTree = () => {
+    return request({
+        url: '/system/dept/tree',
+        method: 'get',
+    });
+};
+
+// 创建部门
+export const createDept = (data: any) => {
+    return request({
+        url: '/system/dept',
+        method: 'post',
+        data,
+    });
+};
+
+// 更新部门
+export const updateDept = (id: number, data: any) => {
+    return request({
+        url: `/system/dept/${id}`,
+        method: 'put',
+        data,
+    });
+};
+
+// 移动部门
+export const moveDept = (id: number, data: { parentId: number; sort: number }) => {
+    return request({
+        url: `/system/dept/${id}/move`,
+        method: 'put',
+        data,
+    });
+};
+
+// 删除部门
+export const deleteDept = (id: number) => {
+    return request({
+        url: `/system/dept/${id}`,
+        method: 'delete',
+    });
+};
@@ -1,12 +1,25 @@
 import request from '@/utils/request';
 
-// 获取操作日志列表
-export const getOperationLogList = (params: {
-    page: number;
-    pageSize: number;
+// 操作日志筛选条件，列表和导出共用
+export interface OperationLogQuery {
     username?: string;
     module?: string;
     action?: string;
+    ip?: string; // 单个 IP、CIDR 或 IP 前缀
+    path?: string; // 路径前缀
+    status?: string; // 状态码或状态类别，如 404、4xx
+    minLatency?: number; // 耗时不低于（ms）
+    startTime?: number; // 毫秒时间戳
+    endTime?: number;
+    keyword?: string; // 在请求参数和响应内容中全文搜索
+    sortBy?: string;
+    sortOrder?: 'asc' | 'desc';
+}
+
+// 获取操作日志列表
+export const getOperationLogList = (params: OperationLogQuery & {
+    page: number;
+    pageSize: number;
 }) => {
     return request({
         url: '/system/log/list',
@@ -15,6 +28,19 @@ export const getOperationLogList = (params: {
     });
 };
 
+// 按筛选条件导出操作日志，format 为 csv 或 xlsx
+export const exportOperationLogs = (params: OperationLogQuery & {
+    format: string;
+}) => {
+    return request({
+        url: '/system/log/export',
+        method: 'get',
+        params,
+        responseType: 'blob',
+        timeout: 0,
+    });
+};
+
 // 批量删除操作日志
 export const deleteOperationLogs = (ids: number[]) => {
     return request({
@@ -31,3 +57,45 @@ export const clearOperationLogs = () => {
         method: 'delete',
     });
 };
+
+// 按当前脱敏规则清洗历史操作日志
+export const scrubOperationLogs = () => {
+    return request({
+        url: '/system/log/scrub',
+        method: 'post',
+    });
+};
+
+// 获取操作日志归档列表
+export const getLogArchiveList = (params: { page: number; pageSize: number }) => {
+    return request({
+        url: '/system/log/archive/list',
+        method: 'get',
+        params,
+    });
+};
+
+// 立即归档过期操作日志，days 为空时使用配置的保留天数
+export const archiveOperationLogs = (days?: number) => {
+    return request({
+        url: '/system/log/archive',
+        method: 'post',
+        data: { days },
+    });
+};
+
+// 将归档恢复到日志表
+export const restoreLogArchive = (id: number) => {
+    return request({
+        url: `/system/log/archive/${id}/restore`,
+        method: 'post',
+    });
+};
+
+// 删除从归档恢复的日志
+export const clearRestoredLogArchive = (id: number) => {
+    return request({
+        url: `/system/log/archive/${id}/restore`,
+        method: 'delete',
+    });
+};
@@ -33,3 +33,20 @@ export const deleteRole = (id: number) => {
         method: 'delete',
     });
 };
+
+// 获取角色数据范围
+export const getRoleDataScope = (id: number) => {
+    return request({
+        url: `/system/role/${id}/data-scope`,
+        method: 'get',
+    });
+};
+
+// 设置角色数据范围
+export const setRoleDataScope = (id: number, data: { dataScope: number; deptIds?: number[] }) => {
+    return request({
+        url: `/system/role/${id}/data-scope`,
+        method: 'put',
+        data,
+    });
+};
@@ -9,6 +9,40 @@ export const getUserList = (params: { page: number; pageSize: number; username?:
     });
 };
 
+// 按筛选条件导出用户，format 为 csv 或 xlsx
+export const exportUsers = (params: { format: string; username?: string; phone?: string; status?: number }) => {
+    return request({
+        url: '/system/user/export',
+        method: 'get',
+        params,
+        responseType: 'blob',
+        timeout: 0,
+    });
+};
+
+// 下载用户导入模板
+export const getUserImportTemplate = (format: string) => {
+    return request({
+        url: '/system/user/import/template',
+        method: 'get',
+        params: { format },
+        responseType: 'blob',
+    });
+};
+
+// 批量导入用户，dryRun 为 true 时只校验不导入
+export const importUsers = (file: File, dryRun: boolean) => {
+    const data = new FormData();
+    data.append('file', file);
+    data.append('dryRun', String(dryRun));
+    return request({
+        url: '/system/user/import',
+        method: 'post',
+        data,
+        timeout: 0,
+    });
+};
+
 // 创建用户
 export const createUser = (data: any) => {
     return request({
@@ -44,6 +78,14 @@ export const resetPassword = (id: number, password?: string) => {
     });
 };
 
+// 模拟登录指定用户
+export const impersonateUser = (id: number) => {
+    return request({
+        url: `/system/user/${id}/impersonate`,
+        method: 'post',
+    });
+};
+
 // 获取角色选项
 export const getRoleOptions = () => {
     return request({
@@ -7,3 +7,50 @@ export const login = (data: any) => {
         data,
     });
 };
+
+export const loginMfa = (data: { mfaToken: string; code: string }) => {
+    return request({
+        url: '/base/login/2fa',
+        method: 'post',
+        data,
+    });
+};
+
+export const getOidcUrl = (tenantCode?: string) => {
+    return request({
+        url: '/base/oidc/url',
+        method: 'get',
+        params: { tenantCode },
+    });
+};
+
+export const oidcLogin = (data: { code: string; state: string }) => {
+    return request({
+        url: '/base/oidc/login',
+        method: 'post',
+        data,
+    });
+};
+
+export const refreshToken = (refreshToken: string) => {
+    return request({
+        url: '/base/refresh',
+        method: 'post',
+        data: { refreshToken },
+    });
+};
+
+export const logout = () => {
+    return request({
+        url: '/base/logout',
+        method: 'post',
+    });
+};
+
+export const getCaptcha = (username?: string, tenantCode?: string) => {
+    return request({
+        url: '/base/captcha',
+        method: 'get',
+        params: { username, tenantCode },
+    });
+};
@@ -34,6 +34,10 @@ export default {
         passwordPlaceholder: 'Password: password',
         usernameRequired: 'Username is required',
         passwordRequired: 'Password is required',
+        tenantPlaceholder: 'Tenant code (leave empty for platform admins)',
+        captchaPlaceholder: 'Captcha',
+        mfaPlaceholder: 'Authenticator code or recovery code',
+        ssoLogin: 'Sign in with SSO',
         loginSuccess: 'Login successful',
         loginFailed: 'Login failed'
     },
@@ -34,6 +34,10 @@ export default {
         passwordPlaceholder: '密码: password',
         usernameRequired: '请输入用户名',
         passwordRequired: '请输入密码',
+        tenantPlaceholder: '租户编码（平台管理员留空）',
+        captchaPlaceholder: '验证码',
+        mfaPlaceholder: '请输入两步验证码或恢复码',
+        ssoLogin: '单点登录',
         loginSuccess: '登录成功',
         loginFailed: '登录失败'
     },
@@ -7,6 +7,12 @@ const routes: Array<RouteRecordRaw> = [
         component: () => import('@/views/login/index.vue'),
         meta: { title: 'Login' }
     },
+    {
+        path: '/oidc/callback',
+        name: 'OidcCallback',
+        component: () => import('@/views/login/oidc-callback.vue'),
+        meta: { title: 'Login' }
+    },
     {
         path: '/',
         component: () => import('@/layouts/AdminLayout.vue'),
@@ -43,12 +49,24 @@ const routes: Array<RouteRecordRaw> = [
                 component: () => import('@/views/system/menu/index.vue'),
                 meta: { title: '菜单管理', requiresAuth: true }
             },
+            {
+                path: 'system/dept',
+                name: 'SystemDept',
+                component: () => import('@/views/system/dept/index.vue'),
+                meta: { title: '部门管理', requiresAuth: true }
+            },
             {
                 path: 'system/log',
                 name: 'SystemLog',
                 component: () => import('@/views/system/log/index.vue'),
                 meta: { title: '操作日志', requiresAuth: true }
             },
+            {
+                path: 'system/audit',
+                name: 'SystemAudit',
+                component: () => import('@/views/system/audit/index.vue'),
+                meta: { title: '审计日志', requiresAuth: true }
+            },
             {
                 path: 'system/file',
                 name: 'SystemFile',
@@ -61,6 +79,18 @@ const routes: Array<RouteRecordRaw> = [
                 component: () => import('@/views/system/setting/index.vue'),
                 meta: { title: '系统设置', requiresAuth: true }
             },
+            {
+                path: 'platform/tenant',
+                name: 'PlatformTenant',
+                component: () => import('@/views/platform/tenant/index.vue'),
+                meta: { title: '租户管理', requiresAuth: true }
+            },
+            {
+                path: 'platform/alert',
+                name: 'PlatformAlert',
+                component: () => import('@/views/platform/alert/index.vue'),
+                meta: { title: '安全告警', requiresAuth: true }
+            },
             {
                 path: 'tool/generator',
                 name: 'ToolGenerator',
@@ -6,6 +6,7 @@ export const useSettingStore = defineStore('setting', () => {
     const siteName = ref('Go Lv Admin');
     const siteLogo = ref('');
     const siteFooter = ref('© 2024 Go Lv Admin');
+    const tenantEnable = ref(false);
 
     const fetchSettings = async () => {
         try {
@@ -14,6 +15,7 @@ export const useSettingStore = defineStore('setting', () => {
                 siteName.value = res.site_name || 'Go Lv Admin';
                 siteLogo.value = res.site_logo || '';
                 siteFooter.value = res.site_footer || '© 2024 Go Lv Admin';
+                tenantEnable.value = !!res.tenant_enable;
 
                 // 更新网页标题
                 document.title = siteName.value;
@@ -27,6 +29,7 @@ export const useSettingStore = defineStore('setting', () => {
         siteName,
         siteLogo,
         siteFooter,
+        tenantEnable,
         fetchSettings
     };
 });
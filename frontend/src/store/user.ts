@@ -1,6 +1,6 @@
 import { defineStore } from 'pinia';
 import { ref } from 'vue';
-import { login } from '@/api/user';
+import { login, loginMfa, oidcLogin, logout as logoutApi } from '@/api/user';
 import { getUserMenus, getUserPermissions } from '@/api/permission';
 
 export const useUserStore = defineStore('user', () => {
@@ -9,21 +9,59 @@ export const useUserStore = defineStore('user', () => {
     const menus = ref<any[]>([]);
     const permissions = ref<string[]>([]);
 
+    // 已启用两步验证时，密码校验通过后返回的待完成 Token
+    const mfaToken = ref('');
+
+    // 模拟登录时发起模拟的管理员，管理员自己的 Token 暂存在 impersonatorToken 中
+    const impersonatorName = ref(localStorage.getItem('impersonatorName') || '');
+
     const handleLogin = async (loginForm: any) => {
         try {
             const res: any = await login(loginForm);
-            token.value = res.token;
-            userInfo.value = res.user;
-            localStorage.setItem('token', res.token);
-            // 登录成功后获取菜单和权限
-            await fetchMenus();
-            await fetchPermissions();
-            return true;
+            if (res.mfaRequired) {
+                mfaToken.value = res.mfaToken;
+                return 'mfa';
+            }
+            return await applyLogin(res);
+        } catch (error) {
+            return false;
+        }
+    };
+
+    const handleOidcLogin = async (code: string, state: string) => {
+        try {
+            const res: any = await oidcLogin({ code, state });
+            if (res.mfaRequired) {
+                mfaToken.value = res.mfaToken;
+                return 'mfa';
+            }
+            return await applyLogin(res);
+        } catch (error) {
+            return false;
+        }
+    };
+
+    const handleLoginMfa = async (code: string) => {
+        try {
+            const res: any = await loginMfa({ mfaToken: mfaToken.value, code });
+            mfaToken.value = '';
+            return await applyLogin(res);
         } catch (error) {
             return false;
         }
     };
 
+    const applyLogin = async (res: any) => {
+        token.value = res.token;
+        userInfo.value = res.user;
+        localStorage.setItem('token', res.token);
+        localStorage.setItem('refreshToken', res.refreshToken);
+        // 登录成功后获取菜单和权限
+        await fetchMenus();
+        await fetchPermissions();
+        return true;
+    };
+
     const fetchMenus = async () => {
         try {
             const res: any = await getUserMenus();
@@ -42,20 +80,65 @@ export const useUserStore = defineStore('user', () => {
         }
     };
 
-    const logout = () => {
+    const startImpersonation = async (res: any) => {
+        localStorage.setItem('impersonatorToken', token.value);
+        localStorage.setItem('impersonatorRefreshToken', localStorage.getItem('refreshToken') || '');
+        localStorage.setItem('impersonatorName', res.impersonatorName);
+        impersonatorName.value = res.impersonatorName;
+
+        // 模拟会话不可刷新，到期后自动回到管理员身份
+        token.value = res.token;
+        userInfo.value = res.user;
+        localStorage.setItem('token', res.token);
+        localStorage.removeItem('refreshToken');
+        await fetchMenus();
+        await fetchPermissions();
+    };
+
+    const stopImpersonation = async () => {
+        try {
+            await logoutApi();
+        } catch (error) {
+            // 模拟会话可能已过期，忽略错误继续恢复
+        }
+        token.value = localStorage.getItem('impersonatorToken') || '';
+        userInfo.value = null;
+        impersonatorName.value = '';
+        localStorage.setItem('token', token.value);
+        localStorage.setItem('refreshToken', localStorage.getItem('impersonatorRefreshToken') || '');
+        localStorage.removeItem('impersonatorToken');
+        localStorage.removeItem('impersonatorRefreshToken');
+        localStorage.removeItem('impersonatorName');
+        await fetchMenus();
+        await fetchPermissions();
+    };
+
+    const logout = async () => {
+        try {
+            await logoutApi();
+        } catch (error) {
+            // 会话可能已失效，忽略错误继续清理本地状态
+        }
         token.value = '';
         userInfo.value = null;
         menus.value = [];
         permissions.value = [];
         localStorage.removeItem('token');
+        localStorage.removeItem('refreshToken');
     };
 
     return {
+        impersonatorName,
+        startImpersonation,
+        stopImpersonation,
         token,
         userInfo,
         menus,
         permissions,
         handleLogin,
+        handleLoginMfa,
+        handleOidcLogin,
+        mfaToken,
         fetchMenus,
         fetchPermissions,
         logout
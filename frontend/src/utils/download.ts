@@ -0,0 +1,33 @@
+import type { AxiosResponse } from 'axios';
+
+// 从 Content-Disposition 中解析文件名（支持 filename*=UTF-8''xxx）
+const parseFilename = (disposition: string, fallback: string) => {
+    const encoded = /filename\*=UTF-8''([^;]+)/i.exec(disposition);
+    if (encoded) {
+        return decodeURIComponent(encoded[1]);
+    }
+    const plain = /filename="?([^";]+)"?/i.exec(disposition);
+    return plain ? plain[1] : fallback;
+};
+
+// 保存接口返回的文件
+export const saveResponseFile = (response: AxiosResponse, fallback = 'download') => {
+    const filename = parseFilename(String(response.headers['content-disposition'] || ''), fallback);
+    const url = URL.createObjectURL(response.data as Blob);
+    const link = document.createElement('a');
+    link.href = url;
+    link.download = filename;
+    document.body.appendChild(link);
+    link.click();
+    document.body.removeChild(link);
+    URL.revokeObjectURL(url);
+};
+
+// 导出接口的返回：数据量较大时转为后台导出，返回任务信息
+export const handleExportResult = (result: any, onAsync: (msg: string) => void) => {
+    if (result && result.async) {
+        onAsync('数据量较大，已转为后台导出，可在“导出任务”中下载');
+        return;
+    }
+    saveResponseFile(result);
+};
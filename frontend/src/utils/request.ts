@@ -8,6 +8,47 @@ const service: AxiosInstance = axios.create({
     timeout: 10000,
 });
 
+// 跳转登录页并清理本地 Token；模拟会话失效时回到管理员自己的身份
+const redirectToLogin = () => {
+    const impersonatorToken = localStorage.getItem('impersonatorToken');
+    if (impersonatorToken) {
+        localStorage.setItem('token', impersonatorToken);
+        localStorage.setItem('refreshToken', localStorage.getItem('impersonatorRefreshToken') || '');
+        localStorage.removeItem('impersonatorToken');
+        localStorage.removeItem('impersonatorRefreshToken');
+        localStorage.removeItem('impersonatorName');
+        window.location.href = '/';
+        return;
+    }
+    localStorage.removeItem('token');
+    localStorage.removeItem('refreshToken');
+    window.location.href = '/login';
+};
+
+// 刷新中的请求，保证并发 401 只触发一次刷新
+let refreshing: Promise<string> | null = null;
+
+const refreshAccessToken = (): Promise<string> => {
+    if (!refreshing) {
+        const refreshToken = localStorage.getItem('refreshToken');
+        refreshing = (refreshToken
+            ? axios.post(`${service.defaults.baseURL}/base/refresh`, { refreshToken }).then((response) => {
+                const res = response.data;
+                if (res.code !== 0) {
+                    throw new Error(res.msg || 'Refresh failed');
+                }
+                localStorage.setItem('token', res.data.token);
+                localStorage.setItem('refreshToken', res.data.refreshToken);
+                return res.data.token as string;
+            })
+            : Promise.reject(new Error('No refresh token'))
+        ).finally(() => {
+            refreshing = null;
+        });
+    }
+    return refreshing;
+};
+
 // Request Interceptor
 service.interceptors.request.use(
     (config: any) => {
@@ -25,30 +66,50 @@ service.interceptors.request.use(
     }
 );
 
+// 处理后端统一返回的 { code: 0, data: ..., msg: ... }
+const handleResult = (res: any) => {
+    if (res.code !== 0) {
+        message.error(res.msg || 'Error');
+        // 如果是 401，跳转到登录页
+        if (res.code === 401) {
+            redirectToLogin();
+        }
+        return Promise.reject(new Error(res.msg || 'Error'));
+    }
+    return res.data;
+};
+
 // Response Interceptor
 service.interceptors.response.use(
     (response: AxiosResponse) => {
-        const res = response.data;
-        // Assuming backend returns { code: 0, data: ..., msg: ... }
-        if (res.code !== 0) {
-            message.error(res.msg || 'Error');
-            // 如果是 401，跳转到登录页
-            if (res.code === 401) {
-                localStorage.removeItem('token');
-                window.location.href = '/login';
+        // 文件下载返回完整响应（用于读取文件名）；接口返回 JSON 时（如转为后台导出）按普通接口处理
+        if (response.config.responseType === 'blob') {
+            if (!String(response.headers['content-type'] || '').includes('application/json')) {
+                return response;
             }
-            return Promise.reject(new Error(res.msg || 'Error'));
-        } else {
-            return res.data;
+            return (response.data as Blob).text().then((text) => handleResult(JSON.parse(text)));
         }
+        return handleResult(response.data);
     },
-    (error: any) => {
+    async (error: any) => {
         console.log('err' + error);
-        // 处理 HTTP 401 错误
+        const config = error.config;
+        // 处理 HTTP 401 错误：先尝试用 Refresh Token 换取新 Token 并重试一次
         if (error.response?.status === 401) {
+            if (config && !config._retried && !config.url?.startsWith('/base/')) {
+                config._retried = true;
+                try {
+                    const token = await refreshAccessToken();
+                    config.headers = { ...config.headers, 'Authorization': `Bearer ${token}` };
+                    return service(config);
+                } catch (e) {
+                    // 刷新失败，继续走登录过期流程
+                }
+            }
             message.error('登录已过期，请重新登录');
-            localStorage.removeItem('token');
-            window.location.href = '/login';
+            redirectToLogin();
+        } else if (error.response?.status === 403) {
+            message.error(error.response.data?.msg || '权限不足');
         } else {
             message.error(error.message);
         }